@@ -4,6 +4,10 @@ const (
 	FlagGitConfig  = "git-config"
 	FlagOcmConfig  = "ocm-config"
 	FlagKubeConfig = "kubeconfig"
+	FlagOcmBackend = "ocm-backend"
+	FlagCacheDir   = "cache-dir"
+	FlagNoCache    = "no-cache"
+	FlagOutput     = "output"
 
 	ArgConfigFile = "configFile"
 )
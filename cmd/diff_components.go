@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openmcp-project/bootstrapper/internal/component"
+	cfg "github.com/openmcp-project/bootstrapper/internal/config"
+	logging "github.com/openmcp-project/bootstrapper/internal/log"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+const (
+	FlagTemplatesResourcePath = "templates-resource-path"
+)
+
+// diffComponentsCmd represents the "diff-components" command
+var diffComponentsCmd = &cobra.Command{
+	Use:   "diff-components fromComponentLocation toComponentLocation",
+	Short: "Compares two openMCP component versions",
+	Long: `Resolves two root component versions and all component versions they reference, and prints the differences:
+added, removed and changed component versions, image references and digests, CRD resources, and the contents of the gitops templates.
+Use it before running manage-deployment-repo to review an upgrade of a landscape. The JSON output can be attached to change requests.`,
+	Args: cobra.ExactArgs(2),
+	ArgAliases: []string{
+		"fromComponentLocation",
+		"toComponentLocation",
+	},
+	Example: `  openmcp-bootstrapper diff-components "ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.18" "ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.19"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString(FlagOutput)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(component.DiffOutputFormats, output) {
+			return fmt.Errorf("unsupported output format %q, must be one of %s", output, strings.Join(component.DiffOutputFormats, ", "))
+		}
+
+		log := logging.GetLogger()
+		log.Debugf("Comparing component versions %s and %s", args[0], args[1])
+
+		templatesResourcePath := cmd.Flag(FlagTemplatesResourcePath).Value.String()
+		ocmConfig := cmd.Flag(FlagOcmConfig).Value.String()
+
+		from := ocmcli.NewComponentGetter(args[0], templatesResourcePath, ocmConfig)
+		if err = from.InitializeComponents(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to initialize components of %s: %w", args[0], err)
+		}
+		to := ocmcli.NewComponentGetter(args[1], templatesResourcePath, ocmConfig)
+		if err = to.InitializeComponents(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to initialize components of %s: %w", args[1], err)
+		}
+
+		diff, err := component.DiffComponents(cmd.Context(), from, to)
+		if err != nil {
+			return fmt.Errorf("failed to compare component versions: %w", err)
+		}
+
+		return component.WriteComponentDiff(cmd.OutOrStdout(), diff, output)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(diffComponentsCmd)
+	diffComponentsCmd.Flags().SortFlags = false
+	diffComponentsCmd.Flags().String(FlagOcmConfig, "", "OCM configuration file")
+	diffComponentsCmd.Flags().String(FlagTemplatesResourcePath, cfg.DefaultFluxcdTemplateResourcePath, "Path to the gitops templates resource in the format <componentRef1>/.../<componentRefN>/<resourceName>")
+	diffComponentsCmd.Flags().StringP(FlagOutput, "o", component.OutputFormatText, "Output format ("+strings.Join(component.DiffOutputFormats, ", ")+")")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openmcp-project/bootstrapper/internal/component"
+	logging "github.com/openmcp-project/bootstrapper/internal/log"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// inspectComponentCmd represents the "inspect-component" command
+var inspectComponentCmd = &cobra.Command{
+	Use:   "inspect-component componentLocation",
+	Short: "Prints the resolved tree of an OCM component version",
+	Long: `Resolves the OCM component version and all component versions it references, directly or transitively, and prints the resolved tree.
+For each component version, its references and its resources with their types and access (image references, local references) are shown.
+The reference names are the names the bootstrapper looks up, e.g. "cluster-provider-<name>", and the resource names are the names of the images
+and templates it uses, e.g. "fluxcd-source-controller". The reference path of the table output can be used in component.fluxcdTemplateResourcePath.`,
+	Args: cobra.ExactArgs(1),
+	ArgAliases: []string{
+		"componentLocation",
+	},
+	Example: `  openmcp-bootstrapper inspect-component "ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.18"
+  openmcp-bootstrapper inspect-component "./ctf//github.com/openmcp-project/openmcp:v0.0.18" --output table`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		componentLocation := args[0]
+
+		output, err := cmd.Flags().GetString(FlagOutput)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(component.OutputFormats, output) {
+			return fmt.Errorf("unsupported output format %q, must be one of %s", output, strings.Join(component.OutputFormats, ", "))
+		}
+
+		log := logging.GetLogger()
+		log.Debugf("Inspecting component version %s", componentLocation)
+
+		compGetter := ocmcli.NewComponentGetter(componentLocation, "", cmd.Flag(FlagOcmConfig).Value.String())
+		if err = compGetter.InitializeComponents(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to initialize components: %w", err)
+		}
+
+		return component.WriteComponentTree(cmd.OutOrStdout(), component.BuildComponentTree(compGetter.Graph()), output)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(inspectComponentCmd)
+	inspectComponentCmd.Flags().SortFlags = false
+	inspectComponentCmd.Flags().String(FlagOcmConfig, "", "OCM configuration file")
+	inspectComponentCmd.Flags().StringP(FlagOutput, "o", component.OutputFormatTree, "Output format ("+strings.Join(component.OutputFormats, ", ")+")")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openmcp-project/bootstrapper/internal/component"
+	logging "github.com/openmcp-project/bootstrapper/internal/log"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+const (
+	FlagResolveDigests = "resolve-digests"
+)
+
+// listImagesCmd represents the "list-images" command
+var listImagesCmd = &cobra.Command{
+	Use:   "list-images componentLocation",
+	Short: "Lists the container images of an OCM component version",
+	Long: `Resolves the OCM component version and all component versions it references, directly or transitively, and lists the fully qualified
+references of all their ociImage resources, e.g. the images of the Flux controllers, the openmcp-operator, the providers and the External Secrets Operator.
+Digests are taken from the image reference or the component descriptor. With --resolve-digests, the digests of images only referenced by tag
+are resolved from their registry, using the credentials of the OCM configuration.
+The list can be printed as plain text with one reference per line, as JSON, or as a CycloneDX document.`,
+	Args: cobra.ExactArgs(1),
+	ArgAliases: []string{
+		"componentLocation",
+	},
+	Example: `  openmcp-bootstrapper list-images "ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.18"
+  openmcp-bootstrapper list-images "ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.18" --resolve-digests --output cyclonedx`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		componentLocation := args[0]
+
+		output, err := cmd.Flags().GetString(FlagOutput)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(component.ImageOutputFormats, output) {
+			return fmt.Errorf("unsupported output format %q, must be one of %s", output, strings.Join(component.ImageOutputFormats, ", "))
+		}
+		resolveDigests, err := cmd.Flags().GetBool(FlagResolveDigests)
+		if err != nil {
+			return err
+		}
+
+		log := logging.GetLogger()
+		log.Debugf("Listing images of component version %s", componentLocation)
+
+		ocmConfig := cmd.Flag(FlagOcmConfig).Value.String()
+		compGetter := ocmcli.NewComponentGetter(componentLocation, "", ocmConfig)
+		if err = compGetter.InitializeComponents(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to initialize components: %w", err)
+		}
+
+		lister, err := component.NewImageLister(ocmConfig, resolveDigests)
+		if err != nil {
+			return fmt.Errorf("failed to create image lister: %w", err)
+		}
+		images, err := lister.ListImages(cmd.Context(), compGetter.Graph())
+		if err != nil {
+			return fmt.Errorf("failed to list images: %w", err)
+		}
+
+		return component.WriteImageList(cmd.OutOrStdout(), images, compGetter.RootComponentVersion(), output)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(listImagesCmd)
+	listImagesCmd.Flags().SortFlags = false
+	listImagesCmd.Flags().String(FlagOcmConfig, "", "OCM configuration file")
+	listImagesCmd.Flags().Bool(FlagResolveDigests, false, "Resolve the digests of images that are only referenced by tag from their registry")
+	listImagesCmd.Flags().StringP(FlagOutput, "o", component.OutputFormatText, "Output format ("+strings.Join(component.ImageOutputFormats, ", ")+")")
+}
@@ -28,6 +28,10 @@ const (
 	FlagCommitMessage             = "commit-message"
 	FlagCommitAuthor              = "commit-author"
 	FlagCommitEmail               = "commit-email"
+	FlagCommitTrailer             = "commit-trailer"
+	FlagTag                       = "tag"
+	FlagPushRetries               = "push-retries"
+	FlagRepoDir                   = "repo-dir"
 )
 
 type LogWriter struct{}
@@ -77,6 +81,21 @@ openmcp-bootstrapper manage-deployment-repo <configFile>`,
 			return fmt.Errorf("failed to parse print-kustomized flag: %w", err)
 		}
 
+		pushRetries, err := cmd.Flags().GetInt(FlagPushRetries)
+		if err != nil {
+			return fmt.Errorf("failed to parse push-retries flag: %w", err)
+		}
+
+		commitTrailers, err := cmd.Flags().GetStringArray(FlagCommitTrailer)
+		if err != nil {
+			return fmt.Errorf("failed to parse commit-trailer flag: %w", err)
+		}
+
+		repoDir := cmd.Flag(FlagRepoDir).Value.String()
+		if repoDir == "" && cmd.Flag(FlagGitConfig).Value.String() == "" {
+			return fmt.Errorf("flag --%s is required unless an existing checkout is used with --%s", FlagGitConfig, FlagRepoDir)
+		}
+
 		if dryRun {
 			logger.Info("Running in dry-run mode: no changes will be applied to the git repository or the target cluster")
 			disableGitPush = true
@@ -109,7 +128,7 @@ openmcp-bootstrapper manage-deployment-repo <configFile>`,
 			cmd.Flag(FlagOcmConfig).Value.String(),
 			cmd.Flag(FlagExtraManifestDir).Value.String(),
 			cmd.Flag(FlagKustomizationPatches).Value.String(),
-		).Initialize(cmd.Context())
+		).WithPushRetries(pushRetries).WithRepoDir(repoDir).WithCommitTrailers(commitTrailers).WithTag(cmd.Flag(FlagTag).Value.String()).Initialize(cmd.Context())
 
 		defer func() {
 			deploymentRepoManager.Cleanup()
@@ -144,6 +163,11 @@ openmcp-bootstrapper manage-deployment-repo <configFile>`,
 			return fmt.Errorf("failed to update resources kustomization: %w", err)
 		}
 
+		err = deploymentRepoManager.PruneManagedFiles()
+		if err != nil {
+			return fmt.Errorf("failed to prune managed files: %w", err)
+		}
+
 		if !disableGitPush {
 			err = deploymentRepoManager.CommitAndPushChanges(
 				cmd.Context(),
@@ -151,6 +175,8 @@ openmcp-bootstrapper manage-deployment-repo <configFile>`,
 				cmd.Flag(FlagCommitAuthor).Value.String(),
 				cmd.Flag(FlagCommitEmail).Value.String())
 			if err != nil {
+				// the results of the mirrors pushed to before the failure and the skipped mirrors are part of the summary
+				logMirrorResults(deploymentRepoManager.MirrorResults())
 				return fmt.Errorf("failed to commit and push changes: %w", err)
 			}
 		} else {
@@ -179,15 +205,29 @@ openmcp-bootstrapper manage-deployment-repo <configFile>`,
 			}
 		}
 
+		logger.Infof("Managed deployment repository with component version %s", deploymentRepoManager.ComponentLocation())
+		logMirrorResults(deploymentRepoManager.MirrorResults())
 		return nil
 	},
 }
 
+// logMirrorResults logs the result of the push to each mirror of the deployment repository.
+func logMirrorResults(results []deploymentrepo.MirrorResult) {
+	logger := log.GetLogger()
+	for _, result := range results {
+		if result.Status == deploymentrepo.MirrorFailed {
+			logger.Errorf("Mirror %s", result)
+		} else {
+			logger.Infof("Mirror %s", result)
+		}
+	}
+}
+
 func init() {
 	RootCmd.AddCommand(manageDeploymentRepoCmd)
 	manageDeploymentRepoCmd.Flags().SortFlags = false
 	manageDeploymentRepoCmd.Flags().String(FlagOcmConfig, "", "ocm configuration file")
-	manageDeploymentRepoCmd.Flags().String(FlagGitConfig, "", "Git configuration file")
+	manageDeploymentRepoCmd.Flags().String(FlagGitConfig, "", "Git configuration file, required unless an existing checkout is used")
 	manageDeploymentRepoCmd.Flags().String(FlagKubeConfig, "", "Kubernetes configuration file")
 	manageDeploymentRepoCmd.Flags().String(FlagExtraManifestDir, "", "Directory containing extra manifests to apply")
 	manageDeploymentRepoCmd.Flags().String(FlagKustomizationPatches, "", "YAML file containing kustomization patches to apply")
@@ -195,11 +235,11 @@ func init() {
 	manageDeploymentRepoCmd.Flags().Bool(FlagDisableKustomizationApply, false, "If true, disables applying the kustomization to the target cluster")
 	manageDeploymentRepoCmd.Flags().Bool(FlagDryRun, false, "If true, performs a dry run without applying any changes to the git repo and the target cluster")
 	manageDeploymentRepoCmd.Flags().Bool(FlagPrintKustomized, false, "If true, prints the kustomized manifests to stdout")
-	manageDeploymentRepoCmd.Flags().String(FlagCommitMessage, "apply templates", "Commit message to use when pushing changes to the git repository")
-	manageDeploymentRepoCmd.Flags().String(FlagCommitAuthor, "openmcp", "Git author name to use when committing changes")
-	manageDeploymentRepoCmd.Flags().String(FlagCommitEmail, "noreply@openmcp.cloud", "Git user email to use when committing changes")
-
-	if err := manageDeploymentRepoCmd.MarkFlagRequired(FlagGitConfig); err != nil {
-		panic(err)
-	}
+	manageDeploymentRepoCmd.Flags().String(FlagCommitMessage, deploymentrepo.DefaultCommitMessage, "Commit message template to use when pushing changes to the git repository")
+	manageDeploymentRepoCmd.Flags().String(FlagCommitAuthor, deploymentrepo.DefaultCommitAuthor, "Git author name to use when committing changes")
+	manageDeploymentRepoCmd.Flags().String(FlagCommitEmail, deploymentrepo.DefaultCommitEmail, "Git user email to use when committing changes")
+	manageDeploymentRepoCmd.Flags().StringArray(FlagCommitTrailer, nil, "Git trailer \"Key: value\" appended to the commit message, the value is a template like the commit message (can be repeated)")
+	manageDeploymentRepoCmd.Flags().String(FlagTag, "", "Template of the name of an annotated tag created on the pushed commit, e.g. \"{{ .Values.environment }}/openmcp-{{ .Values.component.version }}\"")
+	manageDeploymentRepoCmd.Flags().String(FlagRepoDir, "", "Existing checkout of the deployment repository to use instead of cloning it, its working tree must be clean")
+	manageDeploymentRepoCmd.Flags().Int(FlagPushRetries, deploymentrepo.DefaultPushRetries, "Number of times a push rejected because the remote branch moved is retried on top of the new remote head")
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openmcp-project/bootstrapper/internal/component"
+	cfg "github.com/openmcp-project/bootstrapper/internal/config"
+	logging "github.com/openmcp-project/bootstrapper/internal/log"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
+)
+
+// mirrorImagesCmd represents the "mirror-images" command
+var mirrorImagesCmd = &cobra.Command{
+	Use:   "mirror-images",
+	Short: "Pushes all images of the openMCP component into the mirror registry for an air-gapped installation",
+	Long: `Pushes all images and helm charts of the openMCP component and its referenced components into the mirror registry
+configured in airGap.mirrorRegistry. The component is typically read from a local CTF archive, e.g. "./ctf//github.com/openmcp-project/openmcp:v0.1.0".
+Images stored as local blobs in the CTF are uploaded from the archive, all other images are copied from their original registry.
+With a signature verification in the config, the component is verified first and every image must have the digest covered by the signature.
+Afterwards, deploy-flux, deploy-eso and manage-deployment-repo render all image references pointing to the mirror registry.`,
+	Args: cobra.ExactArgs(1),
+	ArgAliases: []string{
+		ArgConfigFile,
+	},
+	Example: `  openmcp-bootstrapper mirror-images "./config.yaml"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFilePath := args[0]
+
+		log := logging.GetLogger()
+		log.Infof("Starting mirroring of images with config file: %s.", configFilePath)
+
+		config := &cfg.BootstrapperConfig{}
+		err := config.ReadFromFile(configFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		config.SetDefaults()
+		err = config.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid config file: %w", err)
+		}
+
+		relocator := relocation.NewRelocator(config)
+		if relocator.MirrorRegistry() == "" {
+			return fmt.Errorf("no mirror registry configured in airGap.mirrorRegistry")
+		}
+
+		compGetter := ocmcli.NewComponentGetter(config.Component.OpenMCPComponentLocation, config.Component.FluxcdTemplateResourcePath, cmd.Flag(FlagOcmConfig).Value.String())
+		component.ConfigureVerification(config.Verification, compGetter)
+		if err = compGetter.InitializeComponents(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to initialize components: %w", err)
+		}
+
+		mirror, err := relocation.NewMirror(compGetter, relocator, config.AirGap.Insecure)
+		if err != nil {
+			return fmt.Errorf("failed to create mirror: %w", err)
+		}
+
+		mirrored, err := mirror.MirrorImages(cmd.Context(), compGetter.Graph())
+		if err != nil {
+			log.Errorf("Mirroring of images failed: %v", err)
+			return err
+		}
+
+		log.Infof("Mirroring of %d images to %s completed", len(mirrored), relocator.MirrorRegistry())
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(mirrorImagesCmd)
+	mirrorImagesCmd.Flags().SortFlags = false
+	mirrorImagesCmd.Flags().String(FlagOcmConfig, "", "OCM configuration file")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openmcp-project/bootstrapper/internal/log"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -30,12 +31,51 @@ func Execute() {
 
 func init() {
 	RootCmd.PersistentFlags().StringP("verbosity", "v", "info", "Set the verbosity level (panic, fatal, error, warn, info, debug, trace)")
+	RootCmd.PersistentFlags().String(FlagOcmBackend, string(ocmcli.BackendNative), "Set the backend used to access OCM repositories (native, exec). The exec backend requires the ocm CLI in the PATH")
+	RootCmd.PersistentFlags().String(FlagCacheDir, "", "Set the directory of the persistent cache for component descriptors and resources (default is openmcp-bootstrapper in the user cache directory), not used with signature verification")
+	RootCmd.PersistentFlags().Bool(FlagNoCache, false, "Disable the persistent cache for component descriptors and resources")
 	cobra.OnInitialize(func() {
 		verbosity, err := RootCmd.PersistentFlags().GetString("verbosity")
 		if err != nil {
 			log.InitLogger("info")
+		} else {
+			log.InitLogger(verbosity)
+		}
+
+		backend, err := RootCmd.PersistentFlags().GetString(FlagOcmBackend)
+		if err != nil {
 			return
 		}
-		log.InitLogger(verbosity)
+		if err = ocmcli.SetDefaultBackend(backend); err != nil {
+			log.GetLogger().Error(err.Error())
+			os.Exit(1)
+		}
+
+		initCache()
 	})
 }
+
+// initCache configures the persistent cache for component descriptors and resources.
+func initCache() {
+	noCache, err := RootCmd.PersistentFlags().GetBool(FlagNoCache)
+	if err != nil || noCache {
+		ocmcli.SetCacheDir("")
+		return
+	}
+
+	cacheDir, err := RootCmd.PersistentFlags().GetString(FlagCacheDir)
+	if err != nil {
+		return
+	}
+	if cacheDir == "" {
+		cacheDir, err = ocmcli.DefaultCacheDir()
+		if err != nil {
+			log.GetLogger().Warnf("Persistent cache disabled: %v", err)
+			ocmcli.SetCacheDir("")
+			return
+		}
+	}
+
+	log.GetLogger().Debugf("Using cache directory %s", cacheDir)
+	ocmcli.SetCacheDir(cacheDir)
+}
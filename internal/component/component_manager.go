@@ -28,6 +28,7 @@ func NewComponentManager(ctx context.Context, config *cfg.BootstrapperConfig, oc
 		ComponentGetter: ocm_cli.NewComponentGetter(config.Component.OpenMCPComponentLocation, config.Component.FluxcdTemplateResourcePath, ocmConfigPath),
 	}
 
+	ConfigureVerification(config.Verification, m.ComponentGetter)
 	if err := m.ComponentGetter.InitializeComponents(ctx); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,491 @@
+package component
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+	ocm_cli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+const (
+	// OutputFormatText prints a human-readable report.
+	OutputFormatText = "text"
+
+	// crdResourceSuffix is the suffix of the CRD resource of a component, which is named after the last segment of the component name.
+	crdResourceSuffix = "-crds"
+)
+
+// DiffOutputFormats are the supported output formats of WriteComponentDiff.
+var DiffOutputFormats = []string{OutputFormatText, OutputFormatJSON}
+
+// ChangeType is the type of change of an entry between two component versions.
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// ComponentDiff contains the differences between two root component versions and all component versions they reference.
+type ComponentDiff struct {
+	// From is the location of the old root component version.
+	From string `json:"from"`
+	// To is the location of the new root component version.
+	To string `json:"to"`
+	// Components are the added, removed and changed component versions, identified by their component name.
+	Components []Change `json:"components"`
+	// Images are the added, removed and changed images, identified by <component name>/<resource name>.
+	Images []Change `json:"images"`
+	// CRDs are the added, removed and changed CRD resources, identified by <component name>/<resource name>.
+	CRDs []Change `json:"crds"`
+	// Templates are the added, removed and changed files of the gitops templates.
+	Templates []FileChange `json:"templates"`
+}
+
+// Change is an added, removed or changed entry.
+type Change struct {
+	Change ChangeType `json:"change"`
+	Name   string     `json:"name"`
+	// From is the old value, e.g. the version of a component or the reference of an image.
+	From string `json:"from,omitempty"`
+	// To is the new value.
+	To         string `json:"to,omitempty"`
+	FromDigest string `json:"fromDigest,omitempty"`
+	ToDigest   string `json:"toDigest,omitempty"`
+	// Files are the added, removed and changed files of a directory resource.
+	Files []FileChange `json:"files,omitempty"`
+}
+
+// FileChange is an added, removed or changed file of a directory resource.
+type FileChange struct {
+	Change       ChangeType `json:"change"`
+	Path         string     `json:"path"`
+	AddedLines   int        `json:"addedLines"`
+	RemovedLines int        `json:"removedLines"`
+	// Diff contains the added lines prefixed with "+" and the removed lines prefixed with "-".
+	Diff string `json:"diff,omitempty"`
+}
+
+// artifact is an image or CRD resource of a component version.
+type artifact struct {
+	cv       *ocm_cli.ComponentVersion
+	resource *ocm_cli.Resource
+	value    string
+	digest   string
+}
+
+// DiffComponents compares the component versions of the initialized component getters from and to.
+// Component versions are compared by name, so a changed version of a component is reported as change.
+// The contents of the CRD resources of changed component versions and of the gitops templates are downloaded and compared file by file.
+func DiffComponents(ctx context.Context, from, to *ocm_cli.ComponentGetter) (*ComponentDiff, error) {
+	logger := log.GetLogger()
+
+	result := &ComponentDiff{
+		From: from.RootComponentLocation(),
+		To:   to.RootComponentLocation(),
+	}
+
+	fromVersions := componentVersionsByName(from.Graph())
+	toVersions := componentVersionsByName(to.Graph())
+	result.Components = diffValues(versionStrings(fromVersions), versionStrings(toVersions))
+
+	multiVersion := multiVersionComponents(fromVersions, toVersions)
+	result.Images = diffArtifacts(collectArtifacts(fromVersions, multiVersion, isImageResource), collectArtifacts(toVersions, multiVersion, isImageResource))
+
+	fromCRDs := collectArtifacts(fromVersions, multiVersion, isCRDResource)
+	toCRDs := collectArtifacts(toVersions, multiVersion, isCRDResource)
+	result.CRDs = []Change{}
+	for _, change := range diffArtifacts(fromCRDs, toCRDs) {
+		logger.Debugf("Comparing contents of CRD resource %s", change.Name)
+		files, err := diffDirectoryResources(
+			func(dir string) error { return downloadArtifact(ctx, from, fromCRDs[change.Name], dir) },
+			func(dir string) error { return downloadArtifact(ctx, to, toCRDs[change.Name], dir) })
+		if err != nil {
+			return nil, fmt.Errorf("error comparing CRD resource %s: %w", change.Name, err)
+		}
+		change.Files = files
+
+		// the resource of a changed component version may have the same content
+		if change.Change == ChangeChanged && change.From == change.To && change.FromDigest == change.ToDigest && len(files) == 0 {
+			continue
+		}
+		result.CRDs = append(result.CRDs, change)
+	}
+
+	logger.Debugf("Comparing gitops templates")
+	templates, err := diffDirectoryResources(
+		func(dir string) error { return from.DownloadTemplatesResource(ctx, dir) },
+		func(dir string) error { return to.DownloadTemplatesResource(ctx, dir) })
+	if err != nil {
+		return nil, fmt.Errorf("error comparing gitops templates: %w", err)
+	}
+	result.Templates = templates
+
+	return result, nil
+}
+
+// IsEmpty returns true if the component versions do not differ.
+func (d *ComponentDiff) IsEmpty() bool {
+	return len(d.Components) == 0 && len(d.Images) == 0 && len(d.CRDs) == 0 && len(d.Templates) == 0
+}
+
+// WriteComponentDiff writes the component diff in the given output format, one of DiffOutputFormats.
+func WriteComponentDiff(w io.Writer, d *ComponentDiff, format string) error {
+	switch format {
+	case OutputFormatText:
+		return writeDiffReport(w, d)
+	case OutputFormatJSON:
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling component diff: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of %s", format, strings.Join(DiffOutputFormats, ", "))
+	}
+}
+
+func writeDiffReport(w io.Writer, d *ComponentDiff) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Comparing %s\n       to %s\n", d.From, d.To))
+	if d.IsEmpty() {
+		sb.WriteString("\nNo differences\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	writeChanges := func(title string, changes []Change) {
+		if len(changes) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n%s:\n", title))
+		for _, change := range changes {
+			sb.WriteString(fmt.Sprintf("  %s %s", changeSymbol(change.Change), change.Name))
+			switch change.Change {
+			case ChangeAdded:
+				sb.WriteString(": " + withDigest(change.To, change.ToDigest))
+			case ChangeRemoved:
+				sb.WriteString(": " + withDigest(change.From, change.FromDigest))
+			case ChangeChanged:
+				sb.WriteString(": " + withDigest(change.From, change.FromDigest) + " -> " + withDigest(change.To, change.ToDigest))
+			}
+			sb.WriteString("\n")
+			writeFileChanges(&sb, change.Files, "    ")
+		}
+	}
+
+	writeChanges("Components", d.Components)
+	writeChanges("Images", d.Images)
+	writeChanges("CRDs", d.CRDs)
+	if len(d.Templates) > 0 {
+		sb.WriteString("\nTemplates:\n")
+		writeFileChanges(&sb, d.Templates, "  ")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeFileChanges(sb *strings.Builder, files []FileChange, indent string) {
+	for _, file := range files {
+		sb.WriteString(fmt.Sprintf("%s%s %s (+%d -%d)\n", indent, changeSymbol(file.Change), file.Path, file.AddedLines, file.RemovedLines))
+		if file.Change != ChangeChanged {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(file.Diff, "\n"), "\n") {
+			sb.WriteString(indent + "    " + line + "\n")
+		}
+	}
+}
+
+func changeSymbol(change ChangeType) string {
+	switch change {
+	case ChangeAdded:
+		return "+"
+	case ChangeRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func withDigest(value, digest string) string {
+	if digest == "" || strings.Contains(value, digest) {
+		return value
+	}
+	return value + " (" + digest + ")"
+}
+
+// componentVersionsByName returns the resolved component versions of the graph by component name.
+// If a component is referenced in several versions, all of them are returned, sorted by version.
+func componentVersionsByName(graph *ocm_cli.ComponentGraph) map[string][]*ocm_cli.ComponentVersion {
+	result := map[string][]*ocm_cli.ComponentVersion{}
+	for _, cv := range graph.ComponentVersions() {
+		result[cv.Component.Name] = append(result[cv.Component.Name], cv)
+	}
+	return result
+}
+
+func versionStrings(cvs map[string][]*ocm_cli.ComponentVersion) map[string]string {
+	result := make(map[string]string, len(cvs))
+	for name, versions := range cvs {
+		values := make([]string, 0, len(versions))
+		for _, cv := range versions {
+			values = append(values, cv.Component.Version)
+		}
+		result[name] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+// diffValues returns the added, removed and changed entries of the maps, sorted by name.
+func diffValues(from, to map[string]string) []Change {
+	changes := []Change{}
+	for _, name := range sortedKeys(from, to) {
+		fromValue, inFrom := from[name]
+		toValue, inTo := to[name]
+		switch {
+		case !inFrom:
+			changes = append(changes, Change{Change: ChangeAdded, Name: name, To: toValue})
+		case !inTo:
+			changes = append(changes, Change{Change: ChangeRemoved, Name: name, From: fromValue})
+		case fromValue != toValue:
+			changes = append(changes, Change{Change: ChangeChanged, Name: name, From: fromValue, To: toValue})
+		}
+	}
+	return changes
+}
+
+func isImageResource(_ *ocm_cli.ComponentVersion, resource *ocm_cli.Resource) bool {
+	return resource.Type == ocm_cli.OCIImageResourceType
+}
+
+// isCRDResource returns true for the CRD resource of a component, see DeploymentRepoManager.ApplyCustomResourceDefinitions.
+func isCRDResource(cv *ocm_cli.ComponentVersion, resource *ocm_cli.Resource) bool {
+	return resource.Name == cv.Component.Name[strings.LastIndex(cv.Component.Name, "/")+1:]+crdResourceSuffix
+}
+
+// multiVersionComponents returns the names of the components that are referenced in several versions by one of the graphs.
+func multiVersionComponents(from, to map[string][]*ocm_cli.ComponentVersion) map[string]bool {
+	result := map[string]bool{}
+	for _, cvs := range []map[string][]*ocm_cli.ComponentVersion{from, to} {
+		for name, versions := range cvs {
+			if len(versions) > 1 {
+				result[name] = true
+			}
+		}
+	}
+	return result
+}
+
+// collectArtifacts returns the resources matching the filter by <component name>/<resource name>.
+// The resources of the components in multiVersion are keyed by <component name>:<version>/<resource name>,
+// so that the resources of the different versions of a component are all compared.
+func collectArtifacts(cvs map[string][]*ocm_cli.ComponentVersion, multiVersion map[string]bool, filter func(*ocm_cli.ComponentVersion, *ocm_cli.Resource) bool) map[string]*artifact {
+	result := map[string]*artifact{}
+	for name, versions := range cvs {
+		for _, cv := range versions {
+			key := name
+			if multiVersion[name] {
+				key = name + ":" + cv.Component.Version
+			}
+			for i := range cv.Component.Resources {
+				resource := &cv.Component.Resources[i]
+				if !filter(cv, resource) {
+					continue
+				}
+
+				a := &artifact{cv: cv, resource: resource, value: resource.Version}
+				if resource.Type == ocm_cli.OCIImageResourceType {
+					if image, err := relocation.OriginalImageReference(resource); err == nil {
+						a.value = image
+					}
+				}
+				if resource.Digest != nil {
+					a.digest = resource.Digest.Value
+					if !strings.Contains(a.digest, ":") {
+						a.digest = "sha256:" + a.digest
+					}
+				} else if resource.Access.ImageReference != nil {
+					if _, _, digest, err := util.ParseImageVersionAndTag(*resource.Access.ImageReference); err == nil {
+						a.digest = digest
+					}
+				}
+				result[key+"/"+resource.Name] = a
+			}
+		}
+	}
+	return result
+}
+
+// diffArtifacts returns the added, removed and changed artifacts, sorted by name.
+// Artifacts are changed if their value, e.g. the image reference or version, or their digest differ.
+func diffArtifacts(from, to map[string]*artifact) []Change {
+	changes := []Change{}
+	for _, name := range sortedKeys(from, to) {
+		fromArtifact, inFrom := from[name]
+		toArtifact, inTo := to[name]
+		switch {
+		case !inFrom:
+			changes = append(changes, Change{Change: ChangeAdded, Name: name, To: toArtifact.value, ToDigest: toArtifact.digest})
+		case !inTo:
+			changes = append(changes, Change{Change: ChangeRemoved, Name: name, From: fromArtifact.value, FromDigest: fromArtifact.digest})
+		case fromArtifact.value != toArtifact.value || fromArtifact.digest != toArtifact.digest ||
+			fromArtifact.cv.Component.Version != toArtifact.cv.Component.Version && fromArtifact.digest == "":
+			changes = append(changes, Change{
+				Change:     ChangeChanged,
+				Name:       name,
+				From:       fromArtifact.value,
+				To:         toArtifact.value,
+				FromDigest: fromArtifact.digest,
+				ToDigest:   toArtifact.digest,
+			})
+		}
+	}
+	return changes
+}
+
+func downloadArtifact(ctx context.Context, getter *ocm_cli.ComponentGetter, a *artifact, dir string) error {
+	if a == nil {
+		return nil
+	}
+	return getter.DownloadDirectoryResource(ctx, a.cv, a.resource.Name, dir)
+}
+
+// diffDirectoryResources downloads two directory resources into temporary directories and compares their files.
+func diffDirectoryResources(downloadFrom, downloadTo func(dir string) error) ([]FileChange, error) {
+	fromDir, err := util.CreateTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = util.DeleteTempDir(fromDir)
+	}()
+	toDir, err := util.CreateTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = util.DeleteTempDir(toDir)
+	}()
+
+	if err = downloadFrom(fromDir); err != nil {
+		return nil, err
+	}
+	if err = downloadTo(toDir); err != nil {
+		return nil, err
+	}
+
+	return DiffDirectories(fromDir, toDir)
+}
+
+// DiffDirectories returns the added, removed and changed files of the directory toDir compared to fromDir, sorted by path.
+func DiffDirectories(fromDir, toDir string) ([]FileChange, error) {
+	fromFiles, err := readFiles(fromDir)
+	if err != nil {
+		return nil, err
+	}
+	toFiles, err := readFiles(toDir)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FileChange{}
+	for _, path := range sortedKeys(fromFiles, toFiles) {
+		fromContent, inFrom := fromFiles[path]
+		toContent, inTo := toFiles[path]
+		if inFrom && inTo && fromContent == toContent {
+			continue
+		}
+
+		change := FileChange{Change: ChangeChanged, Path: path}
+		switch {
+		case !inFrom:
+			change.Change = ChangeAdded
+		case !inTo:
+			change.Change = ChangeRemoved
+		}
+
+		var sb strings.Builder
+		for _, d := range diff.Do(fromContent, toContent) {
+			prefix := ""
+			switch d.Type {
+			case diffmatchpatch.DiffInsert:
+				prefix = "+"
+			case diffmatchpatch.DiffDelete:
+				prefix = "-"
+			default:
+				continue
+			}
+			for _, line := range strings.SplitAfter(d.Text, "\n") {
+				if line == "" {
+					continue
+				}
+				if prefix == "+" {
+					change.AddedLines++
+				} else {
+					change.RemovedLines++
+				}
+				sb.WriteString(prefix + strings.TrimSuffix(line, "\n") + "\n")
+			}
+		}
+		if change.Change == ChangeChanged {
+			change.Diff = sb.String()
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// readFiles returns the contents of all files in the directory by their slash-separated relative path.
+func readFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+func sortedKeys[V any](from, to map[string]V) []string {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
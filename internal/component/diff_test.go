@@ -0,0 +1,132 @@
+package component_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openmcp-project/bootstrapper/internal/component"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+const (
+	diffRootComponent      = "github.com/openmcp-project/bootstrapper/test-diff-root"
+	diffTemplatesComponent = "github.com/openmcp-project/bootstrapper/test-diff-templates"
+	diffOperatorComponent  = "github.com/openmcp-project/bootstrapper/openmcp-operator"
+	diffLegacyComponent    = "github.com/openmcp-project/bootstrapper/test-diff-legacy"
+	diffProviderComponent  = "github.com/openmcp-project/bootstrapper/cluster-provider-test"
+)
+
+func TestDiffComponents(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/02/component-constructor.yaml", t)
+
+	from := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.1", ctf, diffRootComponent), "gitops-templates/fluxcd", ocmcli.NoOcmConfig)
+	to := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.2", ctf, diffRootComponent), "gitops-templates/fluxcd", ocmcli.NoOcmConfig)
+	if !assert.NoError(t, from.InitializeComponents(t.Context())) || !assert.NoError(t, to.InitializeComponents(t.Context())) {
+		return
+	}
+
+	diff, err := component.DiffComponents(t.Context(), from, to)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []component.Change{
+		{Change: component.ChangeAdded, Name: diffProviderComponent, To: "v0.1.0"},
+		{Change: component.ChangeChanged, Name: diffOperatorComponent, From: "v0.1.0", To: "v0.2.0"},
+		{Change: component.ChangeRemoved, Name: diffLegacyComponent, From: "v0.1.0"},
+		{Change: component.ChangeChanged, Name: diffRootComponent, From: "v0.0.1", To: "v0.0.2"},
+		{Change: component.ChangeChanged, Name: diffTemplatesComponent, From: "v0.1.0", To: "v0.2.0"},
+	}, diff.Components)
+
+	assert.Equal(t, []component.Change{
+		{Change: component.ChangeAdded, Name: diffProviderComponent + "/cluster-provider-test", To: "ghcr.io/openmcp-project/images/cluster-provider-test:v0.1.0"},
+		{
+			Change:   component.ChangeChanged,
+			Name:     diffOperatorComponent + "/openmcp-operator",
+			From:     "ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0",
+			To:       "ghcr.io/openmcp-project/images/openmcp-operator:v0.2.0@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			ToDigest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+		{Change: component.ChangeRemoved, Name: diffLegacyComponent + "/legacy", From: "ghcr.io/openmcp-project/images/legacy:v0.1.0"},
+	}, diff.Images)
+
+	if assert.Len(t, diff.CRDs, 1) {
+		crds := diff.CRDs[0]
+		assert.Equal(t, component.ChangeChanged, crds.Change)
+		assert.Equal(t, diffOperatorComponent+"/openmcp-operator-crds", crds.Name)
+		assert.Equal(t, []component.FileChange{
+			{Change: component.ChangeChanged, Path: "crd.yaml", AddedLines: 1, Diff: "+    - name: v1beta1\n"},
+		}, crds.Files)
+	}
+
+	assert.Equal(t, []component.FileChange{
+		{Change: component.ChangeRemoved, Path: "flux.yaml", RemovedLines: 4},
+		{Change: component.ChangeChanged, Path: "kustomization.yaml", AddedLines: 1, RemovedLines: 1, Diff: "-  - flux.yaml\n+  - namespace.yaml\n"},
+		{Change: component.ChangeAdded, Path: "namespace.yaml", AddedLines: 4},
+	}, diff.Templates)
+
+	var buf bytes.Buffer
+	assert.NoError(t, component.WriteComponentDiff(&buf, diff, component.OutputFormatText))
+	assert.Contains(t, buf.String(), "  ~ "+diffOperatorComponent+": v0.1.0 -> v0.2.0\n")
+	assert.Contains(t, buf.String(), "  - "+diffLegacyComponent+"/legacy: ghcr.io/openmcp-project/images/legacy:v0.1.0\n")
+	assert.Contains(t, buf.String(), "  ~ kustomization.yaml (+1 -1)\n      -  - flux.yaml\n      +  - namespace.yaml\n")
+
+	buf.Reset()
+	assert.NoError(t, component.WriteComponentDiff(&buf, diff, component.OutputFormatJSON))
+	var decoded component.ComponentDiff
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *diff, decoded)
+
+	assert.Error(t, component.WriteComponentDiff(&buf, diff, "xml"))
+}
+
+func TestDiffComponentsWithMultipleVersions(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/02/component-constructor.yaml", t)
+
+	from := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.2", ctf, diffRootComponent), "gitops-templates/fluxcd", ocmcli.NoOcmConfig)
+	to := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.3", ctf, diffRootComponent), "gitops-templates/fluxcd", ocmcli.NoOcmConfig)
+	if !assert.NoError(t, from.InitializeComponents(t.Context())) || !assert.NoError(t, to.InitializeComponents(t.Context())) {
+		return
+	}
+
+	diff, err := component.DiffComponents(t.Context(), from, to)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []component.Change{
+		{Change: component.ChangeChanged, Name: diffProviderComponent, From: "v0.1.0", To: "v0.1.0, v0.2.0"},
+		{Change: component.ChangeChanged, Name: diffRootComponent, From: "v0.0.2", To: "v0.0.3"},
+	}, diff.Components)
+
+	// the resources of all versions of a component are compared
+	assert.Equal(t, []component.Change{
+		{Change: component.ChangeAdded, Name: diffProviderComponent + ":v0.2.0/cluster-provider-test", To: "ghcr.io/openmcp-project/images/cluster-provider-test:v0.2.0"},
+	}, diff.Images)
+}
+
+func TestDiffComponentsWithoutDifferences(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/02/component-constructor.yaml", t)
+
+	location := fmt.Sprintf("%s//%s:v0.0.2", ctf, diffRootComponent)
+	from := ocmcli.NewComponentGetter(location, "gitops-templates/fluxcd", ocmcli.NoOcmConfig)
+	to := ocmcli.NewComponentGetter(location, "gitops-templates/fluxcd", ocmcli.NoOcmConfig)
+	if !assert.NoError(t, from.InitializeComponents(t.Context())) || !assert.NoError(t, to.InitializeComponents(t.Context())) {
+		return
+	}
+
+	diff, err := component.DiffComponents(t.Context(), from, to)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, diff.IsEmpty())
+
+	var buf bytes.Buffer
+	assert.NoError(t, component.WriteComponentDiff(&buf, diff, component.OutputFormatText))
+	assert.Contains(t, buf.String(), "No differences")
+}
@@ -0,0 +1,273 @@
+package component
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+	ocm_cli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+const (
+	// OutputFormatCycloneDX prints a CycloneDX 1.5 document with one container component per image.
+	OutputFormatCycloneDX = "cyclonedx"
+
+	cycloneDXSpecVersion = "1.5"
+)
+
+// ImageOutputFormats are the supported output formats of WriteImageList.
+var ImageOutputFormats = []string{OutputFormatText, OutputFormatJSON, OutputFormatCycloneDX}
+
+// Image is an ociImage resource of a component version.
+type Image struct {
+	// Component is the name of the component containing the resource.
+	Component string `json:"component"`
+	// ComponentVersion is the version of the component containing the resource.
+	ComponentVersion string `json:"componentVersion"`
+	// Resource is the name of the resource.
+	Resource string `json:"resource"`
+	// Version is the version of the resource.
+	Version string `json:"version"`
+	// Repository is the fully qualified repository of the image, e.g. "ghcr.io/fluxcd/source-controller".
+	Repository string `json:"repository"`
+	// Tag is the tag of the image, if any.
+	Tag string `json:"tag,omitempty"`
+	// Digest is the digest of the image, if it is pinned, signed or has been resolved.
+	Digest string `json:"digest,omitempty"`
+	// Reference is the fully qualified image reference including the digest, if known.
+	Reference string `json:"reference"`
+}
+
+// ImageLister collects the ociImage resources of component versions.
+type ImageLister struct {
+	keychain       authn.Keychain
+	resolveDigests bool
+}
+
+// NewImageLister creates an ImageLister. If resolveDigests is true, the digests of images that are neither pinned by digest
+// nor have a digest in the component descriptor are resolved from their registry.
+// Registry credentials are taken from the OCM configuration, falling back to the Docker configuration.
+func NewImageLister(ocmConfig string, resolveDigests bool) (*ImageLister, error) {
+	ocmKeychain, err := ocm_cli.NewKeychain(ocmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageLister{
+		keychain:       authn.NewMultiKeychain(ocmKeychain, authn.DefaultKeychain),
+		resolveDigests: resolveDigests,
+	}, nil
+}
+
+// ListImages returns the ociImage resources of all component versions in the graph, sorted by reference.
+func (l *ImageLister) ListImages(ctx context.Context, graph *ocm_cli.ComponentGraph) ([]Image, error) {
+	logger := log.GetLogger()
+
+	images := make([]Image, 0)
+	for _, cv := range graph.ComponentVersions() {
+		for i := range cv.Component.Resources {
+			resource := &cv.Component.Resources[i]
+			if resource.Type != ocm_cli.OCIImageResourceType {
+				continue
+			}
+
+			image, err := l.image(ctx, cv, resource)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list image of resource %s of component version %s:%s: %w", resource.Name, cv.Component.Name, cv.Component.Version, err)
+			}
+			logger.Debugf("Found image %s in resource %s of component version %s:%s", image.Reference, resource.Name, cv.Component.Name, cv.Component.Version)
+			images = append(images, *image)
+		}
+	}
+
+	slices.SortFunc(images, func(a, b Image) int {
+		if c := strings.Compare(a.Reference, b.Reference); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Component+"/"+a.Resource, b.Component+"/"+b.Resource)
+	})
+	return images, nil
+}
+
+func (l *ImageLister) image(ctx context.Context, cv *ocm_cli.ComponentVersion, resource *ocm_cli.Resource) (*Image, error) {
+	source, err := relocation.OriginalImageReference(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	imageName, tag, digest, err := util.ParseImageVersionAndTag(source)
+	if err != nil {
+		return nil, err
+	}
+	repository, err := name.NewRepository(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %s: %w", source, err)
+	}
+
+	if digest == "" && resource.Digest != nil && resource.Digest.NormalisationAlgorithm == ocm_cli.OCIArtifactDigestV1 {
+		digest = "sha256:" + resource.Digest.Value
+	}
+	if digest == "" && l.resolveDigests && tag != "" {
+		desc, err := remote.Head(repository.Tag(tag), remote.WithContext(ctx), remote.WithAuthFromKeychain(l.keychain))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve digest of image %s: %w", source, err)
+		}
+		digest = desc.Digest.String()
+	}
+
+	reference := repository.Name()
+	if tag != "" {
+		reference += ":" + tag
+	}
+	if digest != "" {
+		reference += "@" + digest
+	}
+
+	return &Image{
+		Component:        cv.Component.Name,
+		ComponentVersion: cv.Component.Version,
+		Resource:         resource.Name,
+		Version:          resource.Version,
+		Repository:       repository.Name(),
+		Tag:              tag,
+		Digest:           digest,
+		Reference:        reference,
+	}, nil
+}
+
+// WriteImageList writes the images in the given output format, one of ImageOutputFormats.
+// The text format contains one image reference per line, images used by several resources are listed once.
+// The CycloneDX document describes the root component version rootCV with the images as its components.
+func WriteImageList(w io.Writer, images []Image, rootCV *ocm_cli.ComponentVersion, format string) error {
+	switch format {
+	case OutputFormatText:
+		var sb strings.Builder
+		var last string
+		for _, image := range images {
+			if image.Reference != last {
+				sb.WriteString(image.Reference + "\n")
+				last = image.Reference
+			}
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	case OutputFormatJSON:
+		return writeJSON(w, images)
+	case OutputFormatCycloneDX:
+		return writeJSON(w, newCycloneDXDocument(images, rootCV, time.Now()))
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of %s", format, strings.Join(ImageOutputFormats, ", "))
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling output: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// CycloneDXDocument is the subset of a CycloneDX bill of materials written by WriteImageList.
+type CycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    CycloneDXMetadata    `json:"metadata"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXMetadata describes the component version the bill of materials has been created for.
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+// CycloneDXComponent is a component of a CycloneDX bill of materials.
+type CycloneDXComponent struct {
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Type       string              `json:"type"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Hashes     []CycloneDXHash     `json:"hashes,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+// CycloneDXHash is the hash of a component.
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// CycloneDXProperty is a name-value pair of a component.
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func newCycloneDXDocument(images []Image, rootCV *ocm_cli.ComponentVersion, timestamp time.Time) *CycloneDXDocument {
+	doc := &CycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: cycloneDXSpecVersion,
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: timestamp.UTC().Format(time.RFC3339),
+			Component: CycloneDXComponent{
+				Type:    "application",
+				Name:    rootCV.Component.Name,
+				Version: rootCV.Component.Version,
+			},
+		},
+		Components: make([]CycloneDXComponent, 0, len(images)),
+	}
+
+	for _, image := range images {
+		component := CycloneDXComponent{
+			BOMRef:  image.Component + ":" + image.ComponentVersion + "/" + image.Resource,
+			Type:    "container",
+			Name:    image.Repository,
+			Version: image.Tag,
+			PURL:    ociPackageURL(image),
+			Properties: []CycloneDXProperty{
+				{Name: "openmcp:component", Value: image.Component + ":" + image.ComponentVersion},
+				{Name: "openmcp:resource", Value: image.Resource},
+				{Name: "openmcp:reference", Value: image.Reference},
+			},
+		}
+		if algorithm, hex, ok := strings.Cut(image.Digest, ":"); ok && algorithm == "sha256" {
+			component.Hashes = []CycloneDXHash{{Algorithm: "SHA-256", Content: hex}}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+	return doc
+}
+
+// ociPackageURL returns the package URL of an image, e.g.
+// "pkg:oci/source-controller@sha256%3A...?repository_url=ghcr.io/fluxcd/source-controller&tag=v1.6.0".
+func ociPackageURL(image Image) string {
+	purl := "pkg:oci/" + strings.ToLower(image.Repository[strings.LastIndex(image.Repository, "/")+1:])
+	if image.Digest != "" {
+		purl += "@" + strings.ReplaceAll(image.Digest, ":", "%3A")
+	}
+
+	query := url.Values{}
+	query.Set("repository_url", image.Repository)
+	if image.Tag != "" {
+		query.Set("tag", image.Tag)
+	}
+	return purl + "?" + strings.ReplaceAll(query.Encode(), "%2F", "/")
+}
@@ -0,0 +1,216 @@
+package component_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openmcp-project/bootstrapper/internal/component"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+const (
+	imagesRootComponent     = "github.com/openmcp-project/bootstrapper/test-images-root"
+	imagesFluxcdComponent   = "github.com/openmcp-project/bootstrapper/fluxcd"
+	imagesOperatorComponent = "github.com/openmcp-project/bootstrapper/openmcp-operator"
+	imagesProviderComponent = "github.com/openmcp-project/bootstrapper/cluster-provider-test"
+)
+
+func TestListImages(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/03/component-constructor.yaml", t)
+
+	compGetter := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.1", ctf, imagesRootComponent), "", ocmcli.NoOcmConfig)
+	if !assert.NoError(t, compGetter.InitializeComponents(t.Context())) {
+		return
+	}
+
+	lister, err := component.NewImageLister(ocmcli.NoOcmConfig, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	images, err := lister.ListImages(t.Context(), compGetter.Graph())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []component.Image{
+		{
+			Component:        imagesFluxcdComponent,
+			ComponentVersion: "v2.6.0",
+			Resource:         "fluxcd-kustomize-controller",
+			Version:          "v1.6.0",
+			Repository:       "ghcr.io/fluxcd/kustomize-controller",
+			Tag:              "v1.6.0",
+			Digest:           "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			Reference:        "ghcr.io/fluxcd/kustomize-controller:v1.6.0@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		},
+		{
+			Component:        imagesProviderComponent,
+			ComponentVersion: "v0.1.0",
+			Resource:         "cluster-provider-test",
+			Version:          "v0.1.0",
+			Repository:       "ghcr.io/openmcp-project/images/cluster-provider-test",
+			Tag:              "v0.1.0",
+			Reference:        "ghcr.io/openmcp-project/images/cluster-provider-test:v0.1.0",
+		},
+		{
+			Component:        imagesOperatorComponent,
+			ComponentVersion: "v0.1.0",
+			Resource:         "openmcp-operator",
+			Version:          "v0.1.0",
+			Repository:       "ghcr.io/openmcp-project/images/openmcp-operator",
+			Tag:              "v0.1.0",
+			Digest:           "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+			Reference:        "ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0@sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		},
+		{
+			Component:        imagesProviderComponent,
+			ComponentVersion: "v0.1.0",
+			Resource:         "fluxcd-source-controller",
+			Version:          "v1.6.0",
+			Repository:       "index.docker.io/fluxcd/source-controller",
+			Tag:              "v1.6.0",
+			Reference:        "index.docker.io/fluxcd/source-controller:v1.6.0",
+		},
+		{
+			Component:        imagesFluxcdComponent,
+			ComponentVersion: "v2.6.0",
+			Resource:         "fluxcd-source-controller",
+			Version:          "v1.6.0",
+			Repository:       "index.docker.io/fluxcd/source-controller",
+			Tag:              "v1.6.0",
+			Reference:        "index.docker.io/fluxcd/source-controller:v1.6.0",
+		},
+	}, images)
+
+	var buf bytes.Buffer
+	assert.NoError(t, component.WriteImageList(&buf, images, compGetter.RootComponentVersion(), component.OutputFormatText))
+	assert.Equal(t, `ghcr.io/fluxcd/kustomize-controller:v1.6.0@sha256:1111111111111111111111111111111111111111111111111111111111111111
+ghcr.io/openmcp-project/images/cluster-provider-test:v0.1.0
+ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0@sha256:2222222222222222222222222222222222222222222222222222222222222222
+index.docker.io/fluxcd/source-controller:v1.6.0
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, component.WriteImageList(&buf, images, compGetter.RootComponentVersion(), component.OutputFormatJSON))
+	var decoded []component.Image
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, images, decoded)
+
+	buf.Reset()
+	assert.NoError(t, component.WriteImageList(&buf, images, compGetter.RootComponentVersion(), component.OutputFormatCycloneDX))
+	var bom component.CycloneDXDocument
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &bom)) {
+		assert.Equal(t, "CycloneDX", bom.BOMFormat)
+		assert.Equal(t, "1.5", bom.SpecVersion)
+		assert.Equal(t, imagesRootComponent, bom.Metadata.Component.Name)
+		assert.Equal(t, "v0.0.1", bom.Metadata.Component.Version)
+		assert.NotEmpty(t, bom.Metadata.Timestamp)
+		if assert.Len(t, bom.Components, 5) {
+			operator := bom.Components[2]
+			assert.Equal(t, "container", operator.Type)
+			assert.Equal(t, "ghcr.io/openmcp-project/images/openmcp-operator", operator.Name)
+			assert.Equal(t, "v0.1.0", operator.Version)
+			assert.Equal(t, "pkg:oci/openmcp-operator@sha256%3A2222222222222222222222222222222222222222222222222222222222222222?repository_url=ghcr.io/openmcp-project/images/openmcp-operator&tag=v0.1.0", operator.PURL)
+			assert.Equal(t, []component.CycloneDXHash{{Algorithm: "SHA-256", Content: "2222222222222222222222222222222222222222222222222222222222222222"}}, operator.Hashes)
+
+			provider := bom.Components[1]
+			assert.Equal(t, "pkg:oci/cluster-provider-test?repository_url=ghcr.io/openmcp-project/images/cluster-provider-test&tag=v0.1.0", provider.PURL)
+			assert.Empty(t, provider.Hashes)
+		}
+	}
+
+	assert.Error(t, component.WriteImageList(&buf, images, compGetter.RootComponentVersion(), "xml"))
+}
+
+func TestListImagesResolveDigests(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	image, err := random.Image(256, 1)
+	assert.NoError(t, err)
+	digest, err := image.Digest()
+	assert.NoError(t, err)
+	ref, err := name.ParseReference(host + "/images/app:v1.0.0")
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(ref, image))
+
+	testCases := []struct {
+		desc              string
+		imageReference    string
+		resolveDigests    bool
+		expectedReference string
+		expectError       bool
+	}{
+		{
+			desc:              "should resolve the digest of a tagged image",
+			imageReference:    host + "/images/app:v1.0.0",
+			resolveDigests:    true,
+			expectedReference: host + "/images/app:v1.0.0@" + digest.String(),
+		},
+		{
+			desc:              "should not resolve digests if disabled",
+			imageReference:    host + "/images/app:v1.0.0",
+			expectedReference: host + "/images/app:v1.0.0",
+		},
+		{
+			desc:           "should fail if the image does not exist",
+			imageReference: host + "/images/app:v2.0.0",
+			resolveDigests: true,
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			workDir := t.TempDir()
+			constructorPath := filepath.Join(workDir, "component-constructor.yaml")
+			constructor := fmt.Sprintf(`components:
+  - name: %s
+    version: v0.0.1
+    provider:
+      name: openmcp-project
+    resources:
+      - name: app
+        type: ociImage
+        version: v1.0.0
+        access:
+          type: ociArtifact
+          imageReference: %s
+`, imagesRootComponent, tc.imageReference)
+			assert.NoError(t, os.WriteFile(constructorPath, []byte(constructor), 0o600))
+			ctf := testutil.BuildComponentCTF(constructorPath, t)
+
+			compGetter := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.1", ctf, imagesRootComponent), "", ocmcli.NoOcmConfig)
+			if !assert.NoError(t, compGetter.InitializeComponents(t.Context())) {
+				return
+			}
+
+			lister, err := component.NewImageLister(ocmcli.NoOcmConfig, tc.resolveDigests)
+			if !assert.NoError(t, err) {
+				return
+			}
+			images, err := lister.ListImages(t.Context(), compGetter.Graph())
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) && assert.Len(t, images, 1) {
+				assert.Equal(t, tc.expectedReference, images[0].Reference)
+			}
+		})
+	}
+}
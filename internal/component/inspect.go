@@ -0,0 +1,243 @@
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+
+	ocm_cli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+const (
+	// OutputFormatTree prints the component tree with one line per resource and reference.
+	OutputFormatTree = "tree"
+	// OutputFormatTable prints one row per resource with the reference path of its component.
+	OutputFormatTable = "table"
+	// OutputFormatJSON prints the component tree as JSON.
+	OutputFormatJSON = "json"
+	// OutputFormatYAML prints the component tree as YAML.
+	OutputFormatYAML = "yaml"
+)
+
+// OutputFormats are the supported output formats of WriteComponentTree.
+var OutputFormats = []string{OutputFormatTree, OutputFormatTable, OutputFormatJSON, OutputFormatYAML}
+
+// ComponentNode is a component version in the resolved component tree.
+type ComponentNode struct {
+	// ReferenceName is the name of the reference from the parent component version, empty for the root component version.
+	ReferenceName string `json:"referenceName,omitempty"`
+	// Name is the name of the component.
+	Name string `json:"name"`
+	// Version is the version of the component.
+	Version string `json:"version"`
+	// Resources are the resources of the component version.
+	Resources []ResourceNode `json:"resources,omitempty"`
+	// References are the referenced component versions.
+	References []*ComponentNode `json:"references,omitempty"`
+	// Error is set if the referenced component version could not be resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// ResourceNode is a resource of a component version in the resolved component tree.
+type ResourceNode struct {
+	Name    string     `json:"name"`
+	Version string     `json:"version"`
+	Type    string     `json:"type"`
+	Access  AccessNode `json:"access"`
+}
+
+// AccessNode is the access of a resource in the resolved component tree.
+type AccessNode struct {
+	Type           string `json:"type"`
+	ImageReference string `json:"imageReference,omitempty"`
+	LocalReference string `json:"localReference,omitempty"`
+	MediaType      string `json:"mediaType,omitempty"`
+	ReferenceName  string `json:"referenceName,omitempty"`
+}
+
+// BuildComponentTree builds the tree of the root component version of the graph and all component versions it references.
+// A component version referenced several times appears once per reference.
+func BuildComponentTree(graph *ocm_cli.ComponentGraph) *ComponentNode {
+	return buildComponentNode(graph, graph.Root(), "")
+}
+
+func buildComponentNode(graph *ocm_cli.ComponentGraph, cv *ocm_cli.ComponentVersion, referenceName string) *ComponentNode {
+	node := &ComponentNode{
+		ReferenceName: referenceName,
+		Name:          cv.Component.Name,
+		Version:       cv.Component.Version,
+	}
+
+	for _, resource := range cv.Component.Resources {
+		node.Resources = append(node.Resources, ResourceNode{
+			Name:    resource.Name,
+			Version: resource.Version,
+			Type:    resource.Type,
+			Access: AccessNode{
+				Type:           resource.Access.Type,
+				ImageReference: deref(resource.Access.ImageReference),
+				LocalReference: deref(resource.Access.LocalReference),
+				MediaType:      deref(resource.Access.MediaType),
+				ReferenceName:  deref(resource.Access.ReferenceName),
+			},
+		})
+	}
+
+	for _, ref := range cv.Component.ComponentReferences {
+		refCV, _, err := graph.Lookup(ref.ComponentName, ref.Version)
+		if err != nil || refCV == nil {
+			refNode := &ComponentNode{
+				ReferenceName: ref.Name,
+				Name:          ref.ComponentName,
+				Version:       ref.Version,
+				Error:         "component version not resolved",
+			}
+			if err != nil {
+				refNode.Error = err.Error()
+			}
+			node.References = append(node.References, refNode)
+			continue
+		}
+		node.References = append(node.References, buildComponentNode(graph, refCV, ref.Name))
+	}
+
+	return node
+}
+
+// WriteComponentTree writes the component tree in the given output format, one of OutputFormats.
+func WriteComponentTree(w io.Writer, tree *ComponentNode, format string) error {
+	switch format {
+	case OutputFormatTree:
+		return writeTree(w, tree)
+	case OutputFormatTable:
+		return writeTable(w, tree)
+	case OutputFormatJSON:
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling component tree: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputFormatYAML:
+		data, err := yaml.Marshal(tree)
+		if err != nil {
+			return fmt.Errorf("error marshalling component tree: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of %s", format, strings.Join(OutputFormats, ", "))
+	}
+}
+
+// writeTree writes the component tree, e.g.
+//
+//	github.com/openmcp-project/openmcp:v0.0.1
+//	├── resource openmcp-operator [ociImage] ociArtifact ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0
+//	└── reference cluster-provider-kind: github.com/openmcp-project/cluster-provider-kind:v0.0.1
+//	    └── resource cluster-provider-kind [ociImage] ociArtifact ghcr.io/openmcp-project/images/cluster-provider-kind:v0.0.1
+func writeTree(w io.Writer, tree *ComponentNode) error {
+	var sb strings.Builder
+	sb.WriteString(tree.Name + ":" + tree.Version + "\n")
+	writeTreeChildren(&sb, tree, "")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeTreeChildren(sb *strings.Builder, node *ComponentNode, indent string) {
+	count := len(node.Resources) + len(node.References)
+	i := 0
+	next := func() (string, string) {
+		i++
+		if i == count {
+			return indent + "└── ", indent + "    "
+		}
+		return indent + "├── ", indent + "│   "
+	}
+
+	for _, resource := range node.Resources {
+		prefix, _ := next()
+		sb.WriteString(fmt.Sprintf("%sresource %s [%s] %s\n", prefix, resource.Name, resource.Type, formatAccess(resource.Access)))
+	}
+
+	for _, ref := range node.References {
+		prefix, childIndent := next()
+		line := fmt.Sprintf("%sreference %s: %s:%s", prefix, ref.ReferenceName, ref.Name, ref.Version)
+		if ref.Error != "" {
+			line += " (error: " + ref.Error + ")"
+		}
+		sb.WriteString(line + "\n")
+		writeTreeChildren(sb, ref, childIndent)
+	}
+}
+
+// writeTable writes one row per resource. The reference path is the path of reference names from the root component version,
+// as used in component.fluxcdTemplateResourcePath.
+func writeTable(w io.Writer, tree *ComponentNode) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "REFERENCE PATH\tCOMPONENT\tVERSION\tRESOURCE\tTYPE\tACCESS"); err != nil {
+		return err
+	}
+
+	var writeRows func(node *ComponentNode, path []string) error
+	writeRows = func(node *ComponentNode, path []string) error {
+		referencePath := strings.Join(path, "/")
+		if len(path) == 0 {
+			referencePath = "-"
+		}
+
+		switch {
+		case node.Error != "":
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t\t\terror: %s\n", referencePath, node.Name, node.Version, node.Error); err != nil {
+				return err
+			}
+		case len(node.Resources) == 0:
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t\t\t\n", referencePath, node.Name, node.Version); err != nil {
+				return err
+			}
+		}
+		for _, resource := range node.Resources {
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", referencePath, node.Name, node.Version, resource.Name, resource.Type, formatAccess(resource.Access)); err != nil {
+				return err
+			}
+		}
+
+		for _, ref := range node.References {
+			if err := writeRows(ref, append(path[:len(path):len(path)], ref.ReferenceName)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := writeRows(tree, nil); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+// formatAccess returns the access type followed by the image reference or local reference of the resource.
+func formatAccess(access AccessNode) string {
+	result := access.Type
+	switch {
+	case access.ImageReference != "":
+		result += " " + access.ImageReference
+	case access.LocalReference != "":
+		result += " " + access.LocalReference
+		if access.ReferenceName != "" {
+			result += " (" + access.ReferenceName + ")"
+		}
+	}
+	return result
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
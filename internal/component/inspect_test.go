@@ -0,0 +1,101 @@
+package component_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/bootstrapper/internal/component"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+const (
+	inspectRootComponent     = "github.com/openmcp-project/bootstrapper/test-inspect-root"
+	inspectProviderComponent = "github.com/openmcp-project/bootstrapper/test-inspect-provider"
+)
+
+func TestWriteComponentTree(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/01/component-constructor.yaml", t)
+
+	compGetter := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.1", ctf, inspectRootComponent), "", ocmcli.NoOcmConfig)
+	if !assert.NoError(t, compGetter.InitializeComponents(t.Context())) {
+		return
+	}
+
+	tree := component.BuildComponentTree(compGetter.Graph())
+	if !assert.Len(t, tree.References, 1) || !assert.Len(t, tree.References[0].Resources, 2) {
+		return
+	}
+	localReference := tree.References[0].Resources[1].Access.LocalReference
+	assert.NotEmpty(t, localReference)
+
+	testCases := []struct {
+		desc        string
+		format      string
+		expected    string
+		expectError bool
+	}{
+		{
+			desc:   "should write a tree",
+			format: component.OutputFormatTree,
+			expected: inspectRootComponent + `:v0.0.1
+├── resource openmcp-operator [ociImage] ociArtifact ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0
+└── reference cluster-provider-test: ` + inspectProviderComponent + `:v0.1.0
+    ├── resource cluster-provider-test [ociImage] ociArtifact ghcr.io/openmcp-project/images/cluster-provider-test:v0.1.0
+    └── resource templates [blob] localBlob ` + localReference + `
+`,
+		},
+		{
+			desc:   "should write a table",
+			format: component.OutputFormatTable,
+			expected: `REFERENCE PATH         COMPONENT                                                      VERSION  RESOURCE               TYPE      ACCESS
+-                      github.com/openmcp-project/bootstrapper/test-inspect-root      v0.0.1   openmcp-operator       ociImage  ociArtifact ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0
+cluster-provider-test  github.com/openmcp-project/bootstrapper/test-inspect-provider  v0.1.0   cluster-provider-test  ociImage  ociArtifact ghcr.io/openmcp-project/images/cluster-provider-test:v0.1.0
+cluster-provider-test  github.com/openmcp-project/bootstrapper/test-inspect-provider  v0.1.0   templates              blob      localBlob ` + localReference + `
+`,
+		},
+		{
+			desc:   "should write JSON",
+			format: component.OutputFormatJSON,
+		},
+		{
+			desc:   "should write YAML",
+			format: component.OutputFormatYAML,
+		},
+		{
+			desc:        "should fail for an unsupported format",
+			format:      "xml",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := component.WriteComponentTree(&buf, tree, tc.format)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			switch tc.format {
+			case component.OutputFormatJSON:
+				var decoded component.ComponentNode
+				assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+				assert.Equal(t, *tree, decoded)
+			case component.OutputFormatYAML:
+				var decoded component.ComponentNode
+				assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &decoded))
+				assert.Equal(t, *tree, decoded)
+			default:
+				assert.Equal(t, tc.expected, buf.String())
+			}
+		})
+	}
+}
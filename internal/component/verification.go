@@ -0,0 +1,32 @@
+package component
+
+import (
+	cfg "github.com/openmcp-project/bootstrapper/internal/config"
+	"github.com/openmcp-project/bootstrapper/internal/log"
+	ocm_cli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// ConfigureVerification configures the signature verification of the component getter, if a verification is configured.
+// It must be called before the components are initialized, so that they are verified before they are used.
+func ConfigureVerification(verification cfg.Verification, componentGetter *ocm_cli.ComponentGetter) {
+	logger := log.GetLogger()
+
+	if len(verification.Signatures) == 0 {
+		logger.Debug("No signature verification configured")
+		return
+	}
+
+	options := ocm_cli.VerifyOptions{}
+	names := make([]string, 0, len(verification.Signatures))
+	for _, signature := range verification.Signatures {
+		options.Signatures = append(options.Signatures, ocm_cli.SignatureVerification{
+			Name:          signature.Name,
+			PublicKeyPath: signature.PublicKeyPath,
+			CACertPath:    signature.CACertPath,
+		})
+		names = append(names, signature.Name)
+	}
+
+	logger.Infof("Verifying signatures %v of component version %s and all referenced component versions", names, componentGetter.RootComponentLocation())
+	componentGetter.SetVerification(options)
+}
@@ -2,13 +2,34 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/google/go-containerregistry/pkg/name"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/bootstrapper/internal/forge"
 )
 
+const (
+	// DefaultFluxcdTemplateResourcePath is the default of component.fluxcdTemplateResourcePath.
+	DefaultFluxcdTemplateResourcePath = "gitops-templates/fluxcd"
+	// DefaultPullRequestBranchPrefix is the default of repository.pullRequest.branchPrefix.
+	DefaultPullRequestBranchPrefix = "openmcp-bootstrapper/"
+
+	// MirrorPolicyFailFast fails at the first mirror that cannot be pushed to, the remaining mirrors are skipped.
+	MirrorPolicyFailFast = "failFast"
+	// MirrorPolicyBestEffort pushes to all mirrors and only warns about the mirrors that cannot be pushed to.
+	MirrorPolicyBestEffort = "bestEffort"
+)
+
+// MirrorPolicies are the supported values of repository.mirrorPolicy.
+var MirrorPolicies = []string{MirrorPolicyFailFast, MirrorPolicyBestEffort}
+
 type BootstrapperConfig struct {
 	Component            Component              `json:"component"`
 	DeploymentRepository DeploymentRepository   `json:"repository"`
@@ -18,6 +39,11 @@ type BootstrapperConfig struct {
 	Environment          string                 `json:"environment"`
 	TemplateInput        map[string]interface{} `json:"templateInput"`
 	ExternalSecrets      ExternalSecrets        `json:"externalSecrets"`
+	AirGap               AirGap                 `json:"airGap"`
+	ImageRelocation      ImageRelocation        `json:"imageRelocation"`
+	Verification         Verification           `json:"verification"`
+	// ImagePullSecretFromOCMConfig creates an image pull secret from the registry credentials of the OCM configuration.
+	ImagePullSecretFromOCMConfig *ImagePullSecretFromOCMConfig `json:"imagePullSecretFromOCMConfig"`
 }
 
 type Component struct {
@@ -33,6 +59,53 @@ type DeploymentRepository struct {
 	// Provider sets the Flux GitRepository spec.provider (e.g. "github" for GitHub App auth).
 	// Empty preserves the default secretRef-based auth.
 	Provider string `json:"provider"`
+	// PullRequest enables the pull request mode of manage-deployment-repo for repositories with protected branches.
+	// Nil pushes the changes directly to the push branch.
+	PullRequest *PullRequest `json:"pullRequest"`
+	// Clone limits the clone of the deployment repository by manage-deployment-repo.
+	// Nil clones all branches with their full history.
+	Clone *Clone `json:"clone"`
+	// Mirrors are further git servers the pushed commit is mirrored to after the push to the deployment repository,
+	// e.g. a disaster recovery mirror.
+	Mirrors []Mirror `json:"mirrors"`
+	// MirrorPolicy is MirrorPolicyFailFast or MirrorPolicyBestEffort. Defaults to MirrorPolicyFailFast.
+	MirrorPolicy string `json:"mirrorPolicy"`
+}
+
+// Mirror is a git server the deployment repository is mirrored to.
+type Mirror struct {
+	// URL is the URL of the mirror repository.
+	URL string `json:"url"`
+	// GitConfig is the path to the git configuration file containing the credentials of the mirror.
+	// Defaults to the git configuration of the deployment repository.
+	GitConfig string `json:"gitConfig"`
+}
+
+// Clone limits the history, branches and paths of the clone of the deployment repository.
+type Clone struct {
+	// Depth limits the fetched history to the given number of commits. 0 fetches the full history.
+	Depth int `json:"depth"`
+	// SingleBranch fetches only the pull branch and the push branch instead of all branches.
+	SingleBranch bool `json:"singleBranch"`
+	// Sparse checks out only the paths managed by the bootstrapper, "envs/<environment>" and "resources".
+	Sparse bool `json:"sparse"`
+}
+
+// PullRequest configures the pull request mode, in which the changes are committed to a generated feature branch
+// and a pull request, or merge request in GitLab terms, against the pull branch is opened through the forge API.
+type PullRequest struct {
+	// Forge is the type of the git server, "github" or "gitlab".
+	Forge string `json:"forge"`
+	// APIURL is the URL of the forge API. Defaults to "https://api.github.com" for github.com,
+	// "<host>/api/v3" for GitHub Enterprise Server and "<host>/api/v4" for GitLab.
+	APIURL string `json:"apiURL"`
+	// Repository is the path of the repository, e.g. "openmcp-project/deployment". Defaults to the path of the repository URL.
+	Repository string `json:"repository"`
+	// BranchPrefix is the prefix of the generated feature branches. Defaults to "openmcp-bootstrapper/".
+	BranchPrefix string `json:"branchPrefix"`
+	// TokenFromEnv is the name of the environment variable containing the token of the forge API.
+	// Defaults to the password or bearer token of the git configuration.
+	TokenFromEnv string `json:"tokenFromEnv"`
 }
 
 type TargetCluster struct {
@@ -46,7 +119,15 @@ type Providers struct {
 }
 
 type Provider struct {
-	Name         string          `json:"name"`
+	Name string `json:"name"`
+	// ComponentName overrides the name of the provider component.
+	// By default, the provider component is the component the root component references, e.g. as "cluster-provider-<name>".
+	// If the root component does not reference the provider, the component name and version are required.
+	ComponentName string `json:"componentName"`
+	// Version overrides the version of the provider component, e.g. to roll out a hotfix. It may be a version constraint like "~0.1.0".
+	Version string `json:"version"`
+	// Image overrides the image of the provider. It is relocated like the images of the component.
+	Image        string          `json:"image"`
 	Config       json.RawMessage `json:"config"`
 	ConfigParsed map[string]interface{}
 }
@@ -67,6 +148,60 @@ type ExternalSecrets struct {
 	ImagePullSecrets    []meta.LocalObjectReference `json:"imagePullSecrets"`
 }
 
+// AirGap configures the air-gapped mode, in which the cluster pulls all images from a mirror registry.
+type AirGap struct {
+	// MirrorRegistry is the registry host with an optional path prefix, e.g. "registry.internal:5000/openmcp".
+	// The images of the component are pushed into this registry by the mirror-images command,
+	// and all manifests are rendered with image references pointing to it.
+	// Empty disables the air-gapped mode.
+	MirrorRegistry string `json:"mirrorRegistry"`
+	// Insecure allows pushing images to the mirror registry via plain HTTP.
+	Insecure bool `json:"insecure"`
+}
+
+// ImagePullSecretFromOCMConfig configures an image pull secret of type kubernetes.io/dockerconfigjson, which is created
+// from the registry credentials of the OCM configuration, e.g. those of a DockerConfig repository.
+type ImagePullSecretFromOCMConfig struct {
+	// Name is the name of the image pull secret. It is added to the imagePullSecrets.
+	Name string `json:"name"`
+	// Registries are the registries whose credentials are stored in the secret, e.g. "ghcr.io" or "registry.internal:5000".
+	// Only the credentials of these registries are copied into the cluster.
+	Registries []string `json:"registries"`
+	// Namespaces are the namespaces in which the secret is created. Defaults to "flux-system" and "openmcp-system".
+	Namespaces []string `json:"namespaces"`
+}
+
+// Verification configures the verification of the OCM signatures of the component before any of its resources is used.
+type Verification struct {
+	// Signatures are the signatures that must be present and valid on the root component version.
+	// Referenced component versions are verified by their digests, which are covered by the signature of the root component version.
+	// An empty list disables the verification.
+	Signatures []SignatureVerification `json:"signatures"`
+}
+
+// SignatureVerification configures the key to verify a signature with.
+type SignatureVerification struct {
+	// Name is the name of the signature.
+	Name string `json:"name"`
+	// PublicKeyPath is the path to a PEM encoded RSA public key or certificate.
+	PublicKeyPath string `json:"publicKeyPath"`
+	// CACertPath is the path to PEM encoded CA certificates, which must have issued the certificate contained in the signature.
+	CACertPath string `json:"caCertPath"`
+}
+
+// ImageRelocation configures how image references of the component are rewritten before they are rendered into manifests.
+type ImageRelocation struct {
+	// Rules are the prefix rewrite rules. The first rule whose prefix matches an image reference is applied.
+	Rules []ImageRelocationRule `json:"rules"`
+}
+
+// ImageRelocationRule rewrites image references starting with From, e.g. "ghcr.io/openmcp-project", to start with To, e.g. "registry.internal/openmcp".
+// From only matches at a path, tag or digest boundary, so "ghcr.io/openmcp" does not match "ghcr.io/openmcp-project/image".
+type ImageRelocationRule struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
 func (c *BootstrapperConfig) ReadFromFile(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -78,7 +213,7 @@ func (c *BootstrapperConfig) ReadFromFile(path string) error {
 
 func (c *BootstrapperConfig) SetDefaults() {
 	if len(c.Component.FluxcdTemplateResourcePath) == 0 {
-		c.Component.FluxcdTemplateResourcePath = "gitops-templates/fluxcd"
+		c.Component.FluxcdTemplateResourcePath = DefaultFluxcdTemplateResourcePath
 	}
 
 	if len(c.Component.OpenMCPOperatorTemplateResourcePath) == 0 {
@@ -92,6 +227,23 @@ func (c *BootstrapperConfig) SetDefaults() {
 	if len(c.DeploymentRepository.Provider) == 0 {
 		c.DeploymentRepository.Provider = "generic"
 	}
+
+	if len(c.DeploymentRepository.MirrorPolicy) == 0 {
+		c.DeploymentRepository.MirrorPolicy = MirrorPolicyFailFast
+	}
+
+	if c.DeploymentRepository.PullRequest != nil && len(c.DeploymentRepository.PullRequest.BranchPrefix) == 0 {
+		c.DeploymentRepository.PullRequest.BranchPrefix = DefaultPullRequestBranchPrefix
+	}
+
+	if c.ImagePullSecretFromOCMConfig != nil {
+		if len(c.ImagePullSecretFromOCMConfig.Namespaces) == 0 {
+			c.ImagePullSecretFromOCMConfig.Namespaces = []string{"flux-system", "openmcp-system"}
+		}
+		if len(c.ImagePullSecretFromOCMConfig.Name) > 0 && !slices.Contains(c.ImagePullSecrets, c.ImagePullSecretFromOCMConfig.Name) {
+			c.ImagePullSecrets = append(c.ImagePullSecrets, c.ImagePullSecretFromOCMConfig.Name)
+		}
+	}
 }
 
 func (c *BootstrapperConfig) Validate() error {
@@ -113,6 +265,39 @@ func (c *BootstrapperConfig) Validate() error {
 		errs = append(errs, field.Required(field.NewPath("repository.pullBranch"), "repository pull branch is required"))
 	}
 
+	if pr := c.DeploymentRepository.PullRequest; pr != nil {
+		prPath := field.NewPath("repository.pullRequest")
+		if !slices.Contains(forge.Kinds, pr.Forge) {
+			errs = append(errs, field.NotSupported(prPath.Child("forge"), pr.Forge, forge.Kinds))
+		}
+		if strings.Contains(pr.BranchPrefix, " ") || strings.Contains(pr.BranchPrefix, "..") {
+			errs = append(errs, field.Invalid(prPath.Child("branchPrefix"), pr.BranchPrefix, "branch prefix must be a valid branch name"))
+		}
+		if len(pr.APIURL) > 0 && !strings.HasPrefix(pr.APIURL, "https://") && !strings.HasPrefix(pr.APIURL, "http://") {
+			errs = append(errs, field.Invalid(prPath.Child("apiURL"), pr.APIURL, "API URL must be an http or https URL"))
+		}
+	}
+
+	if clone := c.DeploymentRepository.Clone; clone != nil && clone.Depth < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("repository.clone.depth"), clone.Depth, "clone depth must not be negative"))
+	}
+
+	mirrorsPath := field.NewPath("repository.mirrors")
+	for i, mirror := range c.DeploymentRepository.Mirrors {
+		if len(mirror.URL) == 0 {
+			errs = append(errs, field.Required(mirrorsPath.Index(i).Child("url"), "mirror url is required"))
+		} else if mirror.URL == c.DeploymentRepository.RepoURL {
+			errs = append(errs, field.Invalid(mirrorsPath.Index(i).Child("url"), mirror.URL, "mirror url must differ from the repository url"))
+		}
+	}
+	if clone := c.DeploymentRepository.Clone; clone != nil && clone.Depth > 0 && len(c.DeploymentRepository.Mirrors) > 0 {
+		// a mirror that is behind can only be fast-forwarded with the history between its head and the pushed commit
+		errs = append(errs, field.Invalid(field.NewPath("repository.clone.depth"), clone.Depth, "clone depth must be 0 if mirrors are configured"))
+	}
+	if len(c.DeploymentRepository.MirrorPolicy) > 0 && !slices.Contains(MirrorPolicies, c.DeploymentRepository.MirrorPolicy) {
+		errs = append(errs, field.NotSupported(field.NewPath("repository.mirrorPolicy"), c.DeploymentRepository.MirrorPolicy, MirrorPolicies))
+	}
+
 	if len(c.OpenMCPOperator.Config) == 0 {
 		errs = append(errs, field.Required(field.NewPath("openmcpOperator.config"), "openmcp operator config is required"))
 	}
@@ -127,6 +312,8 @@ func (c *BootstrapperConfig) Validate() error {
 			errs = append(errs, field.Required(field.NewPath("providers.clusterProviders").Index(i).Child("name"), "cluster provider name is required"))
 		}
 
+		errs = append(errs, validateProviderOverrides(field.NewPath("providers.clusterProviders").Index(i), cp)...)
+
 		if cp.Config != nil {
 			err := yaml.Unmarshal(cp.Config, &c.Providers.ClusterProviders[i].ConfigParsed)
 			if err != nil {
@@ -140,6 +327,8 @@ func (c *BootstrapperConfig) Validate() error {
 			errs = append(errs, field.Required(field.NewPath("providers.serviceProviders").Index(i).Child("name"), "service provider name is required"))
 		}
 
+		errs = append(errs, validateProviderOverrides(field.NewPath("providers.serviceProviders").Index(i), sp)...)
+
 		if sp.Config != nil {
 			err := yaml.Unmarshal(sp.Config, &c.Providers.ServiceProviders[i].ConfigParsed)
 			if err != nil {
@@ -153,6 +342,8 @@ func (c *BootstrapperConfig) Validate() error {
 			errs = append(errs, field.Required(field.NewPath("providers.platformServices").Index(i).Child("name"), "platform service name is required"))
 		}
 
+		errs = append(errs, validateProviderOverrides(field.NewPath("providers.platformServices").Index(i), ps)...)
+
 		if ps.Config != nil {
 			err := yaml.Unmarshal(ps.Config, &c.Providers.PlatformServices[i].ConfigParsed)
 			if err != nil {
@@ -161,5 +352,73 @@ func (c *BootstrapperConfig) Validate() error {
 		}
 	}
 
+	if len(c.AirGap.MirrorRegistry) > 0 {
+		if strings.Contains(c.AirGap.MirrorRegistry, "://") {
+			errs = append(errs, field.Invalid(field.NewPath("airGap.mirrorRegistry"), c.AirGap.MirrorRegistry, "mirror registry must not contain a scheme"))
+		} else if _, err := name.NewRepository(strings.TrimSuffix(c.AirGap.MirrorRegistry, "/") + "/image"); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("airGap.mirrorRegistry"), c.AirGap.MirrorRegistry, fmt.Sprintf("mirror registry is not a valid registry: %v", err)))
+		}
+	}
+
+	for i, signature := range c.Verification.Signatures {
+		signaturePath := field.NewPath("verification.signatures").Index(i)
+		if len(signature.Name) == 0 {
+			errs = append(errs, field.Required(signaturePath.Child("name"), "signature name is required"))
+		}
+		if len(signature.PublicKeyPath) == 0 && len(signature.CACertPath) == 0 {
+			errs = append(errs, field.Required(signaturePath.Child("publicKeyPath"), "public key or CA certificate is required"))
+		}
+	}
+
+	if secret := c.ImagePullSecretFromOCMConfig; secret != nil {
+		secretPath := field.NewPath("imagePullSecretFromOCMConfig")
+		if len(secret.Name) == 0 {
+			errs = append(errs, field.Required(secretPath.Child("name"), "image pull secret name is required"))
+		}
+		if len(secret.Registries) == 0 {
+			errs = append(errs, field.Required(secretPath.Child("registries"), "at least one registry is required"))
+		}
+		for i, registry := range secret.Registries {
+			if _, err := name.NewRegistry(registry); err != nil || strings.Contains(registry, "/") {
+				errs = append(errs, field.Invalid(secretPath.Child("registries").Index(i), registry, "registry must be a registry host with an optional port"))
+			}
+		}
+	}
+
+	for i, rule := range c.ImageRelocation.Rules {
+		rulePath := field.NewPath("imageRelocation.rules").Index(i)
+		if len(rule.From) == 0 {
+			errs = append(errs, field.Required(rulePath.Child("from"), "image relocation rule prefix is required"))
+		}
+		if len(rule.To) == 0 {
+			errs = append(errs, field.Required(rulePath.Child("to"), "image relocation rule replacement is required"))
+		}
+		if strings.Contains(rule.From, "://") {
+			errs = append(errs, field.Invalid(rulePath.Child("from"), rule.From, "image relocation rule prefix must not contain a scheme"))
+		}
+		if strings.Contains(rule.To, "://") {
+			errs = append(errs, field.Invalid(rulePath.Child("to"), rule.To, "image relocation rule replacement must not contain a scheme"))
+		}
+	}
+
 	return errs.ToAggregate()
 }
+
+// validateProviderOverrides validates the component and image overrides of a provider.
+func validateProviderOverrides(providerPath *field.Path, p Provider) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if len(p.Image) > 0 {
+		if strings.Contains(p.Image, "://") {
+			errs = append(errs, field.Invalid(providerPath.Child("image"), p.Image, "image must not contain a scheme"))
+		} else if _, err := name.ParseReference(p.Image); err != nil {
+			errs = append(errs, field.Invalid(providerPath.Child("image"), p.Image, fmt.Sprintf("image is not a valid image reference: %v", err)))
+		}
+	}
+
+	if strings.ContainsAny(p.ComponentName, ": ") {
+		errs = append(errs, field.Invalid(providerPath.Child("componentName"), p.ComponentName, "component name must not contain a version"))
+	}
+
+	return errs
+}
@@ -40,3 +40,380 @@ func TestSetDefaults_Provider(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_AirGap(t *testing.T) {
+	tests := []struct {
+		name           string
+		mirrorRegistry string
+		expectError    bool
+	}{
+		{
+			name:           "air gap disabled",
+			mirrorRegistry: "",
+		},
+		{
+			name:           "registry with port and path",
+			mirrorRegistry: "registry.internal:5000/openmcp",
+		},
+		{
+			name:           "registry with scheme",
+			mirrorRegistry: "https://registry.internal",
+			expectError:    true,
+		},
+		{
+			name:           "invalid registry",
+			mirrorRegistry: "Registry Internal",
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.AirGap.MirrorRegistry = tt.mirrorRegistry
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidate_ImageRelocation(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       []config.ImageRelocationRule
+		expectError bool
+	}{
+		{
+			name:  "valid rules",
+			rules: []config.ImageRelocationRule{{From: "ghcr.io/openmcp-project", To: "registry.internal/openmcp"}},
+		},
+		{
+			name:        "missing prefix",
+			rules:       []config.ImageRelocationRule{{To: "registry.internal/openmcp"}},
+			expectError: true,
+		},
+		{
+			name:        "missing replacement",
+			rules:       []config.ImageRelocationRule{{From: "ghcr.io/openmcp-project"}},
+			expectError: true,
+		},
+		{
+			name:        "replacement with scheme",
+			rules:       []config.ImageRelocationRule{{From: "ghcr.io/openmcp-project", To: "https://registry.internal/openmcp"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.ImageRelocation.Rules = tt.rules
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Verification(t *testing.T) {
+	tests := []struct {
+		name        string
+		signatures  []config.SignatureVerification
+		expectError bool
+	}{
+		{
+			name:       "public key",
+			signatures: []config.SignatureVerification{{Name: "openmcp", PublicKeyPath: "./keys/openmcp.pub"}},
+		},
+		{
+			name:       "CA certificate",
+			signatures: []config.SignatureVerification{{Name: "openmcp", CACertPath: "./keys/ca.pem"}},
+		},
+		{
+			name:        "missing name",
+			signatures:  []config.SignatureVerification{{PublicKeyPath: "./keys/openmcp.pub"}},
+			expectError: true,
+		},
+		{
+			name:        "missing key",
+			signatures:  []config.SignatureVerification{{Name: "openmcp"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Verification.Signatures = tt.signatures
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func newValidConfig() *config.BootstrapperConfig {
+	return &config.BootstrapperConfig{
+		Environment: "test",
+		Component: config.Component{
+			OpenMCPComponentLocation: "ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.1",
+		},
+		DeploymentRepository: config.DeploymentRepository{
+			RepoURL:    "https://example.com/repo",
+			PullBranch: "main",
+		},
+		OpenMCPOperator: config.OpenMCPOperator{
+			Config: []byte(`{"managedControlPlane":{}}`),
+		},
+	}
+}
+
+func TestValidate_ProviderOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    config.Provider
+		expectError bool
+	}{
+		{
+			name:     "version override",
+			provider: config.Provider{Name: "kind", Version: "v0.1.1"},
+		},
+		{
+			name:     "component and image override",
+			provider: config.Provider{Name: "kind", ComponentName: "github.com/acme/cluster-provider-kind", Version: "~0.1.0", Image: "registry.internal/cluster-provider-kind:v0.1.1"},
+		},
+		{
+			name:        "invalid image",
+			provider:    config.Provider{Name: "kind", Image: "registry.internal/Cluster-Provider-Kind:v0.1.1"},
+			expectError: true,
+		},
+		{
+			name:        "image with scheme",
+			provider:    config.Provider{Name: "kind", Image: "https://registry.internal/cluster-provider-kind:v0.1.1"},
+			expectError: true,
+		},
+		{
+			name:        "component name with version",
+			provider:    config.Provider{Name: "kind", ComponentName: "github.com/acme/cluster-provider-kind:v0.1.1"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Providers.ClusterProviders = []config.Provider{tt.provider}
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidate_ImagePullSecretFromOCMConfig(t *testing.T) {
+	tests := []struct {
+		name                     string
+		secret                   *config.ImagePullSecretFromOCMConfig
+		expectedImagePullSecrets []string
+		expectedNamespaces       []string
+		expectError              bool
+	}{
+		{
+			name:                     "defaults",
+			secret:                   &config.ImagePullSecretFromOCMConfig{Name: "registry-credentials", Registries: []string{"ghcr.io", "registry.internal:5000"}},
+			expectedImagePullSecrets: []string{"existing", "registry-credentials"},
+			expectedNamespaces:       []string{"flux-system", "openmcp-system"},
+		},
+		{
+			name:                     "explicit namespaces",
+			secret:                   &config.ImagePullSecretFromOCMConfig{Name: "existing", Registries: []string{"ghcr.io"}, Namespaces: []string{"flux-system"}},
+			expectedImagePullSecrets: []string{"existing"},
+			expectedNamespaces:       []string{"flux-system"},
+		},
+		{
+			name:        "missing name",
+			secret:      &config.ImagePullSecretFromOCMConfig{Registries: []string{"ghcr.io"}},
+			expectError: true,
+		},
+		{
+			name:        "missing registries",
+			secret:      &config.ImagePullSecretFromOCMConfig{Name: "registry-credentials"},
+			expectError: true,
+		},
+		{
+			name:        "registry with path",
+			secret:      &config.ImagePullSecretFromOCMConfig{Name: "registry-credentials", Registries: []string{"ghcr.io/openmcp-project"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.ImagePullSecrets = []string{"existing"}
+			cfg.ImagePullSecretFromOCMConfig = tt.secret
+			cfg.SetDefaults()
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedImagePullSecrets, cfg.ImagePullSecrets)
+			assert.Equal(t, tt.expectedNamespaces, cfg.ImagePullSecretFromOCMConfig.Namespaces)
+		})
+	}
+}
+
+func TestValidate_PullRequest(t *testing.T) {
+	tests := []struct {
+		name                 string
+		pullRequest          *config.PullRequest
+		expectedBranchPrefix string
+		expectError          bool
+	}{
+		{
+			name:                 "github with defaults",
+			pullRequest:          &config.PullRequest{Forge: "github"},
+			expectedBranchPrefix: config.DefaultPullRequestBranchPrefix,
+		},
+		{
+			name:                 "gitlab with API URL and branch prefix",
+			pullRequest:          &config.PullRequest{Forge: "gitlab", APIURL: "https://gitlab.example.com/api/v4", BranchPrefix: "update/"},
+			expectedBranchPrefix: "update/",
+		},
+		{
+			name:        "unsupported forge",
+			pullRequest: &config.PullRequest{Forge: "bitbucket"},
+			expectError: true,
+		},
+		{
+			name:        "API URL without scheme",
+			pullRequest: &config.PullRequest{Forge: "github", APIURL: "api.github.com"},
+			expectError: true,
+		},
+		{
+			name:        "invalid branch prefix",
+			pullRequest: &config.PullRequest{Forge: "github", BranchPrefix: "update .."},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.DeploymentRepository.PullRequest = tt.pullRequest
+			cfg.SetDefaults()
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBranchPrefix, cfg.DeploymentRepository.PullRequest.BranchPrefix)
+		})
+	}
+}
+
+func TestValidate_Clone(t *testing.T) {
+	tests := []struct {
+		name        string
+		clone       *config.Clone
+		expectError bool
+	}{
+		{
+			name:  "shallow single branch sparse clone",
+			clone: &config.Clone{Depth: 1, SingleBranch: true, Sparse: true},
+		},
+		{
+			name:        "negative depth",
+			clone:       &config.Clone{Depth: -1},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.DeploymentRepository.Clone = tt.clone
+			cfg.SetDefaults()
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidate_Mirrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		mirrors      []config.Mirror
+		mirrorPolicy string
+		clone        *config.Clone
+		expectError  bool
+	}{
+		{
+			name:    "mirror with default policy",
+			mirrors: []config.Mirror{{URL: "https://mirror.example.com/deployment.git", GitConfig: "mirror-git-config.yaml"}},
+		},
+		{
+			name:         "best effort mirrors",
+			mirrors:      []config.Mirror{{URL: "https://mirror1.example.com/deployment.git"}, {URL: "https://mirror2.example.com/deployment.git"}},
+			mirrorPolicy: config.MirrorPolicyBestEffort,
+		},
+		{
+			name:        "mirror without url",
+			mirrors:     []config.Mirror{{GitConfig: "mirror-git-config.yaml"}},
+			expectError: true,
+		},
+		{
+			name:         "unsupported mirror policy",
+			mirrors:      []config.Mirror{{URL: "https://mirror.example.com/deployment.git"}},
+			mirrorPolicy: "sometimes",
+			expectError:  true,
+		},
+		{
+			name:        "mirror of a shallow clone",
+			mirrors:     []config.Mirror{{URL: "https://mirror.example.com/deployment.git"}},
+			clone:       &config.Clone{Depth: 1},
+			expectError: true,
+		},
+		{
+			name:    "mirror of a single branch clone with full history",
+			mirrors: []config.Mirror{{URL: "https://mirror.example.com/deployment.git"}},
+			clone:   &config.Clone{SingleBranch: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.DeploymentRepository.Mirrors = tt.mirrors
+			cfg.DeploymentRepository.MirrorPolicy = tt.mirrorPolicy
+			cfg.DeploymentRepository.Clone = tt.clone
+			cfg.SetDefaults()
+			err := cfg.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package deploymentrepo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
+	"github.com/openmcp-project/bootstrapper/internal/version"
+)
+
+const (
+	// DefaultCommitMessage is the default template of the commit message of the changes in the deployment repository.
+	DefaultCommitMessage = "Update openMCP environment {{ .Values.environment }} to {{ .Values.component.version }}\n\n{{ .Values.changes.summary }}"
+)
+
+// trailerKeyRegex matches the key of a git trailer, e.g. "Signed-off-by".
+var trailerKeyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// commitMessage renders the commit message template with the release metadata of the applied component version
+// and the changes staged for the commit. The resolved component version and the trailers are appended to the message.
+// It also returns the template input, which is used to render the tag name.
+func (m *DeploymentRepoManager) commitMessage(ctx context.Context, commitMessageTemplate string) (string, map[string]interface{}, error) {
+	files, err := StagedChanges(m.gitRepo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	templateInput := m.commitMessageInput(files)
+	message, err := RenderCommitMessage(ctx, commitMessageTemplate, m.CommitTrailers, templateInput, m.relocator, m.compGetter, m.resolvedComponentVersion())
+	return message, templateInput, err
+}
+
+// RenderCommitMessage renders the commit message template with the template input, which is available under .Values.
+// The non-empty paragraphs are appended to the message, followed by the trailers in the format "Key: value".
+// The values of the trailers are rendered like the commit message, trailers with an empty value are omitted.
+func RenderCommitMessage(ctx context.Context, commitMessageTemplate string, trailers []string, templateInput map[string]interface{},
+	relocator *relocation.Relocator, compGetter *ocmcli.ComponentGetter, paragraphs ...string) (string, error) {
+	message, err := TemplateString(ctx, "commitMessage", commitMessageTemplate, templateInput, relocator, compGetter)
+	if err != nil {
+		return "", fmt.Errorf("failed to render commit message: %w", err)
+	}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "", fmt.Errorf("commit message template %q rendered an empty commit message", commitMessageTemplate)
+	}
+
+	for _, paragraph := range paragraphs {
+		if paragraph != "" {
+			message += "\n\n" + paragraph
+		}
+	}
+
+	renderedTrailers := make([]string, 0, len(trailers))
+	for _, trailer := range trailers {
+		key, value, found := strings.Cut(trailer, ":")
+		key = strings.TrimSpace(key)
+		if !found || !trailerKeyRegex.MatchString(key) {
+			return "", fmt.Errorf("invalid commit trailer %q, expected the format \"Key: value\"", trailer)
+		}
+
+		value, err = TemplateString(ctx, "commitTrailer", strings.TrimSpace(value), templateInput, relocator, compGetter)
+		if err != nil {
+			return "", fmt.Errorf("failed to render commit trailer %s: %w", key, err)
+		}
+		// trailer values are single lines
+		value = strings.Join(strings.Fields(value), " ")
+		if value != "" {
+			renderedTrailers = append(renderedTrailers, key+": "+value)
+		}
+	}
+	if len(renderedTrailers) > 0 {
+		message += "\n\n" + strings.Join(renderedTrailers, "\n")
+	}
+
+	return message, nil
+}
+
+// commitMessageInput returns the input of the commit message template, which is available under .Values like the input of the templates.
+func (m *DeploymentRepoManager) commitMessageInput(files []FileChange) map[string]interface{} {
+	templateInput := map[string]interface{}{
+		"environment": m.Config.Environment,
+		"bootstrapper": map[string]interface{}{
+			"version": version.GetVersion().GitVersion,
+		},
+		"providers": commitMessageProviders(m.Config.Providers),
+		"changes":   commitMessageChanges(files),
+	}
+
+	if m.compGetter != nil {
+		root := m.compGetter.RootComponentVersion()
+		templateInput["component"] = map[string]interface{}{
+			"name":              root.Component.Name,
+			"version":           root.Component.Version,
+			"location":          m.ComponentLocation(),
+			"versionConstraint": m.compGetter.VersionConstraint(),
+		}
+	}
+	if m.openMCPOperatorCV != nil {
+		templateInput["openmcpOperator"] = map[string]interface{}{
+			"name":    m.openMCPOperatorCV.Component.Name,
+			"version": m.openMCPOperatorCV.Component.Version,
+		}
+	}
+
+	return templateInput
+}
+
+// commitMessageProviders returns the enabled providers with their kind, e.g. "clusterProvider", and their overrides.
+func commitMessageProviders(providers config.Providers) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(providers.ClusterProviders)+len(providers.ServiceProviders)+len(providers.PlatformServices))
+	for _, kindProviders := range []struct {
+		kind      string
+		providers []config.Provider
+	}{
+		{kind: "clusterProvider", providers: providers.ClusterProviders},
+		{kind: "serviceProvider", providers: providers.ServiceProviders},
+		{kind: "platformService", providers: providers.PlatformServices},
+	} {
+		for _, provider := range kindProviders.providers {
+			result = append(result, map[string]interface{}{
+				"kind":          kindProviders.kind,
+				"name":          provider.Name,
+				"componentName": provider.ComponentName,
+				"version":       provider.Version,
+			})
+		}
+	}
+	return result
+}
+
+// commitMessageChanges returns the changed files, their number per action and a summary,
+// e.g. "3 files changed: 1 added, 2 modified, 0 deleted".
+func commitMessageChanges(files []FileChange) map[string]interface{} {
+	counts := map[string]int{FileAdded: 0, FileModified: 0, FileDeleted: 0}
+	fileList := make([]map[string]interface{}, 0, len(files))
+	for _, file := range files {
+		counts[file.Action]++
+		fileList = append(fileList, map[string]interface{}{
+			"action": file.Action,
+			"path":   file.Path,
+		})
+	}
+
+	noun := "files"
+	if len(files) == 1 {
+		noun = "file"
+	}
+	return map[string]interface{}{
+		"files":      fileList,
+		FileAdded:    counts[FileAdded],
+		FileModified: counts[FileModified],
+		FileDeleted:  counts[FileDeleted],
+		"summary": fmt.Sprintf("%d %s changed: %d added, %d modified, %d deleted",
+			len(files), noun, counts[FileAdded], counts[FileModified], counts[FileDeleted]),
+	}
+}
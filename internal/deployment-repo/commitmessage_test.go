@@ -0,0 +1,95 @@
+package deploymentrepo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	deploymentrepo "github.com/openmcp-project/bootstrapper/internal/deployment-repo"
+)
+
+func TestRenderCommitMessage(t *testing.T) {
+	templateInput := map[string]interface{}{
+		"environment": "dev",
+		"component": map[string]interface{}{
+			"name":    "github.com/openmcp-project/openmcp",
+			"version": "v0.0.2",
+		},
+		"openmcpOperator": map[string]interface{}{
+			"version": "v0.1.0",
+		},
+		"providers": []map[string]interface{}{
+			{"kind": "clusterProvider", "name": "kind"},
+			{"kind": "serviceProvider", "name": "crossplane"},
+		},
+		"changes": map[string]interface{}{
+			"summary": "2 files changed: 1 added, 1 modified, 0 deleted",
+		},
+	}
+
+	testCases := []struct {
+		desc            string
+		template        string
+		trailers        []string
+		paragraphs      []string
+		expectedMessage string
+		expectError     bool
+	}{
+		{
+			desc:            "should render the default commit message",
+			template:        deploymentrepo.DefaultCommitMessage,
+			expectedMessage: "Update openMCP environment dev to v0.0.2\n\n2 files changed: 1 added, 1 modified, 0 deleted",
+		},
+		{
+			desc:            "should keep a static commit message",
+			template:        "apply templates",
+			expectedMessage: "apply templates",
+		},
+		{
+			desc:            "should render providers and append paragraphs and trailers",
+			template:        "Deploy {{ .Values.component.version }}\n\nProviders:{{ range .Values.providers }} {{ .name }}{{ end }}",
+			trailers:        []string{"Openmcp-Operator: {{ .Values.openmcpOperator.version }}", "Environment:{{ .Values.environment }}", "Empty: {{ if .Values.dryRun }}true{{ end }}"},
+			paragraphs:      []string{"", "Component version resolved"},
+			expectedMessage: "Deploy v0.0.2\n\nProviders: kind crossplane\n\nComponent version resolved\n\nOpenmcp-Operator: v0.1.0\nEnvironment: dev",
+		},
+		{
+			desc:        "should fail for an empty commit message",
+			template:    "{{ if .Values.dryRun }}dry run{{ end }}",
+			expectError: true,
+		},
+		{
+			desc:        "should fail for a missing value",
+			template:    "Deploy {{ .Values.component.missing }}",
+			expectError: true,
+		},
+		{
+			desc:        "should fail for an invalid template",
+			template:    "{{ .Values.environment ",
+			expectError: true,
+		},
+		{
+			desc:        "should fail for a trailer without key",
+			template:    "apply templates",
+			trailers:    []string{"no trailer"},
+			expectError: true,
+		},
+		{
+			desc:        "should fail for a trailer key with whitespace",
+			template:    "apply templates",
+			trailers:    []string{"Reviewed by: someone"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			message, err := deploymentrepo.RenderCommitMessage(t.Context(), tc.template, tc.trailers, templateInput, nil, nil, tc.paragraphs...)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMessage, message)
+		})
+	}
+}
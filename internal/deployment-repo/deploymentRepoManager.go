@@ -3,24 +3,30 @@ package deploymentrepo
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/openmcp-project/controller-utils/pkg/clusters"
 	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/yaml"
 
+	"github.com/openmcp-project/bootstrapper/internal/component"
 	"github.com/openmcp-project/bootstrapper/internal/config"
 
 	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
 	"github.com/openmcp-project/bootstrapper/internal/log"
 	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/util"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -69,6 +75,21 @@ type DeploymentRepoManager struct {
 
 	PatchesFile string
 
+	// PushRetries is the number of times a push rejected because the remote branch moved is retried
+	// on top of the new remote head
+	PushRetries int
+
+	// RepoDir is an optional existing checkout of the deployment repository, which is used instead of cloning the repository
+	RepoDir string
+
+	// CommitTrailers are the git trailers appended to the commit message, in the format "Key: value".
+	// The values are templates rendered like the commit message.
+	CommitTrailers []string
+
+	// TagTemplate is the optional template of the name of an annotated tag, which is created on the pushed commit
+	// and pushed to the deployment repository, e.g. "{{ .Values.environment }}/openmcp-{{ .Values.component.version }}"
+	TagTemplate string
+
 	// Internals
 	// workDir is a temporary directory used for processing
 	workDir string
@@ -76,11 +97,13 @@ type DeploymentRepoManager struct {
 	// (a subdirectory of workDir)
 	templatesDir string
 	// gitRepoDir is the directory into which the deployment repository is cloned
-	// (a subdirectory of workDir)
+	// (a subdirectory of workDir, or RepoDir if set)
 	gitRepoDir string
 
 	// compGetter is the OCM component getter used to fetch components and resources
 	compGetter *ocmcli.ComponentGetter
+	// relocator maps image references to the mirror registry in air-gapped mode
+	relocator *relocation.Relocator
 	// gitConfig is the parsed Git configuration
 	gitConfig *gitconfig.Config
 	// gitRepo is the cloned Git repository
@@ -93,6 +116,14 @@ type DeploymentRepoManager struct {
 	crdFiles []string
 	// extraManifests is a list of extra manifest files copied from the ExtraManifestDir to the deployment repository
 	extraManifests []string
+	// mirrors are the mirrors of the deployment repository the pushed commit is mirrored to
+	mirrors []MirrorRemote
+	// mirrorResults are the results of the push to the mirrors
+	mirrorResults []MirrorResult
+	// previousManagedFiles are the files managed by the previous runs by environment, read from the managed files manifests
+	previousManagedFiles map[string][]string
+	// managedFiles are the files rendered by this run, relative to the repository root
+	managedFiles []string
 }
 
 // NewDeploymentRepoManager creates a new DeploymentRepoManager with the specified parameters.
@@ -104,10 +135,36 @@ func NewDeploymentRepoManager(config *config.BootstrapperConfig, targetCluster *
 		OcmConfigPath:    ocmConfigPath,
 		ExtraManifestDir: extraManifestDir,
 		PatchesFile:      patchesFile,
+		PushRetries:      DefaultPushRetries,
 	}
 }
 
+// WithPushRetries sets the number of times a push rejected because the remote branch moved is retried.
+func (m *DeploymentRepoManager) WithPushRetries(retries int) *DeploymentRepoManager {
+	m.PushRetries = retries
+	return m
+}
+
+// WithRepoDir sets an existing checkout of the deployment repository, which is used instead of cloning the repository.
+func (m *DeploymentRepoManager) WithRepoDir(repoDir string) *DeploymentRepoManager {
+	m.RepoDir = repoDir
+	return m
+}
+
+// WithCommitTrailers sets the git trailers appended to the commit message, in the format "Key: value".
+func (m *DeploymentRepoManager) WithCommitTrailers(trailers []string) *DeploymentRepoManager {
+	m.CommitTrailers = trailers
+	return m
+}
+
+// WithTag sets the template of the name of an annotated tag, which is created on the pushed commit of the applied component version.
+func (m *DeploymentRepoManager) WithTag(tagTemplate string) *DeploymentRepoManager {
+	m.TagTemplate = tagTemplate
+	return m
+}
+
 // Initialize initializes the DeploymentRepoManager by setting up working directories, downloading components and templates, and cloning the deployment repository.
+// If an existing checkout has been set by WithRepoDir, it is used instead of cloning the deployment repository and must not contain uncommitted changes.
 func (m *DeploymentRepoManager) Initialize(ctx context.Context) (*DeploymentRepoManager, error) {
 	var err error
 
@@ -122,6 +179,12 @@ func (m *DeploymentRepoManager) Initialize(ctx context.Context) (*DeploymentRepo
 
 	m.templatesDir = filepath.Join(m.workDir, "templates")
 	m.gitRepoDir = filepath.Join(m.workDir, "repo")
+	if m.RepoDir != "" {
+		m.gitRepoDir, err = filepath.Abs(m.RepoDir)
+		if err != nil {
+			return m, fmt.Errorf("failed to get absolute path of repository directory %s: %w", m.RepoDir, err)
+		}
+	}
 
 	err = os.Mkdir(m.templatesDir, 0o755)
 	if err != nil {
@@ -130,21 +193,29 @@ func (m *DeploymentRepoManager) Initialize(ctx context.Context) (*DeploymentRepo
 
 	logger.Tracef("Created template dir: %s", m.templatesDir)
 
-	err = os.Mkdir(m.gitRepoDir, 0o755)
-	if err != nil {
-		return m, fmt.Errorf("failed to create template directory: %w", err)
-	}
+	if m.RepoDir == "" {
+		err = os.Mkdir(m.gitRepoDir, 0o755)
+		if err != nil {
+			return m, fmt.Errorf("failed to create template directory: %w", err)
+		}
 
-	logger.Tracef("Created Git repo dir: %s", m.gitRepoDir)
+		logger.Tracef("Created Git repo dir: %s", m.gitRepoDir)
+	}
 
 	logger.Infof("Downloading component %s", m.Config.Component.OpenMCPComponentLocation)
 
 	m.compGetter = ocmcli.NewComponentGetter(m.Config.Component.OpenMCPComponentLocation, m.Config.Component.FluxcdTemplateResourcePath, m.OcmConfigPath)
+	component.ConfigureVerification(m.Config.Verification, m.compGetter)
 	err = m.compGetter.InitializeComponents(ctx)
 	if err != nil {
 		return m, fmt.Errorf("failed to initialize components: %w", err)
 	}
 
+	m.relocator = relocation.NewRelocator(m.Config)
+	if m.relocator.MirrorRegistry() != "" {
+		logger.Infof("Air-gapped mode: relocating images to mirror registry %s", m.relocator.MirrorRegistry())
+	}
+
 	logger.Info("Creating template transformer")
 
 	templateTransformer := NewTemplateTransformer(m.compGetter, m.Config.Component.FluxcdTemplateResourcePath, m.Config.Component.OpenMCPOperatorTemplateResourcePath, m.workDir)
@@ -173,32 +244,91 @@ func (m *DeploymentRepoManager) Initialize(ctx context.Context) (*DeploymentRepo
 	}
 	m.fluxcdCV = &fluxcdCVs[0]
 
-	m.gitConfig, err = gitconfig.ParseConfig(m.GitConfigPath)
-	if err != nil {
-		return m, fmt.Errorf("failed to parse git config: %w", err)
+	if m.GitConfigPath != "" {
+		m.gitConfig, err = gitconfig.ParseConfig(m.GitConfigPath)
+		if err != nil {
+			return m, fmt.Errorf("failed to parse git config: %w", err)
+		}
+		err = m.gitConfig.Validate()
+		if err != nil {
+			return m, fmt.Errorf("invalid git config: %w", err)
+		}
+		err = m.gitConfig.ResolveCredentials(m.Config.DeploymentRepository.RepoURL)
+		if err != nil {
+			return m, fmt.Errorf("failed to resolve git credentials: %w", err)
+		}
+	} else {
+		// an existing checkout may be used without credentials, e.g. if the changes are not pushed
+		m.gitConfig = &gitconfig.Config{}
 	}
-	err = m.gitConfig.Validate()
+
+	m.mirrors, err = m.mirrorRemotes()
 	if err != nil {
-		return m, fmt.Errorf("invalid git config: %w", err)
+		return m, err
 	}
 
-	logger.Infof("Cloning deployment repository %s", m.Config.DeploymentRepository.RepoURL)
+	if m.RepoDir != "" {
+		logger.Infof("Using existing checkout %s of deployment repository %s", m.gitRepoDir, m.Config.DeploymentRepository.RepoURL)
 
-	m.gitRepo, err = CloneRepo(m.Config.DeploymentRepository.RepoURL, m.gitRepoDir, m.gitConfig)
-	if err != nil {
-		return m, fmt.Errorf("failed to clone deployment repository: %w", err)
-	}
+		m.gitRepo, err = OpenRepo(m.gitRepoDir)
+		if err != nil {
+			return m, fmt.Errorf("failed to open deployment repository: %w", err)
+		}
 
-	logger.Infof("Checking out or creating branch %s", m.Config.DeploymentRepository.PushBranch)
+		logger.Infof("Checking out or creating branch %s", m.baseBranch())
 
-	err = CheckoutAndCreateBranchIfNotExists(m.gitRepo, m.Config.DeploymentRepository.PushBranch, m.gitConfig)
+		err = CheckoutLocalBranch(m.gitRepo, m.baseBranch(), m.gitConfig)
+		if err != nil {
+			return m, fmt.Errorf("failed to checkout or create branch %s: %w", m.baseBranch(), err)
+		}
+	} else {
+		logger.Infof("Cloning deployment repository %s", m.Config.DeploymentRepository.RepoURL)
+
+		m.gitRepo, err = CloneRepoWithOptions(m.Config.DeploymentRepository.RepoURL, m.gitRepoDir, m.cloneOptions(), m.gitConfig)
+		if err != nil {
+			return m, fmt.Errorf("failed to clone deployment repository: %w", err)
+		}
+
+		logger.Infof("Checking out or creating branch %s", m.baseBranch())
+
+		err = CheckoutAndCreateBranchIfNotExists(m.gitRepo, m.baseBranch(), m.gitConfig)
+		if err != nil {
+			return m, fmt.Errorf("failed to checkout or create branch %s: %w", m.baseBranch(), err)
+		}
+	}
+
+	m.previousManagedFiles, err = ReadManagedFiles(m.gitRepo)
 	if err != nil {
-		return m, fmt.Errorf("failed to checkout or create branch %s: %w", m.Config.DeploymentRepository.PushBranch, err)
+		return m, fmt.Errorf("failed to read managed files of deployment repository: %w", err)
 	}
 
 	return m, nil
 }
 
+// cloneOptions returns the options limiting the clone of the deployment repository according to the configuration.
+// A single-branch clone fetches the pull branch and the push branch, a sparse clone checks out the directories of the environment and the resources.
+func (m *DeploymentRepoManager) cloneOptions() CloneOptions {
+	clone := m.Config.DeploymentRepository.Clone
+	if clone == nil {
+		return CloneOptions{}
+	}
+
+	options := CloneOptions{
+		Depth: clone.Depth,
+	}
+	if clone.SingleBranch {
+		for _, branch := range []string{m.Config.DeploymentRepository.PullBranch, m.Config.DeploymentRepository.PushBranch} {
+			if branch != "" {
+				options.Branches = append(options.Branches, branch)
+			}
+		}
+	}
+	if clone.Sparse {
+		options.SparseDirectories = []string{path.Join(EnvsDirectoryName, m.Config.Environment), ResourcesDirectoryName}
+	}
+	return options
+}
+
 // Cleanup removes temporary directories created during processing.
 func (m *DeploymentRepoManager) Cleanup() {
 	if m.workDir != "" {
@@ -217,13 +347,18 @@ func (m *DeploymentRepoManager) ApplyTemplates(ctx context.Context) error {
 	templateInput := make(map[string]interface{})
 
 	openMCPOperatorImageResources := m.openMCPOperatorCV.GetResourcesByType(ocmcli.OCIImageResourceType)
-	if len(openMCPOperatorImageResources) == 0 || openMCPOperatorImageResources[0].Access.ImageReference == nil {
+	if len(openMCPOperatorImageResources) == 0 {
 		return fmt.Errorf("no image resource found for openmcp-operator component version %s:%s", m.openMCPOperatorCV.Component.Name, m.openMCPOperatorCV.Component.Version)
 	}
 
-	imageName, imageTag, imageDigest, err := util.ParseImageVersionAndTag(*openMCPOperatorImageResources[0].Access.ImageReference)
+	openMCPOperatorImage, err := m.relocator.ResourceImage(&openMCPOperatorImageResources[0])
 	if err != nil {
-		return fmt.Errorf("failed to parse image reference %s: %w", *openMCPOperatorImageResources[0].Access.ImageReference, err)
+		return fmt.Errorf("no image reference found for openmcp-operator component version %s:%s: %w", m.openMCPOperatorCV.Component.Name, m.openMCPOperatorCV.Component.Version, err)
+	}
+
+	imageName, imageTag, imageDigest, err := util.ParseImageVersionAndTag(openMCPOperatorImage)
+	if err != nil {
+		return fmt.Errorf("failed to parse image reference %s: %w", openMCPOperatorImage, err)
 	}
 
 	if len(m.Config.ImagePullSecrets) > 0 {
@@ -265,7 +400,7 @@ func (m *DeploymentRepoManager) ApplyTemplates(ctx context.Context) error {
 			return fmt.Errorf("failed to read patches file %s: %w", m.PatchesFile, err)
 		}
 
-		patches, err := TemplateString(ctx, "userPatches", string(patchesRaw), templateInput, m.compGetter)
+		patches, err := TemplateString(ctx, "userPatches", string(patchesRaw), templateInput, m.relocator, m.compGetter)
 		if err != nil {
 			return fmt.Errorf("failed to template user patches file %s: %w", m.PatchesFile, err)
 		}
@@ -282,32 +417,32 @@ func (m *DeploymentRepoManager) ApplyTemplates(ctx context.Context) error {
 		templateInput["userKustomizationPatches"] = userKustomizationPatches["patches"]
 	}
 
-	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDSourceControllerResourceName, "sourceController")
+	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDSourceControllerResourceName, "sourceController", m.relocator)
 	if err != nil {
 		return fmt.Errorf("failed to apply fluxcd source controller template input: %w", err)
 	}
 
-	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDKustomizationControllerResourceName, "kustomizeController")
+	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDKustomizationControllerResourceName, "kustomizeController", m.relocator)
 	if err != nil {
 		return fmt.Errorf("failed to apply fluxcd kustomize controller template input: %w", err)
 	}
 
-	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDHelmControllerResourceName, "helmController")
+	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDHelmControllerResourceName, "helmController", m.relocator)
 	if err != nil {
 		return fmt.Errorf("failed to apply fluxcd helm controller template input: %w", err)
 	}
 
-	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDNotificationControllerName, "notificationController")
+	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDNotificationControllerName, "notificationController", m.relocator)
 	if err != nil {
 		return fmt.Errorf("failed to apply fluxcd helm controller template input: %w", err)
 	}
 
-	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDImageReflectorControllerName, "imageReflectorController")
+	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDImageReflectorControllerName, "imageReflectorController", m.relocator)
 	if err != nil {
 		return fmt.Errorf("failed to apply fluxcd image reflector controller template input: %w", err)
 	}
 
-	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDImageAutomationControllerName, "imageAutomationController")
+	err = applyFluxCDTemplateInput(templateInput, m.fluxcdCV, FluxCDImageAutomationControllerName, "imageAutomationController", m.relocator)
 	if err != nil {
 		return fmt.Errorf("failed to apply fluxcd image automation controller template input: %w", err)
 	}
@@ -319,22 +454,41 @@ func (m *DeploymentRepoManager) ApplyTemplates(ctx context.Context) error {
 		}
 	}
 
-	err = TemplateDir(ctx, m.templatesDir, templateInput, m.compGetter, m.gitRepo)
+	err = TemplateDir(ctx, m.templatesDir, templateInput, m.relocator, m.compGetter, m.gitRepo)
 	if err != nil {
 		return fmt.Errorf("failed to apply templates from directory %s: %w", m.templatesDir, err)
 	}
 
+	err = filepath.WalkDir(m.templatesDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relativePath, err := filepath.Rel(m.templatesDir, path)
+		if err != nil {
+			return err
+		}
+		m.addManagedFile(relativePath)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record templated files: %w", err)
+	}
+
 	return nil
 }
 
-func applyFluxCDTemplateInput(templateInput map[string]interface{}, fluxcdCV *ocmcli.ComponentVersion, fluxResource, key string) error {
+func applyFluxCDTemplateInput(templateInput map[string]interface{}, fluxcdCV *ocmcli.ComponentVersion, fluxResource, key string, relocator *relocation.Relocator) error {
 	fluxSourceControllerImageResource, err := fluxcdCV.GetResource(fluxResource)
 	if err != nil {
 		return fmt.Errorf("failed to get fluxcd resource %s: %w", fluxResource, err)
 	}
-	imageName, imageTag, imageDigest, err := util.ParseImageVersionAndTag(*fluxSourceControllerImageResource.Access.ImageReference)
+	image, err := relocator.ResourceImage(fluxSourceControllerImageResource)
 	if err != nil {
-		return fmt.Errorf("failed to parse image reference %s: %w", *fluxSourceControllerImageResource.Access.ImageReference, err)
+		return err
+	}
+	imageName, imageTag, imageDigest, err := util.ParseImageVersionAndTag(image)
+	if err != nil {
+		return fmt.Errorf("failed to parse image reference %s: %w", image, err)
 	}
 	templateInput["images"].(map[string]interface{})[key] = map[string]interface{}{
 		"version": imageTag,
@@ -352,11 +506,15 @@ func (m *DeploymentRepoManager) ApplyProviders(ctx context.Context) error {
 	logger.Infof("Templating providers: clusterProviders=%v, serviceProviders=%v, platformServices=%v, imagePullSecrets=%v",
 		m.Config.Providers.ClusterProviders, m.Config.Providers.ServiceProviders, m.Config.Providers.PlatformServices, m.Config.ImagePullSecrets)
 
-	err := TemplateProviders(ctx, m.Config.Providers.ClusterProviders, m.Config.Providers.ServiceProviders, m.Config.Providers.PlatformServices, m.Config.ImagePullSecrets, m.compGetter, m.gitRepo)
+	err := TemplateProviders(ctx, m.Config.Providers.ClusterProviders, m.Config.Providers.ServiceProviders, m.Config.Providers.PlatformServices, m.Config.ImagePullSecrets, m.relocator, m.compGetter, m.gitRepo)
 	if err != nil {
 		return fmt.Errorf("failed to template providers: %w", err)
 	}
 
+	for _, provider := range m.providerFiles() {
+		m.addManagedFile(filepath.Join(ResourcesDirectoryName, OpenMCPDirectoryName, provider))
+	}
+
 	return nil
 }
 
@@ -370,54 +528,67 @@ func (m *DeploymentRepoManager) ApplyCustomResourceDefinitions(ctx context.Conte
 		return nil
 	}
 
-	crdDirectory := filepath.Join(m.gitRepoDir, ResourcesDirectoryName, OpenMCPDirectoryName, CRDsDirectoryName)
+	crdRepoDirectory := filepath.Join(ResourcesDirectoryName, OpenMCPDirectoryName, CRDsDirectoryName)
+	crdDirectory := filepath.Join(m.gitRepoDir, crdRepoDirectory)
+
+	// CRDs downloaded by a previous run are removed, so that CRDs no longer shipped by the components are pruned.
+	// The remaining files in the directory are owned by the user or managed by another environment.
+	// The files are removed through the worktree, which cannot reach files outside the repository.
+	workTree, err := m.gitRepo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	for _, file := range m.previousManagedFiles[m.Config.Environment] {
+		if path.Dir(file) != filepath.ToSlash(crdRepoDirectory) || managedByOtherEnvironment(m.previousManagedFiles, m.Config.Environment, file) {
+			continue
+		}
+		err := workTree.Filesystem.Remove(file)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove previously downloaded CRD file %s: %w", file, err)
+		}
+	}
+	existingCRDs, err := os.ReadDir(crdDirectory)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read CRD download directory: %w", err)
+	}
 
 	logger.Infof("Applying Custom Resource Definitions to deployment repository")
 
-	err := m.applyCRDsForComponentVersion(ctx, m.openMCPOperatorCV, crdDirectory)
+	err = m.applyCRDsForComponentVersion(ctx, m.openMCPOperatorCV, crdDirectory)
 	if err != nil {
 		return fmt.Errorf("failed to apply CRDs for openmcp-operator component: %w", err)
 	}
 
 	for _, clusterProvider := range m.Config.Providers.ClusterProviders {
-		clusterProviderCVs, err := m.compGetter.GetReferencedComponentVersionsRecursive(ctx, m.compGetter.RootComponentVersion(), "cluster-provider-"+clusterProvider.Name)
+		clusterProviderCV, err := getProviderComponentVersion(ctx, m.compGetter, "cluster-provider-", clusterProvider)
 		if err != nil {
-			return fmt.Errorf("failed to get component version for cluster provider %s: %w", clusterProvider, err)
+			return fmt.Errorf("failed to get component version for cluster provider %s: %w", clusterProvider.Name, err)
 		}
-		if len(clusterProviderCVs) != 1 {
-			return fmt.Errorf("expected exactly one component version for cluster provider %s, got %d", clusterProvider, len(clusterProviderCVs))
-		}
-		err = m.applyCRDsForComponentVersion(ctx, &clusterProviderCVs[0], crdDirectory)
+		err = m.applyCRDsForComponentVersion(ctx, clusterProviderCV, crdDirectory)
 		if err != nil {
-			logger.Warnf("Failed to apply CRDs for cluster provider %s: %v", clusterProvider, err)
+			logger.Warnf("Failed to apply CRDs for cluster provider %s: %v", clusterProvider.Name, err)
 		}
 	}
 
 	for _, serviceProvider := range m.Config.Providers.ServiceProviders {
-		serviceProviderCVs, err := m.compGetter.GetReferencedComponentVersionsRecursive(ctx, m.compGetter.RootComponentVersion(), "service-provider-"+serviceProvider.Name)
+		serviceProviderCV, err := getProviderComponentVersion(ctx, m.compGetter, "service-provider-", serviceProvider)
 		if err != nil {
-			return fmt.Errorf("failed to get component version for service provider %s: %w", serviceProvider, err)
-		}
-		if len(serviceProviderCVs) != 1 {
-			return fmt.Errorf("expected exactly one component version for service provider %s, got %d", serviceProvider, len(serviceProviderCVs))
+			return fmt.Errorf("failed to get component version for service provider %s: %w", serviceProvider.Name, err)
 		}
-		err = m.applyCRDsForComponentVersion(ctx, &serviceProviderCVs[0], crdDirectory)
+		err = m.applyCRDsForComponentVersion(ctx, serviceProviderCV, crdDirectory)
 		if err != nil {
-			logger.Warnf("Failed to apply CRDs for service provider %s: %v", serviceProvider, err)
+			logger.Warnf("Failed to apply CRDs for service provider %s: %v", serviceProvider.Name, err)
 		}
 	}
 
 	for _, platformService := range m.Config.Providers.PlatformServices {
-		platformServiceCVs, err := m.compGetter.GetReferencedComponentVersionsRecursive(ctx, m.compGetter.RootComponentVersion(), "platform-service-"+platformService.Name)
+		platformServiceCV, err := getProviderComponentVersion(ctx, m.compGetter, "platform-service-", platformService)
 		if err != nil {
-			return fmt.Errorf("failed to get component version for platform service %s: %w", platformService, err)
-		}
-		if len(platformServiceCVs) != 1 {
-			return fmt.Errorf("expected exactly one component version for platform service %s, got %d", platformService, len(platformServiceCVs))
+			return fmt.Errorf("failed to get component version for platform service %s: %w", platformService.Name, err)
 		}
-		err = m.applyCRDsForComponentVersion(ctx, &platformServiceCVs[0], crdDirectory)
+		err = m.applyCRDsForComponentVersion(ctx, platformServiceCV, crdDirectory)
 		if err != nil {
-			logger.Warnf("Failed to apply CRDs for platform service %s: %v", platformService, err)
+			logger.Warnf("Failed to apply CRDs for platform service %s: %v", platformService.Name, err)
 		}
 	}
 
@@ -464,16 +635,17 @@ func (m *DeploymentRepoManager) ApplyCustomResourceDefinitions(ctx context.Conte
 				} else {
 					logger.Tracef("Added CRD file: %s", filePath)
 					m.crdFiles = append(m.crdFiles, filePath)
+					// CRDs left by another environment are managed by this environment as well, if it downloads them
+					userOwned := slices.ContainsFunc(existingCRDs, func(e os.DirEntry) bool { return e.Name() == fileName }) &&
+						!managedByOtherEnvironment(m.previousManagedFiles, m.Config.Environment, path.Join(filepath.ToSlash(crdRepoDirectory), fileName))
+					if !userOwned {
+						m.addManagedFile(filepath.Join(crdRepoDirectory, fileName))
+					}
 				}
 			}
 		}
 	}
 
-	workTree, err := m.gitRepo.Worktree()
-	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
-	}
-
 	_, err = workTree.Add(filepath.Join(ResourcesDirectoryName, OpenMCPDirectoryName, CRDsDirectoryName))
 	if err != nil {
 		return fmt.Errorf("failed to add CRD files: %w", err)
@@ -560,17 +732,7 @@ func (m *DeploymentRepoManager) UpdateResourcesKustomization() error {
 		files = append(files, crdFile)
 	}
 
-	for _, clusterProvider := range m.Config.Providers.ClusterProviders {
-		files = append(files, filepath.Join("cluster-providers", clusterProvider.Name+".yaml"))
-	}
-
-	for _, serviceProvider := range m.Config.Providers.ServiceProviders {
-		files = append(files, filepath.Join("service-providers", serviceProvider.Name+".yaml"))
-	}
-
-	for _, platformService := range m.Config.Providers.PlatformServices {
-		files = append(files, filepath.Join("platform-services", platformService.Name+".yaml"))
-	}
+	files = append(files, m.providerFiles()...)
 
 	for _, manifest := range m.extraManifests {
 		files = append(files, filepath.Join(ExtraManifestsDirectory, filepath.Base(manifest)))
@@ -623,10 +785,47 @@ func (m *DeploymentRepoManager) UpdateResourcesKustomization() error {
 	if _, err = workTree.Add(resourcesRootKustomizationPath); err != nil {
 		return fmt.Errorf("failed to add resources root kustomization to git index: %w", err)
 	}
+	m.addManagedFile(resourcesRootKustomizationPath)
 
 	return nil
 }
 
+// PruneManagedFiles removes the files rendered by a previous run, which have not been rendered by this run, from the deployment repository.
+// Files that have not been rendered by the bootstrapper are left alone. The rendered files are recorded in the ManagedFilesManifest of the environment.
+func (m *DeploymentRepoManager) PruneManagedFiles() error {
+	logger := log.GetLogger()
+
+	removed, err := PruneManagedFiles(m.gitRepo, m.Config.Environment, m.previousManagedFiles, m.managedFiles)
+	if err != nil {
+		return fmt.Errorf("failed to prune managed files: %w", err)
+	}
+
+	logger.Infof("Pruned %d file(s) no longer rendered by the bootstrapper", len(removed))
+	return nil
+}
+
+// providerFiles returns the paths of the provider manifests relative to the openmcp resources directory.
+func (m *DeploymentRepoManager) providerFiles() []string {
+	files := make([]string, 0)
+	for _, clusterProvider := range m.Config.Providers.ClusterProviders {
+		files = append(files, filepath.Join("cluster-providers", clusterProvider.Name+".yaml"))
+	}
+
+	for _, serviceProvider := range m.Config.Providers.ServiceProviders {
+		files = append(files, filepath.Join("service-providers", serviceProvider.Name+".yaml"))
+	}
+
+	for _, platformService := range m.Config.Providers.PlatformServices {
+		files = append(files, filepath.Join("platform-services", platformService.Name+".yaml"))
+	}
+	return files
+}
+
+// addManagedFile records a file rendered by this run, given relative to the repository root.
+func (m *DeploymentRepoManager) addManagedFile(path string) {
+	m.managedFiles = append(m.managedFiles, filepath.ToSlash(path))
+}
+
 // RunKustomize runs kustomize on the environment directory and returns the resulting manifests.
 func (m *DeploymentRepoManager) RunKustomize() ([]*unstructured.Unstructured, error) {
 	logger := log.GetLogger()
@@ -677,26 +876,116 @@ func (m *DeploymentRepoManager) RunKustomizeAndApply(ctx context.Context, manife
 }
 
 // CommitAndPushChanges commits all changes in the deployment repository and pushes them to the remote repository.
+// In pull request mode, the changes are pushed to a generated feature branch and a pull request against the pull branch is opened.
+// The commit message is a template, which is rendered with the release metadata of the applied component version and the changed files.
 // If there are no changes to commit, it does nothing.
-func (m *DeploymentRepoManager) CommitAndPushChanges(_ context.Context, commitMessage, commitAuthor, commitEmail string) error {
+func (m *DeploymentRepoManager) CommitAndPushChanges(ctx context.Context, commitMessage, commitAuthor, commitEmail string) error {
 	logger := log.GetLogger()
 
+	if m.Config.DeploymentRepository.PullRequest != nil {
+		logger.Info("Committing changes and opening a pull request against the deployment repository")
+		if m.TagTemplate != "" {
+			logger.Warn("Tags are not created in pull request mode, as the changes are not pushed to the pull branch")
+		}
+		if len(m.mirrors) > 0 {
+			logger.Warn("Mirrors are not pushed to in pull request mode, as the changes are not pushed to the pull branch")
+		}
+		return m.commitAndOpenPullRequest(ctx, commitMessage, commitAuthor, commitEmail)
+	}
+
 	logger.Info("Committing and pushing changes to deployment repository")
 
-	err := CommitChanges(m.gitRepo, commitMessage, commitAuthor, commitEmail)
+	base, err := m.gitRepo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD of deployment repository: %w", err)
+	}
+
+	message, templateInput, err := m.commitMessage(ctx, commitMessage)
+	if err != nil {
+		return err
+	}
+
+	err = CommitChanges(m.gitRepo, message, commitAuthor, commitEmail, m.gitConfig)
 	if err != nil {
 		return fmt.Errorf("failed to commit changes: %w", err)
 	}
 
-	err = PushRepo(m.gitRepo, m.Config.DeploymentRepository.PushBranch, m.gitConfig)
+	head, err := m.gitRepo.Head()
 	if err != nil {
-		return fmt.Errorf("failed to push changes to deployment repository: %w", err)
+		return fmt.Errorf("failed to get HEAD of deployment repository: %w", err)
+	}
+	if head.Hash() == base.Hash() {
+		logger.Info("No changes to push")
+	} else {
+		err = SafePushRepo(m.gitRepo, m.Config.DeploymentRepository.PushBranch, m.PushRetries, m.gitConfig)
+		if err != nil {
+			return fmt.Errorf("failed to push changes to deployment repository: %w", err)
+		}
+	}
+
+	// the tag is also created without changes, e.g. if the component version has been applied before without tag
+	var tag plumbing.ReferenceName
+	if m.TagTemplate != "" {
+		tag, err = m.tagRelease(ctx, templateInput, commitAuthor, commitEmail)
+		if err != nil {
+			return err
+		}
+	}
+
+	// mirrors are also pushed to without changes, so that a mirror that failed before catches up
+	if len(m.mirrors) > 0 {
+		logger.Infof("Pushing changes to %d mirrors of the deployment repository", len(m.mirrors))
+		bestEffort := m.Config.DeploymentRepository.MirrorPolicy == config.MirrorPolicyBestEffort
+		m.mirrorResults, err = PushMirrors(m.gitRepo, m.Config.DeploymentRepository.PushBranch, tag, m.mirrors, bestEffort)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// GitRepoDir returns the path to the cloned deployment repository.
+// MirrorResults returns the results of the push to the mirrors of the deployment repository by CommitAndPushChanges.
+func (m *DeploymentRepoManager) MirrorResults() []MirrorResult {
+	return m.mirrorResults
+}
+
+// mirrorRemotes returns the mirrors of the deployment repository with their git configuration.
+// Mirrors without git configuration use the git configuration of the deployment repository, see NewMirrorRemote.
+func (m *DeploymentRepoManager) mirrorRemotes() ([]MirrorRemote, error) {
+	mirrors := make([]MirrorRemote, 0, len(m.Config.DeploymentRepository.Mirrors))
+	for _, mirror := range m.Config.DeploymentRepository.Mirrors {
+		mirrorRemote, err := NewMirrorRemote(mirror.URL, mirror.GitConfig, m.Config.DeploymentRepository.RepoURL, m.GitConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, mirrorRemote)
+	}
+	return mirrors, nil
+}
+
+// resolvedComponentVersion returns the paragraph of the commit message describing the resolved component version,
+// if the component location contains a version constraint.
+func (m *DeploymentRepoManager) resolvedComponentVersion() string {
+	if m.compGetter == nil || m.compGetter.VersionConstraint() == "" {
+		return ""
+	}
+
+	root := m.compGetter.RootComponentVersion()
+	return fmt.Sprintf("Component version %s:%s resolved from version constraint %q",
+		root.Component.Name, root.Component.Version, m.compGetter.VersionConstraint())
+}
+
+// ComponentLocation returns the location of the applied component version.
+// A version constraint in the configured location is replaced by the resolved version.
+func (m *DeploymentRepoManager) ComponentLocation() string {
+	if m.compGetter == nil {
+		return m.Config.Component.OpenMCPComponentLocation
+	}
+	return m.compGetter.RootComponentLocation()
+}
+
+// GitRepoDir returns the path to the cloned deployment repository or the existing checkout set by WithRepoDir.
 func (m *DeploymentRepoManager) GitRepoDir() string {
 	return m.gitRepoDir
 }
@@ -0,0 +1,188 @@
+package deploymentrepo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const (
+	// ManagedFilesManifestName is the name of the manifest listing the files the bootstrapper manages for an environment.
+	// It is kept in the directory of the environment, see ManagedFilesManifest.
+	ManagedFilesManifestName = ".openmcp-bootstrapper-managed-files.yaml"
+
+	managedFilesManifestHeader = "# Files managed by the openmcp-bootstrapper for this environment. They are removed once the bootstrapper no longer renders them.\n" +
+		"# Do not edit this file, files not listed here are left alone.\n"
+)
+
+// ManagedFiles is the content of the ManagedFilesManifest of an environment.
+type ManagedFiles struct {
+	// Files are the paths of the managed files in the repository, sorted by path.
+	Files []string `json:"files"`
+}
+
+// ManagedFilesManifest returns the path of the managed files manifest of the environment in the repository.
+func ManagedFilesManifest(environment string) string {
+	return path.Join(EnvsDirectoryName, environment, ManagedFilesManifestName)
+}
+
+// ReadManagedFiles returns the files listed in the managed files manifests of the HEAD commit of the repository by environment.
+// The manifests are read from the commit, as the directories of the other environments are not part of a sparse checkout.
+// Entries that are not clean paths inside the repository are ignored. It returns no files if the repository has no commits.
+func ReadManagedFiles(repo *git.Repository) (map[string][]string, error) {
+	logger := log.GetLogger()
+
+	managedFiles := map[string][]string{}
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return managedFiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	tree, err := commitTree(repo, head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	envsTree, err := tree.Tree(EnvsDirectoryName)
+	if errors.Is(err, object.ErrDirectoryNotFound) {
+		return managedFiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s directory: %w", EnvsDirectoryName, err)
+	}
+
+	for _, entry := range envsTree.Entries {
+		if entry.Mode != filemode.Dir {
+			continue
+		}
+
+		file, err := envsTree.File(path.Join(entry.Name, ManagedFilesManifestName))
+		if errors.Is(err, object.ErrFileNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get managed files manifest of environment %s: %w", entry.Name, err)
+		}
+		data, err := file.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read managed files manifest of environment %s: %w", entry.Name, err)
+		}
+
+		manifest := &ManagedFiles{}
+		if err := yaml.Unmarshal([]byte(data), manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse managed files manifest of environment %s: %w", entry.Name, err)
+		}
+		for _, file := range manifest.Files {
+			if !isRepoPath(file) {
+				logger.Warnf("Ignoring managed file %q of environment %s, which is not a clean path inside the repository", file, entry.Name)
+				continue
+			}
+			managedFiles[entry.Name] = append(managedFiles[entry.Name], file)
+		}
+	}
+	return managedFiles, nil
+}
+
+// isRepoPath returns true if the path is a clean relative path inside the repository, e.g. "resources/openmcp/crds/crd.yaml".
+func isRepoPath(file string) bool {
+	return file != "" && file == path.Clean(file) && !path.IsAbs(file) && file != ".." && !strings.HasPrefix(file, "../")
+}
+
+// managedByOtherEnvironment returns true if the file is managed by an environment other than environment,
+// e.g. a provider manifest in the resources directory shared by the environments.
+func managedByOtherEnvironment(managedFiles map[string][]string, environment, file string) bool {
+	for env, files := range managedFiles {
+		if env != environment && slices.Contains(files, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// PruneManagedFiles removes the files previously managed for the environment, which are not managed anymore, from the repository and the git index.
+// Files that are still managed by another environment and files that have never been managed are left alone.
+// It writes the currently managed files into the ManagedFilesManifest of the environment and stages it. It returns the removed files.
+func PruneManagedFiles(repo *git.Repository, environment string, managedFiles map[string][]string, current []string) ([]string, error) {
+	logger := log.GetLogger()
+
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	current = slices.Clone(current)
+	slices.Sort(current)
+	current = slices.Compact(current)
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git index: %w", err)
+	}
+
+	removed := make([]string, 0)
+	for _, file := range managedFiles[environment] {
+		if _, found := slices.BinarySearch(current, file); found {
+			continue
+		}
+		if managedByOtherEnvironment(managedFiles, environment, file) {
+			logger.Debugf("Keeping file %s, which is managed by another environment", file)
+			continue
+		}
+
+		if _, err := idx.Entry(file); err == nil {
+			logger.Infof("Removing file %s, which is no longer rendered", file)
+			if _, err := workTree.Remove(file); err != nil {
+				return nil, fmt.Errorf("failed to remove stale managed file %s: %w", file, err)
+			}
+		} else if _, err := workTree.Filesystem.Lstat(file); err == nil {
+			logger.Infof("Removing untracked file %s, which is no longer rendered", file)
+			if err := workTree.Filesystem.Remove(file); err != nil {
+				return nil, fmt.Errorf("failed to remove stale managed file %s: %w", file, err)
+			}
+		} else {
+			logger.Debugf("Stale managed file %s has already been removed", file)
+			continue
+		}
+		removed = append(removed, file)
+	}
+
+	data, err := yaml.Marshal(&ManagedFiles{Files: current})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal managed files manifest: %w", err)
+	}
+
+	manifestPath := ManagedFilesManifest(environment)
+	if err := workTree.Filesystem.MkdirAll(path.Dir(manifestPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory of managed files manifest: %w", err)
+	}
+	manifest, err := workTree.Filesystem.OpenFile(manifestPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open managed files manifest: %w", err)
+	}
+	_, err = manifest.Write(append([]byte(managedFilesManifestHeader), data...))
+	if closeErr := manifest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write managed files manifest: %w", err)
+	}
+
+	if _, err := workTree.Add(manifestPath); err != nil {
+		return nil, fmt.Errorf("failed to add managed files manifest to git index: %w", err)
+	}
+
+	return removed, nil
+}
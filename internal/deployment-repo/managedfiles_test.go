@@ -0,0 +1,93 @@
+package deploymentrepo_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+
+	deploymentrepo "github.com/openmcp-project/bootstrapper/internal/deployment-repo"
+	testutils "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+func TestPruneManagedFiles(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	assert.NoError(t, err)
+	workTree, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	managed, err := deploymentrepo.ReadManagedFiles(repo)
+	assert.NoError(t, err)
+	assert.Empty(t, managed)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repoDir, "resources"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(repoDir, "envs", "dev"), 0o755))
+	for _, file := range []string{"envs/dev/a.yaml", "resources/b.yaml", "resources/shared.yaml", "resources/user.yaml"} {
+		testutils.WriteToFile(t, filepath.Join(repoDir, file), "initial")
+		testutils.AddFileToWorkTree(t, workTree, file)
+	}
+	removed, err := deploymentrepo.PruneManagedFiles(repo, "dev", managed, []string{"resources/b.yaml", "envs/dev/a.yaml", "resources/gone.yaml", "resources/shared.yaml"})
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+	removed, err = deploymentrepo.PruneManagedFiles(repo, "prod", managed, []string{"resources/shared.yaml"})
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+	testutils.WorkTreeCommit(t, workTree, "Initial commit")
+
+	previous, err := deploymentrepo.ReadManagedFiles(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"dev":  {"envs/dev/a.yaml", "resources/b.yaml", "resources/gone.yaml", "resources/shared.yaml"},
+		"prod": {"resources/shared.yaml"},
+	}, previous)
+
+	// the files of the dev environment are left alone by a run of the prod environment
+	removed, err = deploymentrepo.PruneManagedFiles(repo, "prod", previous, []string{})
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+	assert.FileExists(t, filepath.Join(repoDir, "resources", "shared.yaml"))
+	assert.NoError(t, workTree.Reset(&git.ResetOptions{Mode: git.HardReset}))
+
+	testutils.WriteToFile(t, filepath.Join(repoDir, "c.yaml"), "rendered")
+	testutils.AddFileToWorkTree(t, workTree, "c.yaml")
+
+	removed, err = deploymentrepo.PruneManagedFiles(repo, "dev", previous, []string{"c.yaml", "envs/dev/a.yaml"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"resources/b.yaml"}, removed)
+
+	assert.NoFileExists(t, filepath.Join(repoDir, "resources", "b.yaml"))
+	assert.FileExists(t, filepath.Join(repoDir, "resources", "user.yaml"))
+	assert.FileExists(t, filepath.Join(repoDir, "resources", "shared.yaml"))
+	assert.FileExists(t, filepath.Join(repoDir, "envs", "dev", "a.yaml"))
+
+	status, err := workTree.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, git.Deleted, status.File("resources/b.yaml").Staging)
+	assert.Equal(t, git.Modified, status.File(deploymentrepo.ManagedFilesManifest("dev")).Staging)
+
+	testutils.WorkTreeCommit(t, workTree, "Prune managed files")
+	current, err := deploymentrepo.ReadManagedFiles(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c.yaml", "envs/dev/a.yaml"}, current["dev"])
+}
+
+func TestReadManagedFilesIgnoresPathsOutsideTheRepository(t *testing.T) {
+	repoDir := t.TempDir()
+	repo, err := git.PlainInit(repoDir, false)
+	assert.NoError(t, err)
+	workTree, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repoDir, "envs", "dev"), 0o755))
+	testutils.WriteToFile(t, filepath.Join(repoDir, deploymentrepo.ManagedFilesManifest("dev")),
+		"files:\n- resources/openmcp/crds/crd.yaml\n- resources/openmcp/crds/../../../../outside.yaml\n- /etc/passwd\n- ../outside.yaml\n")
+	testutils.AddFileToWorkTree(t, workTree, deploymentrepo.ManagedFilesManifest("dev"))
+	testutils.WorkTreeCommit(t, workTree, "Initial commit")
+
+	managed, err := deploymentrepo.ReadManagedFiles(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"dev": {"resources/openmcp/crds/crd.yaml"}}, managed)
+}
@@ -0,0 +1,183 @@
+package deploymentrepo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const (
+	// MirrorPushed is the status of a mirror the references have been pushed to.
+	MirrorPushed = "pushed"
+	// MirrorUpToDate is the status of a mirror that already contained the references.
+	MirrorUpToDate = "up-to-date"
+	// MirrorFailed is the status of a mirror the references could not be pushed to.
+	MirrorFailed = "failed"
+	// MirrorSkipped is the status of a mirror that has not been pushed to, because the push to a previous mirror failed.
+	MirrorSkipped = "skipped"
+
+	// mirrorRemoteName is the name of the in-memory remote used to push to a mirror, it is not stored in the repository configuration.
+	mirrorRemoteName = "mirror"
+)
+
+// MirrorRemote is a mirror of the deployment repository.
+type MirrorRemote struct {
+	// URL is the URL of the mirror repository.
+	URL string
+	// GitConfig contains the credentials of the mirror.
+	GitConfig *gitconfig.Config
+}
+
+// MirrorResult is the result of the push to a mirror.
+type MirrorResult struct {
+	// URL is the URL of the mirror repository.
+	URL string
+	// Status is one of MirrorPushed, MirrorUpToDate, MirrorFailed and MirrorSkipped.
+	Status string
+	// Err is the error of a failed push.
+	Err error
+}
+
+// String returns the result for the run summary, e.g. "https://mirror.example.com/deployment.git: pushed".
+func (r MirrorResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", r.URL, r.Status, r.Err)
+	}
+	return fmt.Sprintf("%s: %s", r.URL, r.Status)
+}
+
+// NewMirrorRemote returns the mirror at mirrorURL with the git configuration at gitConfigPath.
+// Without gitConfigPath, the git configuration of the deployment repository at repoURL is read from repoGitConfigPath,
+// and its credentials are resolved for the mirror, e.g. from the entry of the mirror host in a netrc file.
+// Credentials bound to a host, like a password or a GitHub App, are only reused for a mirror on the same host.
+func NewMirrorRemote(mirrorURL, gitConfigPath, repoURL, repoGitConfigPath string) (MirrorRemote, error) {
+	if gitConfigPath == "" && repoGitConfigPath == "" {
+		// the deployment repository is used without credentials, e.g. an existing checkout
+		return MirrorRemote{URL: mirrorURL, GitConfig: &gitconfig.Config{}}, nil
+	}
+
+	path := gitConfigPath
+	if path == "" {
+		path = repoGitConfigPath
+	}
+	gitConfig, err := gitconfig.ParseConfig(path)
+	if err != nil {
+		return MirrorRemote{}, fmt.Errorf("failed to parse git config of mirror %s: %w", mirrorURL, err)
+	}
+	if err = gitConfig.Validate(); err != nil {
+		return MirrorRemote{}, fmt.Errorf("invalid git config of mirror %s: %w", mirrorURL, err)
+	}
+
+	if gitConfigPath == "" && gitConfig.Authentication.HostBound() {
+		same, err := sameHost(repoURL, mirrorURL)
+		if err != nil {
+			return MirrorRemote{}, err
+		}
+		if !same {
+			return MirrorRemote{}, fmt.Errorf("mirror %s is on a different host than the deployment repository, "+
+				"its credentials must be configured with the gitConfig of the mirror", mirrorURL)
+		}
+	}
+
+	if err = gitConfig.ResolveCredentials(mirrorURL); err != nil {
+		return MirrorRemote{}, fmt.Errorf("failed to resolve git credentials of mirror %s: %w", mirrorURL, err)
+	}
+	return MirrorRemote{URL: mirrorURL, GitConfig: gitConfig}, nil
+}
+
+// sameHost returns whether the repositories at the URLs a and b are served by the same host and port.
+func sameHost(a, b string) (bool, error) {
+	endpointA, err := transport.NewEndpoint(a)
+	if err != nil {
+		return false, fmt.Errorf("invalid repository URL %s: %w", a, err)
+	}
+	endpointB, err := transport.NewEndpoint(b)
+	if err != nil {
+		return false, fmt.Errorf("invalid repository URL %s: %w", b, err)
+	}
+	return strings.EqualFold(endpointA.Host, endpointB.Host) && endpointA.Port == endpointB.Port, nil
+}
+
+// PushMirrors pushes HEAD to the branch and the optional tag to the mirrors in order and returns the result of each mirror.
+// Without bestEffort, it stops at the first mirror that cannot be pushed to and returns its error, the remaining mirrors are skipped.
+// With bestEffort, all mirrors are pushed to and failures are only reported in the results.
+// A shallow clone is never pushed to the mirrors, as a mirror that is behind can only be fast-forwarded with the history
+// between its head and HEAD.
+func PushMirrors(repo *git.Repository, branch string, tag plumbing.ReferenceName, mirrors []MirrorRemote, bestEffort bool) ([]MirrorResult, error) {
+	logger := log.GetLogger()
+
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shallow commits of the repository: %w", err)
+	}
+	if len(shallow) > 0 {
+		results := make([]MirrorResult, 0, len(mirrors))
+		for _, mirror := range mirrors {
+			results = append(results, MirrorResult{URL: mirror.URL, Status: MirrorSkipped})
+		}
+		return results, fmt.Errorf("cannot push a shallow clone of the repository to mirrors, the full history is required")
+	}
+
+	refSpecs := []config.RefSpec{
+		config.RefSpec(plumbing.HEAD + ":" + plumbing.NewBranchReferenceName(branch)),
+	}
+	if tag != "" {
+		refSpecs = append(refSpecs, config.RefSpec(tag+":"+tag))
+	}
+
+	results := make([]MirrorResult, 0, len(mirrors))
+	for i, mirror := range mirrors {
+		result := MirrorResult{URL: mirror.URL, Status: MirrorPushed}
+
+		err := pushMirror(repo, refSpecs, mirror)
+		switch {
+		case errors.Is(err, git.NoErrAlreadyUpToDate):
+			result.Status = MirrorUpToDate
+			logger.Infof("Mirror %s is up-to-date", mirror.URL)
+		case err != nil:
+			result.Status = MirrorFailed
+			result.Err = err
+			logger.Warnf("Failed to push to mirror %s: %v", mirror.URL, err)
+		default:
+			logger.Infof("Pushed to mirror %s", mirror.URL)
+		}
+		results = append(results, result)
+
+		if result.Status == MirrorFailed && !bestEffort {
+			for _, skipped := range mirrors[i+1:] {
+				results = append(results, MirrorResult{URL: skipped.URL, Status: MirrorSkipped})
+			}
+			return results, fmt.Errorf("failed to push to mirror %s: %w", mirror.URL, err)
+		}
+	}
+
+	return results, nil
+}
+
+// pushMirror pushes the references to the mirror through an in-memory remote, so that the repository configuration is not changed.
+func pushMirror(repo *git.Repository, refSpecs []config.RefSpec, mirror MirrorRemote) error {
+	remote := git.NewRemote(repo.Storer, &config.RemoteConfig{
+		Name: mirrorRemoteName,
+		URLs: []string{mirror.URL},
+	})
+
+	pushOptions := &git.PushOptions{
+		RemoteName: mirrorRemoteName,
+		RemoteURL:  mirror.URL,
+		RefSpecs:   refSpecs,
+		Progress:   gitProgressWriter{},
+	}
+	if err := mirror.GitConfig.ConfigurePushOptions(pushOptions); err != nil {
+		return fmt.Errorf("failed to configure push options: %w", err)
+	}
+
+	return remote.Push(pushOptions)
+}
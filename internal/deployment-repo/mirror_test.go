@@ -0,0 +1,263 @@
+package deploymentrepo_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+
+	deploymentrepo "github.com/openmcp-project/bootstrapper/internal/deployment-repo"
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	testutils "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+func TestPushMirrors(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		bestEffort       bool
+		expectedStatuses []string
+		expectError      bool
+	}{
+		{
+			desc:             "should skip the remaining mirrors after a failure with fail-fast",
+			expectedStatuses: []string{deploymentrepo.MirrorPushed, deploymentrepo.MirrorFailed, deploymentrepo.MirrorSkipped},
+			expectError:      true,
+		},
+		{
+			desc:             "should push to all mirrors with best-effort",
+			bestEffort:       true,
+			expectedStatuses: []string{deploymentrepo.MirrorPushed, deploymentrepo.MirrorFailed, deploymentrepo.MirrorPushed},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			originDir := t.TempDir()
+			targetDir := t.TempDir()
+			mirrorDirs := []string{t.TempDir(), t.TempDir()}
+
+			_, err := git.PlainInit(originDir, true)
+			assert.NoError(t, err)
+			mirrorRepos := make([]*git.Repository, 0, len(mirrorDirs))
+			for _, mirrorDir := range mirrorDirs {
+				mirrorRepo, err := git.PlainInit(mirrorDir, true)
+				assert.NoError(t, err)
+				mirrorRepos = append(mirrorRepos, mirrorRepo)
+			}
+
+			gitConfig := &gitconfig.Config{}
+			repo, err := deploymentrepo.CloneRepo(originDir, targetDir, gitConfig)
+			assert.NoError(t, err)
+			assert.NoError(t, deploymentrepo.CheckoutAndCreateBranchIfNotExists(repo, testBranchName, gitConfig))
+
+			repoWorkTree, err := repo.Worktree()
+			assert.NoError(t, err)
+			testutils.WriteToFile(t, filepath.Join(targetDir, "test.txt"), "This is a test file.")
+			testutils.AddFileToWorkTree(t, repoWorkTree, "test.txt")
+			assert.NoError(t, deploymentrepo.CommitChanges(repo, "Add test.txt", "Test User", "noreply@test", gitConfig))
+			assert.NoError(t, deploymentrepo.PushRepo(repo, testBranchName, gitConfig))
+
+			head, err := repo.Head()
+			assert.NoError(t, err)
+			tagger := object.Signature{Name: "Test User", Email: "noreply@test", When: time.Now()}
+			assert.NoError(t, deploymentrepo.TagCommit(repo, "dev/openmcp-v0.0.1", "openMCP environment dev", head.Hash(), tagger, gitConfig))
+
+			mirrors := []deploymentrepo.MirrorRemote{
+				{URL: mirrorDirs[0], GitConfig: gitConfig},
+				{URL: filepath.Join(t.TempDir(), "missing"), GitConfig: gitConfig},
+				{URL: mirrorDirs[1], GitConfig: gitConfig},
+			}
+			tag := plumbing.NewTagReferenceName("dev/openmcp-v0.0.1")
+
+			results, err := deploymentrepo.PushMirrors(repo, testBranchName, tag, mirrors, tc.bestEffort)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			statuses := make([]string, 0, len(results))
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+			}
+			assert.Equal(t, tc.expectedStatuses, statuses)
+			assert.Error(t, results[1].Err)
+
+			for i, mirrorRepo := range mirrorRepos {
+				if results[i*2].Status != deploymentrepo.MirrorPushed {
+					continue
+				}
+				branch, err := mirrorRepo.Reference(plumbing.NewBranchReferenceName(testBranchName), true)
+				if assert.NoError(t, err) {
+					assert.Equal(t, head.Hash(), branch.Hash())
+				}
+				_, err = mirrorRepo.Reference(tag, false)
+				assert.NoError(t, err)
+			}
+
+			// a mirror that already contains the commit is up-to-date
+			results, _ = deploymentrepo.PushMirrors(repo, testBranchName, tag, mirrors[:1], tc.bestEffort)
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, deploymentrepo.MirrorUpToDate, results[0].Status)
+			}
+		})
+	}
+}
+
+func TestPushMirrorsBehind(t *testing.T) {
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+	originWorkTree, err := origin.Worktree()
+	assert.NoError(t, err)
+	var mirrorHead plumbing.Hash
+	for i := range 3 {
+		file := fmt.Sprintf("file-%d.txt", i)
+		testutils.WriteToFile(t, filepath.Join(originDir, file), file)
+		testutils.AddFileToWorkTree(t, originWorkTree, file)
+		testutils.WorkTreeCommit(t, originWorkTree, fmt.Sprintf("Commit %d", i))
+		if i == 0 {
+			head, err := origin.Head()
+			assert.NoError(t, err)
+			mirrorHead = head.Hash()
+		}
+	}
+	head, err := origin.Head()
+	assert.NoError(t, err)
+	branch := head.Name().Short()
+
+	// shallow clones require a bare repository
+	bareDir := t.TempDir()
+	_, err = git.PlainClone(bareDir, true, &git.CloneOptions{URL: originDir, Mirror: true})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc             string
+		options          deploymentrepo.CloneOptions
+		expectedStatuses []string
+		expectedHead     plumbing.Hash
+		expectError      bool
+	}{
+		{
+			desc:             "should fast-forward a mirror that is behind",
+			expectedStatuses: []string{deploymentrepo.MirrorPushed},
+			expectedHead:     head.Hash(),
+		},
+		{
+			desc:             "should not push a shallow clone to a mirror that is behind",
+			options:          deploymentrepo.CloneOptions{Depth: 1},
+			expectedStatuses: []string{deploymentrepo.MirrorSkipped},
+			expectedHead:     mirrorHead,
+			expectError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			mirrorDir := t.TempDir()
+			mirrorRepo, err := git.PlainInit(mirrorDir, true)
+			assert.NoError(t, err)
+			mirrorRemote := git.NewRemote(origin.Storer, &config.RemoteConfig{Name: "mirror", URLs: []string{mirrorDir}})
+			assert.NoError(t, mirrorRemote.Push(&git.PushOptions{
+				RemoteName: "mirror",
+				RefSpecs:   []config.RefSpec{config.RefSpec(mirrorHead.String() + ":" + plumbing.NewBranchReferenceName(branch).String())},
+			}))
+
+			gitConfig := &gitconfig.Config{}
+			repo, err := deploymentrepo.CloneRepoWithOptions(bareDir, t.TempDir(), tc.options, gitConfig)
+			assert.NoError(t, err)
+			assert.NoError(t, deploymentrepo.CheckoutAndCreateBranchIfNotExists(repo, branch, gitConfig))
+
+			mirrors := []deploymentrepo.MirrorRemote{{URL: mirrorDir, GitConfig: gitConfig}}
+			results, err := deploymentrepo.PushMirrors(repo, branch, "", mirrors, false)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			statuses := make([]string, 0, len(results))
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+			}
+			assert.Equal(t, tc.expectedStatuses, statuses)
+
+			mirrorBranch, err := mirrorRepo.Reference(plumbing.NewBranchReferenceName(branch), true)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expectedHead, mirrorBranch.Hash())
+			}
+		})
+	}
+}
+
+func TestNewMirrorRemote(t *testing.T) {
+	dir := t.TempDir()
+	netrcFile := filepath.Join(dir, "netrc")
+	testutils.WriteToFile(t, netrcFile, "machine github.com login repo-user password repo-password\nmachine mirror.example.com login mirror-user password mirror-password\n")
+	netrcConfig := filepath.Join(dir, "git-config-netrc.yaml")
+	testutils.WriteToFile(t, netrcConfig, "auth:\n  netrc:\n    file: "+netrcFile+"\n")
+	basicConfig := filepath.Join(dir, "git-config-basic.yaml")
+	testutils.WriteToFile(t, basicConfig, "auth:\n  basic:\n    username: repo-user\n    password: repo-password\n")
+	mirrorConfig := filepath.Join(dir, "git-config-mirror.yaml")
+	testutils.WriteToFile(t, mirrorConfig, "auth:\n  basic:\n    username: other-user\n    password: other-password\n")
+
+	const repoURL = "https://github.com/openmcp-project/deployment.git"
+
+	testCases := []struct {
+		desc              string
+		mirrorURL         string
+		gitConfigPath     string
+		repoGitConfigPath string
+		expectedAuth      *gitconfig.BasicAuth
+		expectError       bool
+	}{
+		{
+			desc:              "should resolve the netrc credentials of the deployment repository for the mirror host",
+			mirrorURL:         "https://mirror.example.com/deployment.git",
+			repoGitConfigPath: netrcConfig,
+			expectedAuth:      &gitconfig.BasicAuth{Username: "mirror-user", Password: "mirror-password"},
+		},
+		{
+			desc:              "should reuse the basic authentication of the deployment repository on the same host",
+			mirrorURL:         "https://github.com/openmcp-project/deployment-mirror.git",
+			repoGitConfigPath: basicConfig,
+			expectedAuth:      &gitconfig.BasicAuth{Username: "repo-user", Password: "repo-password"},
+		},
+		{
+			desc:              "should not send the basic authentication of the deployment repository to a different host",
+			mirrorURL:         "https://mirror.example.com/deployment.git",
+			repoGitConfigPath: basicConfig,
+			expectError:       true,
+		},
+		{
+			desc:              "should use the git config of the mirror",
+			mirrorURL:         "https://mirror.example.com/deployment.git",
+			gitConfigPath:     mirrorConfig,
+			repoGitConfigPath: basicConfig,
+			expectedAuth:      &gitconfig.BasicAuth{Username: "other-user", Password: "other-password"},
+		},
+		{
+			desc:      "should use no credentials without git config",
+			mirrorURL: "https://mirror.example.com/deployment.git",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			mirror, err := deploymentrepo.NewMirrorRemote(tc.mirrorURL, tc.gitConfigPath, repoURL, tc.repoGitConfigPath)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.mirrorURL, mirror.URL)
+			if assert.NotNil(t, mirror.GitConfig) {
+				assert.Equal(t, tc.expectedAuth, mirror.GitConfig.Authentication.BasicAuth)
+			}
+		})
+	}
+}
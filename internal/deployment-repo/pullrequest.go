@@ -0,0 +1,250 @@
+package deploymentrepo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	"github.com/openmcp-project/bootstrapper/internal/forge"
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+// PullRequestChanges describes what a pull request of the deployment repository changes.
+type PullRequestChanges struct {
+	// Environment is the environment of the deployment.
+	Environment string
+	// ComponentLocation is the location of the applied component version.
+	ComponentLocation string
+	// VersionConstraint is the version constraint the component version has been resolved from, if any.
+	VersionConstraint string
+	// Providers are the applied providers.
+	Providers config.Providers
+	// Files are the changed files.
+	Files []FileChange
+}
+
+// Description returns the markdown description of the pull request.
+func (c *PullRequestChanges) Description() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Updates the openMCP deployment of environment `%s` to component version `%s`.\n", c.Environment, c.ComponentLocation)
+	if c.VersionConstraint != "" {
+		fmt.Fprintf(&sb, "The component version has been resolved from the version constraint `%s`.\n", c.VersionConstraint)
+	}
+
+	sb.WriteString("\n### Providers\n\n")
+	numProviders := 0
+	for _, providers := range []struct {
+		kind      string
+		providers []config.Provider
+	}{
+		{kind: "Cluster provider", providers: c.Providers.ClusterProviders},
+		{kind: "Service provider", providers: c.Providers.ServiceProviders},
+		{kind: "Platform service", providers: c.Providers.PlatformServices},
+	} {
+		for _, provider := range providers.providers {
+			fmt.Fprintf(&sb, "- %s `%s`%s\n", providers.kind, provider.Name, providerOverrides(provider))
+			numProviders++
+		}
+	}
+	if numProviders == 0 {
+		sb.WriteString("No providers.\n")
+	}
+
+	sb.WriteString("\n### Changed files\n\n")
+	for _, file := range c.Files {
+		fmt.Fprintf(&sb, "- %s `%s`\n", file.Action, file.Path)
+	}
+	if len(c.Files) == 0 {
+		sb.WriteString("No changed files.\n")
+	}
+
+	return sb.String()
+}
+
+// providerOverrides describes the overrides of a provider, e.g. " (version v0.1.1)".
+func providerOverrides(provider config.Provider) string {
+	overrides := make([]string, 0, 3)
+	if provider.ComponentName != "" {
+		overrides = append(overrides, "component "+provider.ComponentName)
+	}
+	if provider.Version != "" {
+		overrides = append(overrides, "version "+provider.Version)
+	}
+	if provider.Image != "" {
+		overrides = append(overrides, "image "+provider.Image)
+	}
+	if len(overrides) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(overrides, ", ") + ")"
+}
+
+// PushPullRequest pushes the HEAD of the repository to the source branch of the pull request and opens the pull request through the forge.
+// It returns the URL of the pull request.
+func PushPullRequest(ctx context.Context, repo *git.Repository, gitConfig *gitconfig.Config, f forge.Forge, pr forge.PullRequest) (string, error) {
+	logger := log.GetLogger()
+
+	logger.Infof("Pushing changes to branch %s", pr.SourceBranch)
+	if err := PushRepo(repo, pr.SourceBranch, gitConfig); err != nil {
+		return "", err
+	}
+
+	logger.Infof("Opening pull request of branch %s against %s", pr.SourceBranch, pr.TargetBranch)
+	prURL, err := f.CreatePullRequest(ctx, pr)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Infof("Opened pull request %s", prURL)
+	return prURL, nil
+}
+
+// baseBranch returns the branch the changes are based on.
+// In pull request mode, it is the pull branch the pull request is opened against, otherwise the push branch.
+func (m *DeploymentRepoManager) baseBranch() string {
+	if m.Config.DeploymentRepository.PullRequest != nil {
+		return m.Config.DeploymentRepository.PullBranch
+	}
+	return m.Config.DeploymentRepository.PushBranch
+}
+
+// commitAndOpenPullRequest commits all changes, pushes them to a generated feature branch
+// and opens a pull request against the pull branch. If there are no changes to commit, it does nothing.
+func (m *DeploymentRepoManager) commitAndOpenPullRequest(ctx context.Context, commitMessage, commitAuthor, commitEmail string) error {
+	logger := log.GetLogger()
+	prConfig := m.Config.DeploymentRepository.PullRequest
+
+	base, err := m.gitRepo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD of deployment repository: %w", err)
+	}
+
+	message, _, err := m.commitMessage(ctx, commitMessage)
+	if err != nil {
+		return err
+	}
+
+	err = CommitChanges(m.gitRepo, message, commitAuthor, commitEmail, m.gitConfig)
+	if err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	head, err := m.gitRepo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD of deployment repository: %w", err)
+	}
+	if head.Hash() == base.Hash() {
+		logger.Info("No changes, skipping pull request")
+		return nil
+	}
+
+	files, err := ChangedFiles(m.gitRepo, base.Hash(), head.Hash())
+	if err != nil {
+		return err
+	}
+
+	f, err := m.newForge()
+	if err != nil {
+		return err
+	}
+
+	root := m.compGetter.RootComponentVersion()
+	changes := &PullRequestChanges{
+		Environment:       m.Config.Environment,
+		ComponentLocation: m.ComponentLocation(),
+		VersionConstraint: m.compGetter.VersionConstraint(),
+		Providers:         m.Config.Providers,
+		Files:             files,
+	}
+
+	_, err = PushPullRequest(ctx, m.gitRepo, m.gitConfig, f, forge.PullRequest{
+		Title:        fmt.Sprintf("Update openMCP environment %s to %s", m.Config.Environment, root.Component.Version),
+		Description:  changes.Description(),
+		SourceBranch: pullRequestBranch(prConfig.BranchPrefix, m.Config.Environment, root.Component.Version, head.Hash()),
+		TargetBranch: m.Config.DeploymentRepository.PullBranch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	return nil
+}
+
+// pullRequestBranch returns the name of the feature branch of a pull request, e.g. "openmcp-bootstrapper/dev-v0.0.2-1a2b3c4d".
+// The abbreviated commit hash makes the name unique for each change.
+func pullRequestBranch(prefix, environment, version string, commit plumbing.Hash) string {
+	return fmt.Sprintf("%s%s-%s-%s", prefix, environment, version, commit.String()[:8])
+}
+
+// newForge creates the forge of the pull request configuration.
+// The API URL and repository default to those derived from the repository URL.
+func (m *DeploymentRepoManager) newForge() (forge.Forge, error) {
+	prConfig := m.Config.DeploymentRepository.PullRequest
+
+	apiURL := prConfig.APIURL
+	if apiURL == "" {
+		var err error
+		apiURL, err = forge.DefaultAPIURL(prConfig.Forge, m.Config.DeploymentRepository.RepoURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	repository := prConfig.Repository
+	if repository == "" {
+		var err error
+		repository, err = forge.RepositoryFromURL(m.Config.DeploymentRepository.RepoURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := m.forgeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	caBundle, err := m.gitConfig.DecodeTLSCACert()
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := forge.NewHTTPClient(caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return forge.New(prConfig.Forge, apiURL, repository, token, httpClient)
+}
+
+// forgeToken returns the token of the forge API from the environment variable of the pull request configuration,
+// or else the password, bearer token or GitHub App installation token of the git configuration.
+func (m *DeploymentRepoManager) forgeToken() (string, error) {
+	prConfig := m.Config.DeploymentRepository.PullRequest
+	if prConfig.TokenFromEnv != "" {
+		token := os.Getenv(prConfig.TokenFromEnv)
+		if token == "" {
+			return "", fmt.Errorf("environment variable %s containing the forge token is not set", prConfig.TokenFromEnv)
+		}
+		return token, nil
+	}
+
+	if auth := m.gitConfig.Authentication; auth.BasicAuth != nil && auth.BasicAuth.Password != "" {
+		return auth.BasicAuth.Password, nil
+	} else if auth.BearerToken != nil && auth.BearerToken.Token != "" {
+		return auth.BearerToken.Token, nil
+	} else if auth.GitHubApp != nil {
+		token, err := m.gitConfig.GitHubAppInstallationToken()
+		if err != nil {
+			return "", fmt.Errorf("failed to get forge token: %w", err)
+		}
+		return token, nil
+	}
+	return "", fmt.Errorf("no forge token: set repository.pullRequest.tokenFromEnv or use basic, bearer token or GitHub App authentication in the git configuration")
+}
@@ -0,0 +1,117 @@
+package deploymentrepo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	deploymentrepo "github.com/openmcp-project/bootstrapper/internal/deployment-repo"
+	"github.com/openmcp-project/bootstrapper/internal/forge"
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	testutils "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+func TestPushPullRequest(t *testing.T) {
+	originDir := t.TempDir()
+	targetDir := t.TempDir()
+
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+	originWorkTree, err := origin.Worktree()
+	assert.NoError(t, err)
+	testutils.WriteToFile(t, filepath.Join(originDir, "dummy.txt"), "This is a dummy file.")
+	testutils.WriteToFile(t, filepath.Join(originDir, "obsolete.txt"), "This file is removed.")
+	testutils.AddFileToWorkTree(t, originWorkTree, "dummy.txt")
+	testutils.AddFileToWorkTree(t, originWorkTree, "obsolete.txt")
+	testutils.WorkTreeCommit(t, originWorkTree, "Initial commit")
+
+	gitConfig := &gitconfig.Config{}
+	repo, err := deploymentrepo.CloneRepo(originDir, targetDir, gitConfig)
+	assert.NoError(t, err)
+	assert.NoError(t, deploymentrepo.CheckoutAndCreateBranchIfNotExists(repo, testBranchName, gitConfig))
+
+	base, err := repo.Head()
+	assert.NoError(t, err)
+
+	repoWorkTree, err := repo.Worktree()
+	assert.NoError(t, err)
+	testutils.WriteToFile(t, filepath.Join(targetDir, "dummy.txt"), "This is a modified dummy file.")
+	testutils.WriteToFile(t, filepath.Join(targetDir, "test.txt"), "This is a test file.")
+	testutils.AddFileToWorkTree(t, repoWorkTree, "dummy.txt")
+	testutils.AddFileToWorkTree(t, repoWorkTree, "test.txt")
+	_, err = repoWorkTree.Remove("obsolete.txt")
+	assert.NoError(t, err)
+
+	staged, err := deploymentrepo.StagedChanges(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []deploymentrepo.FileChange{
+		{Action: deploymentrepo.FileModified, Path: "dummy.txt"},
+		{Action: deploymentrepo.FileDeleted, Path: "obsolete.txt"},
+		{Action: deploymentrepo.FileAdded, Path: "test.txt"},
+	}, staged)
+
+	assert.NoError(t, deploymentrepo.CommitChanges(repo, "Update files", "Test User", "noreply@test", gitConfig))
+
+	head, err := repo.Head()
+	assert.NoError(t, err)
+
+	files, err := deploymentrepo.ChangedFiles(repo, base.Hash(), head.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, []deploymentrepo.FileChange{
+		{Action: deploymentrepo.FileModified, Path: "dummy.txt"},
+		{Action: deploymentrepo.FileDeleted, Path: "obsolete.txt"},
+		{Action: deploymentrepo.FileAdded, Path: "test.txt"},
+	}, files)
+
+	changes := &deploymentrepo.PullRequestChanges{
+		Environment:       "dev",
+		ComponentLocation: "ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.2",
+		VersionConstraint: "~0.0.1",
+		Providers: config.Providers{
+			ClusterProviders: []config.Provider{{Name: "kind", Version: "v0.1.1"}},
+			PlatformServices: []config.Provider{{Name: "landscaper"}},
+		},
+		Files: files,
+	}
+
+	var request map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/openmcp-project/deployment/pulls", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 1, "html_url": "https://github.com/openmcp-project/deployment/pull/1"}`))
+	}))
+	defer server.Close()
+
+	f, err := forge.New(forge.GitHub, server.URL, "openmcp-project/deployment", "test-token", server.Client())
+	assert.NoError(t, err)
+
+	prURL, err := deploymentrepo.PushPullRequest(t.Context(), repo, gitConfig, f, forge.PullRequest{
+		Title:        "Update openMCP environment dev to v0.0.2",
+		Description:  changes.Description(),
+		SourceBranch: "openmcp-bootstrapper/dev-v0.0.2",
+		TargetBranch: testBranchName,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/openmcp-project/deployment/pull/1", prURL)
+
+	assert.Equal(t, "openmcp-bootstrapper/dev-v0.0.2", request["head"])
+	assert.Equal(t, testBranchName, request["base"])
+	assert.Equal(t, "Update openMCP environment dev to v0.0.2", request["title"])
+	assert.Contains(t, request["body"], "component version `ghcr.io/openmcp-project/components//github.com/openmcp-project/openmcp:v0.0.2`")
+	assert.Contains(t, request["body"], "resolved from the version constraint `~0.0.1`")
+	assert.Contains(t, request["body"], "- Cluster provider `kind` (version v0.1.1)\n- Platform service `landscaper`\n")
+	assert.Contains(t, request["body"], "- modified `dummy.txt`\n- deleted `obsolete.txt`\n- added `test.txt`\n")
+
+	featureBranch, err := origin.Reference(plumbing.NewBranchReferenceName("openmcp-bootstrapper/dev-v0.0.2"), true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, head.Hash(), featureBranch.Hash())
+	}
+}
@@ -3,18 +3,36 @@ package deploymentrepo
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
 
 	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
 	"github.com/openmcp-project/bootstrapper/internal/log"
 )
 
+const (
+	// DefaultCommitAuthor is the default author name of the commits in the deployment repository.
+	DefaultCommitAuthor = "openmcp"
+	// DefaultCommitEmail is the default author email of the commits in the deployment repository.
+	DefaultCommitEmail = "noreply@openmcp.cloud"
+
+	// sparseCheckoutFile is the file in the git directory containing the patterns of a sparse checkout.
+	sparseCheckoutFile = "info/sparse-checkout"
+)
+
 // gitProgressWriter is a writer that logs Git progress messages.
 type gitProgressWriter struct{}
 
@@ -24,16 +42,39 @@ func (w gitProgressWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// CloneOptions limit the history, branches and paths of a clone.
+type CloneOptions struct {
+	// Depth limits the fetched history to the given number of commits. 0 fetches the full history.
+	Depth int
+	// Branches limits the fetched branches, if not empty. Branches that do not exist in the remote repository are skipped.
+	Branches []string
+	// SparseDirectories limits the checked out directories, if not empty.
+	// They are recorded in the repository, so that later checkouts are limited to them as well.
+	SparseDirectories []string
+}
+
 // CloneRepo clones a Git repository from the specified URL to the given path.
 // It uses the provided gitConfig to configure the clone options with authentication.
 func CloneRepo(repoURL, path string, gitConfig *gitconfig.Config) (*git.Repository, error) {
+	return CloneRepoWithOptions(repoURL, path, CloneOptions{}, gitConfig)
+}
+
+// CloneRepoWithOptions clones a Git repository from the specified URL to the given path, limited by the given options.
+// It uses the provided gitConfig to configure the clone and fetch options with authentication.
+func CloneRepoWithOptions(repoURL, path string, options CloneOptions, gitConfig *gitconfig.Config) (*git.Repository, error) {
 	logger := log.GetLogger()
 
+	if len(options.Branches) > 0 {
+		return cloneBranches(repoURL, path, options, gitConfig)
+	}
+
 	logger.Debugf("Cloning repository from %s to %s", repoURL, path)
 
 	cloneOptions := &git.CloneOptions{
 		URL:          repoURL,
 		SingleBranch: false,
+		Depth:        options.Depth,
+		NoCheckout:   len(options.SparseDirectories) > 0,
 		Progress:     gitProgressWriter{},
 	}
 
@@ -42,13 +83,254 @@ func CloneRepo(repoURL, path string, gitConfig *gitconfig.Config) (*git.Reposito
 	}
 
 	repo, err := git.PlainClone(path, false, cloneOptions)
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		logger.Infof("Repository %s is empty, initializing it", repoURL)
+		return initEmptyRepo(repoURL, path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
 
+	if len(options.SparseDirectories) > 0 {
+		if err := setSparseCheckoutDirectories(repo, options.SparseDirectories); err != nil {
+			return nil, err
+		}
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		if err := checkout(repo, &git.CheckoutOptions{Hash: head.Hash(), Force: true}); err != nil {
+			return nil, err
+		}
+		if head.Name().IsBranch() {
+			// checking out the hash detaches HEAD, re-attach it to the branch
+			if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, head.Name())); err != nil {
+				return nil, fmt.Errorf("failed to set HEAD to %s: %w", head.Name(), err)
+			}
+		}
+	}
+
+	return repo, nil
+}
+
+// cloneBranches initializes a repository at the given path and fetches only the given branches from the repository at repoURL
+// into their remote-tracking branches, so that their existence can be checked like in a full clone.
+// HEAD is detached at the first existing branch, or at the HEAD of the remote repository if none of the branches exists.
+func cloneBranches(repoURL, path string, options CloneOptions, gitConfig *gitconfig.Config) (*git.Repository, error) {
+	logger := log.GetLogger()
+
+	logger.Debugf("Cloning branches %s of repository from %s to %s", strings.Join(options.Branches, ", "), repoURL, path)
+
+	repo, err := initEmptyRepo(repoURL, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options.SparseDirectories) > 0 {
+		if err := setSparseCheckoutDirectories(repo, options.SparseDirectories); err != nil {
+			return nil, err
+		}
+	}
+
+	var head *plumbing.Reference
+	fetched := make(map[string]bool, len(options.Branches))
+	for _, branch := range options.Branches {
+		if fetched[branch] {
+			continue
+		}
+		fetched[branch] = true
+
+		ref, err := fetchRef(repo, repoURL, plumbing.NewBranchReferenceName(branch), plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch), options.Depth, gitConfig)
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			logger.Infof("Repository %s is empty", repoURL)
+			return repo, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch branch %s: %w", branch, err)
+		}
+		if ref == nil {
+			logger.Debugf("Branch %s does not exist in repository %s", branch, repoURL)
+		} else if head == nil {
+			head = ref
+		}
+	}
+
+	if head == nil {
+		head, err = fetchRef(repo, repoURL, plumbing.HEAD, plumbing.NewRemoteHEADReferenceName(git.DefaultRemoteName), options.Depth, gitConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch HEAD: %w", err)
+		}
+	}
+	if head != nil {
+		if err := checkout(repo, &git.CheckoutOptions{Hash: head.Hash(), Force: true}); err != nil {
+			return nil, fmt.Errorf("failed to checkout %s: %w", head.Name().Short(), err)
+		}
+	}
+
+	return repo, nil
+}
+
+// fetchRef fetches the reference src of the repository at repoURL into the reference dst and returns it.
+// It returns no reference if src does not exist.
+func fetchRef(repo *git.Repository, repoURL string, src, dst plumbing.ReferenceName, depth int, gitConfig *gitconfig.Config) (*plumbing.Reference, error) {
+	fetchOptions := &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RemoteURL:  repoURL,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", src, dst)),
+		},
+		Depth:    depth,
+		Progress: gitProgressWriter{},
+	}
+	if err := gitConfig.ConfigureFetchOptions(fetchOptions); err != nil {
+		return nil, fmt.Errorf("failed to configure fetch options: %w", err)
+	}
+
+	err := repo.Fetch(fetchOptions)
+	if errors.Is(err, git.NoMatchingRefSpecError{}) {
+		return nil, nil
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+
+	ref, err := repo.Reference(dst, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fetched reference %s: %w", dst, err)
+	}
+	return ref, nil
+}
+
+// OpenRepo opens the existing checkout of a Git repository at the given path.
+// It fails if the working tree contains uncommitted changes or untracked files, so that they are not mixed into the rendered changes.
+func OpenRepo(path string) (*git.Repository, error) {
+	logger := log.GetLogger()
+
+	logger.Debugf("Opening repository at %s", path)
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository at %s: %w", path, err)
+	}
+
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := workTree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of worktree: %w", err)
+	}
+	if !status.IsClean() {
+		files := make([]string, 0, len(status))
+		for file, fileStatus := range status {
+			if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+				files = append(files, file)
+			}
+		}
+		slices.Sort(files)
+		return nil, fmt.Errorf("working tree of repository at %s is not clean, commit or stash the changes of: %s", path, strings.Join(files, ", "))
+	}
+
+	return repo, nil
+}
+
+// initEmptyRepo initializes a repository at the given path with the empty repository at repoURL as its origin remote.
+func initEmptyRepo(repoURL, path string) (*git.Repository, error) {
+	repo, err := git.PlainInit(path, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remote %s: %w", git.DefaultRemoteName, err)
+	}
+
 	return repo, nil
 }
 
+// setSparseCheckoutDirectories records the directories of a sparse checkout in the repository.
+// They are written in the format of a non-cone sparse checkout of git, so that the git CLI limits its checkouts to them as well.
+func setSparseCheckoutDirectories(repo *git.Repository, directories []string) error {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return fmt.Errorf("sparse checkouts require a repository on the filesystem")
+	}
+
+	var sb strings.Builder
+	for _, directory := range directories {
+		sb.WriteString("/" + strings.Trim(filepath.ToSlash(directory), "/") + "/\n")
+	}
+	if err := util.WriteFile(storage.Filesystem(), sparseCheckoutFile, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write sparse checkout file: %w", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to get repository config: %w", err)
+	}
+	cfg.Raw.Section("core").SetOption("sparseCheckout", "true")
+	cfg.Raw.Section("core").SetOption("sparseCheckoutCone", "false")
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to set repository config: %w", err)
+	}
+
+	return nil
+}
+
+// sparseCheckoutDirectories returns the directories of a sparse checkout recorded by setSparseCheckoutDirectories.
+// It returns no directories if the repository is not a sparse checkout or uses patterns other than directories.
+func sparseCheckoutDirectories(repo *git.Repository) ([]string, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository config: %w", err)
+	}
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok || cfg.Raw.Section("core").Option("sparseCheckout") != "true" {
+		return nil, nil
+	}
+
+	data, err := util.ReadFile(storage.Filesystem(), sparseCheckoutFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sparse checkout file: %w", err)
+	}
+
+	directories := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		directory, ok := strings.CutPrefix(strings.TrimSpace(line), "/")
+		directory, isDir := strings.CutSuffix(directory, "/")
+		if !ok || !isDir || directory == "" || strings.ContainsAny(directory, "*?[!") {
+			log.GetLogger().Debugf("Ignoring sparse checkout with pattern %q, which is not a directory", line)
+			return nil, nil
+		}
+		directories = append(directories, directory)
+	}
+	return directories, nil
+}
+
+// checkout checks out the worktree with the given options, limited to the directories of a sparse checkout, if any.
+func checkout(repo *git.Repository, options *git.CheckoutOptions) error {
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	options.SparseCheckoutDirectories, err = sparseCheckoutDirectories(repo)
+	if err != nil {
+		return err
+	}
+
+	return workTree.Checkout(options)
+}
+
 // PushRepo pushes the changes in the given repository to the remote.
 // It uses the provided gitConfig to configure the push options with authentication.
 func PushRepo(repo *git.Repository, branch string, gitConfig *gitconfig.Config) error {
@@ -56,7 +338,13 @@ func PushRepo(repo *git.Repository, branch string, gitConfig *gitconfig.Config)
 
 	logger.Debug("Pushing changes to remote repository")
 
+	remoteURL, err := originURL(repo)
+	if err != nil {
+		return err
+	}
+
 	pushOptions := &git.PushOptions{
+		RemoteURL: remoteURL,
 		RefSpecs: []config.RefSpec{
 			config.RefSpec(plumbing.HEAD + ":" + plumbing.NewBranchReferenceName(branch)),
 		},
@@ -78,8 +366,23 @@ func PushRepo(repo *git.Repository, branch string, gitConfig *gitconfig.Config)
 	return nil
 }
 
+// originURL returns the URL of the origin remote of the repository.
+// It is passed to the push options, so that the host key of the git server can be verified for SSH URLs.
+func originURL(repo *git.Repository) (string, error) {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %s: %w", git.DefaultRemoteName, err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", git.DefaultRemoteName)
+	}
+	return urls[0], nil
+}
+
 // CommitChanges commits all changes in the repository with the specified message, author name, and email.
-func CommitChanges(repo *git.Repository, message, name, email string) error {
+// It uses the provided gitConfig to sign the commit.
+func CommitChanges(repo *git.Repository, message, name, email string, gitConfig *gitconfig.Config) error {
 	logger := log.GetLogger()
 
 	logger.Debugf("Committing changes with message: %s", message)
@@ -89,13 +392,19 @@ func CommitChanges(repo *git.Repository, message, name, email string) error {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	hash, err := workTree.Commit(message, &git.CommitOptions{
+	commitOptions := &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  name,
 			Email: email,
 			When:  time.Now(),
 		},
-	})
+	}
+
+	if err := gitConfig.ConfigureCommitOptions(commitOptions); err != nil {
+		return fmt.Errorf("failed to configure commit options: %w", err)
+	}
+
+	hash, err := workTree.Commit(message, commitOptions)
 	if err != nil {
 		if errors.Is(err, git.ErrEmptyCommit) {
 			logger.Info("No changes to commit")
@@ -114,6 +423,7 @@ func CommitChanges(repo *git.Repository, message, name, email string) error {
 // CheckoutAndCreateBranchIfNotExists checks out a branch with the given name.
 // If the branch does not exist, it creates a new branch with that name and pushes it
 // to the remote repository. If the branch already exists, it checks out the existing branch.
+// In an empty repository, the new branch starts with an empty initial commit, which is signed with the provided gitConfig.
 func CheckoutAndCreateBranchIfNotExists(repo *git.Repository, branchName string, gitConfig *gitconfig.Config) error {
 	logger := log.GetLogger()
 
@@ -139,23 +449,28 @@ func CheckoutAndCreateBranchIfNotExists(repo *git.Repository, branchName string,
 		return err
 	}
 
-	workTree, err := repo.Worktree()
-	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
-	}
-
 	if !branchExists {
 		// Create and checkout new branch
 		logger.Debugf("Branch %s does not exist. Creating...\n", branchName)
-		err = workTree.Checkout(&git.CheckoutOptions{
-			Branch: localRef,
-			Create: true,
-		})
+		if _, err := repo.Head(); errors.Is(err, plumbing.ErrReferenceNotFound) {
+			err = createInitialCommit(repo, localRef, gitConfig)
+		} else {
+			err = checkout(repo, &git.CheckoutOptions{
+				Branch: localRef,
+				Create: true,
+			})
+		}
 		if err != nil {
 			return fmt.Errorf("failed to create branch: %w", err)
 		}
 
+		remoteURL, err := originURL(repo)
+		if err != nil {
+			return err
+		}
+
 		pushOptions := &git.PushOptions{
+			RemoteURL: remoteURL,
 			RefSpecs: []config.RefSpec{
 				config.RefSpec(localRef + ":" + localRef),
 			},
@@ -174,7 +489,7 @@ func CheckoutAndCreateBranchIfNotExists(repo *git.Repository, branchName string,
 	}
 
 	// Checkout existing branch
-	err = workTree.Checkout(&git.CheckoutOptions{
+	err = checkout(repo, &git.CheckoutOptions{
 		Branch: remoteRef,
 	})
 	if err != nil {
@@ -183,3 +498,176 @@ func CheckoutAndCreateBranchIfNotExists(repo *git.Repository, branchName string,
 
 	return nil
 }
+
+// CheckoutLocalBranch checks out the local branch with the given name in an existing checkout.
+// If the local branch does not exist, it is created from the remote branch of the origin remote, if that exists, and from HEAD otherwise.
+// In contrast to CheckoutAndCreateBranchIfNotExists, a new branch is not pushed, this is left to the push of the changes.
+func CheckoutLocalBranch(repo *git.Repository, branchName string, gitConfig *gitconfig.Config) error {
+	logger := log.GetLogger()
+
+	localRef := plumbing.NewBranchReferenceName(branchName)
+	checkoutOptions := &git.CheckoutOptions{
+		Branch: localRef,
+	}
+
+	_, err := repo.Reference(localRef, false)
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return fmt.Errorf("failed to get branch %s: %w", branchName, err)
+	}
+	if err != nil {
+		checkoutOptions.Create = true
+
+		remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branchName), true)
+		switch {
+		case err == nil:
+			logger.Debugf("Creating branch %s from %s", branchName, remoteRef.Name())
+			checkoutOptions.Hash = remoteRef.Hash()
+		case errors.Is(err, plumbing.ErrReferenceNotFound):
+			if _, err := repo.Head(); errors.Is(err, plumbing.ErrReferenceNotFound) {
+				logger.Debugf("Branch %s does not exist in the empty repository. Creating...", branchName)
+				return createInitialCommit(repo, localRef, gitConfig)
+			}
+			logger.Debugf("Branch %s does not exist. Creating it from HEAD...", branchName)
+		default:
+			return fmt.Errorf("failed to get remote branch %s: %w", branchName, err)
+		}
+	}
+
+	if err := checkout(repo, checkoutOptions); err != nil {
+		return fmt.Errorf("failed to checkout branch %s: %w", branchName, err)
+	}
+
+	return nil
+}
+
+// createInitialCommit creates the branch in an empty repository with an empty initial commit.
+func createInitialCommit(repo *git.Repository, branch plumbing.ReferenceName, gitConfig *gitconfig.Config) error {
+	logger := log.GetLogger()
+
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		return fmt.Errorf("failed to set HEAD to %s: %w", branch, err)
+	}
+
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	commitOptions := &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author: &object.Signature{
+			Name:  DefaultCommitAuthor,
+			Email: DefaultCommitEmail,
+			When:  time.Now(),
+		},
+	}
+
+	if err := gitConfig.ConfigureCommitOptions(commitOptions); err != nil {
+		return fmt.Errorf("failed to configure commit options: %w", err)
+	}
+
+	hash, err := workTree.Commit("Initialize branch "+branch.Short(), commitOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create initial commit: %w", err)
+	}
+
+	logger.Infof("Created initial commit: %s", hash.String())
+	return nil
+}
+
+const (
+	// FileAdded marks a file added by a commit.
+	FileAdded = "added"
+	// FileModified marks a file modified by a commit.
+	FileModified = "modified"
+	// FileDeleted marks a file deleted by a commit.
+	FileDeleted = "deleted"
+)
+
+// FileChange is a file changed between two commits.
+type FileChange struct {
+	// Action is one of FileAdded, FileModified and FileDeleted.
+	Action string
+	// Path is the path of the file in the repository.
+	Path string
+}
+
+// ChangedFiles returns the files changed between the commits from and to, sorted by path.
+func ChangedFiles(repo *git.Repository, from, to plumbing.Hash) ([]FileChange, error) {
+	fromTree, err := commitTree(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := commitTree(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commits %s and %s: %w", from, to, err)
+	}
+
+	files := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get action of change: %w", err)
+		}
+
+		switch action {
+		case merkletrie.Insert:
+			files = append(files, FileChange{Action: FileAdded, Path: change.To.Name})
+		case merkletrie.Delete:
+			files = append(files, FileChange{Action: FileDeleted, Path: change.From.Name})
+		default:
+			files = append(files, FileChange{Action: FileModified, Path: change.To.Name})
+		}
+	}
+
+	slices.SortFunc(files, func(a, b FileChange) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files, nil
+}
+
+// StagedChanges returns the files staged for the next commit, sorted by path.
+func StagedChanges(repo *git.Repository) ([]FileChange, error) {
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := workTree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	files := make([]FileChange, 0, len(status))
+	for path, fileStatus := range status {
+		switch fileStatus.Staging {
+		case git.Added, git.Copied:
+			files = append(files, FileChange{Action: FileAdded, Path: path})
+		case git.Deleted:
+			files = append(files, FileChange{Action: FileDeleted, Path: path})
+		case git.Modified, git.Renamed:
+			files = append(files, FileChange{Action: FileModified, Path: path})
+		}
+	}
+
+	slices.SortFunc(files, func(a, b FileChange) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files, nil
+}
+
+func commitTree(repo *git.Repository, hash plumbing.Hash) (*object.Tree, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of commit %s: %w", hash, err)
+	}
+	return tree, nil
+}
@@ -1,10 +1,18 @@
 package deploymentrepo_test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 
 	deploymentrepo "github.com/openmcp-project/bootstrapper/internal/deployment-repo"
@@ -47,7 +55,7 @@ func Test_Repo(t *testing.T) {
 	testutils.WriteToFile(t, testFilePath, "This is a test file.")
 	testutils.AddFileToWorkTree(t, repoWorkTree, "test.txt")
 
-	err = deploymentrepo.CommitChanges(repo, "Add test.txt", "Test User", "noreply@test")
+	err = deploymentrepo.CommitChanges(repo, "Add test.txt", "Test User", "noreply@test", gitConfig)
 	assert.NoError(t, err)
 
 	err = deploymentrepo.PushRepo(repo, testBranchName, gitConfig)
@@ -70,3 +78,251 @@ func Test_Repo(t *testing.T) {
 
 	assert.True(t, hasTestBranch, "Origin repository should have 'test' branch")
 }
+
+func Test_RepoExistingCheckout(t *testing.T) {
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+	originWorkTree, err := origin.Worktree()
+	assert.NoError(t, err)
+	testutils.WriteToFile(t, filepath.Join(originDir, "dummy.txt"), "This is a dummy file.")
+	testutils.AddFileToWorkTree(t, originWorkTree, "dummy.txt")
+	testutils.WorkTreeCommit(t, originWorkTree, "Initial commit")
+	initialCommit, err := origin.Head()
+	assert.NoError(t, err)
+	assert.NoError(t, originWorkTree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("remote"), Create: true}))
+	testutils.WriteToFile(t, filepath.Join(originDir, "remote.txt"), "This is a remote file.")
+	testutils.AddFileToWorkTree(t, originWorkTree, "remote.txt")
+	testutils.WorkTreeCommit(t, originWorkTree, "Remote commit")
+	remoteCommit, err := origin.Head()
+	assert.NoError(t, err)
+
+	gitConfig := &gitconfig.Config{}
+	checkoutDir := t.TempDir()
+	_, err = deploymentrepo.CloneRepo(originDir, checkoutDir, gitConfig)
+	assert.NoError(t, err)
+
+	testutils.WriteToFile(t, filepath.Join(checkoutDir, "untracked.txt"), "This is an untracked file.")
+	_, err = deploymentrepo.OpenRepo(checkoutDir)
+	assert.ErrorContains(t, err, "untracked.txt")
+	assert.NoError(t, os.Remove(filepath.Join(checkoutDir, "untracked.txt")))
+
+	repo, err := deploymentrepo.OpenRepo(checkoutDir)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		branch       string
+		expectedHead plumbing.Hash
+	}{
+		{branch: "remote", expectedHead: remoteCommit.Hash()},
+		{branch: "new", expectedHead: remoteCommit.Hash()},
+		{branch: "master", expectedHead: initialCommit.Hash()},
+	}
+	for _, tc := range testCases {
+		assert.NoError(t, deploymentrepo.CheckoutLocalBranch(repo, tc.branch, gitConfig))
+		head, err := repo.Head()
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.NewBranchReferenceName(tc.branch), head.Name())
+		assert.Equal(t, tc.expectedHead, head.Hash())
+	}
+
+	_, err = origin.Reference(plumbing.NewBranchReferenceName("new"), false)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound, "new branch should not be pushed")
+}
+
+func Test_RepoSignedCommits(t *testing.T) {
+	originDir := t.TempDir()
+	targetDir := t.TempDir()
+
+	origin, err := git.PlainInit(originDir, true)
+	assert.NoError(t, err)
+
+	entity, err := openpgp.NewEntity("openmcp", "", "noreply@openmcp.cloud", nil)
+	assert.NoError(t, err)
+	var privateKey, publicKey bytes.Buffer
+	w, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.SerializePrivate(w, nil))
+	assert.NoError(t, w.Close())
+	w, err = armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.Serialize(w))
+	assert.NoError(t, w.Close())
+
+	gitConfig := &gitconfig.Config{
+		Signing: &gitconfig.Signing{Key: base64.StdEncoding.EncodeToString(privateKey.Bytes())},
+	}
+
+	repo, err := deploymentrepo.CloneRepo(originDir, targetDir, gitConfig)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, repo) {
+		return
+	}
+
+	err = deploymentrepo.CheckoutAndCreateBranchIfNotExists(repo, testBranchName, gitConfig)
+	assert.NoError(t, err)
+
+	initialCommit := verifyBranchCommit(t, origin, publicKey.String())
+	if assert.NotNil(t, initialCommit) {
+		assert.Equal(t, "Initialize branch "+testBranchName, initialCommit.Message)
+		assert.Equal(t, deploymentrepo.DefaultCommitAuthor, initialCommit.Author.Name)
+	}
+
+	repoWorkTree, err := repo.Worktree()
+	assert.NoError(t, err)
+	testutils.WriteToFile(t, filepath.Join(targetDir, "test.txt"), "This is a test file.")
+	testutils.AddFileToWorkTree(t, repoWorkTree, "test.txt")
+
+	err = deploymentrepo.CommitChanges(repo, "Add test.txt", "Test User", "noreply@test", gitConfig)
+	assert.NoError(t, err)
+	err = deploymentrepo.PushRepo(repo, testBranchName, gitConfig)
+	assert.NoError(t, err)
+
+	commit := verifyBranchCommit(t, origin, publicKey.String())
+	if assert.NotNil(t, commit) {
+		assert.Equal(t, "Add test.txt", commit.Message)
+	}
+}
+
+// verifyBranchCommit verifies the signature of the last commit of the test branch in the repository and returns the commit.
+func verifyBranchCommit(t *testing.T, repo *git.Repository, armoredPublicKey string) *object.Commit {
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(testBranchName), true)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	_, err = commit.Verify(armoredPublicKey)
+	assert.NoError(t, err)
+	return commit
+}
+
+func Test_RepoCloneOptions(t *testing.T) {
+	const deployBranch = "deploy"
+
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+	originWorkTree, err := origin.Worktree()
+	assert.NoError(t, err)
+	for i, file := range []string{"root.txt", "envs/dev/dev.yaml", "envs/prod/prod.yaml", "resources/resource.yaml"} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(originDir, file)), 0o755))
+		testutils.WriteToFile(t, filepath.Join(originDir, file), file)
+		testutils.AddFileToWorkTree(t, originWorkTree, file)
+		testutils.WorkTreeCommit(t, originWorkTree, fmt.Sprintf("Commit %d", i))
+	}
+	head, err := origin.Head()
+	assert.NoError(t, err)
+	assert.NoError(t, origin.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(deployBranch), head.Hash())))
+	assert.NoError(t, origin.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("other"), head.Hash())))
+
+	// pushes of shallow clones to a local repository require a bare repository
+	bareDir := t.TempDir()
+	bare, err := git.PlainClone(bareDir, true, &git.CloneOptions{URL: originDir, Mirror: true})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc                string
+		options             deploymentrepo.CloneOptions
+		branch              string
+		expectShallow       bool
+		expectedOtherBranch bool
+		expectedFiles       []string
+		expectedMissing     []string
+	}{
+		{
+			desc:                "should clone all branches with full history",
+			branch:              deployBranch,
+			expectedOtherBranch: true,
+			expectedFiles:       []string{"root.txt", "envs/prod/prod.yaml"},
+		},
+		{
+			desc:                "should clone with limited depth",
+			options:             deploymentrepo.CloneOptions{Depth: 1},
+			branch:              deployBranch,
+			expectShallow:       true,
+			expectedOtherBranch: true,
+			expectedFiles:       []string{"root.txt", "envs/prod/prod.yaml"},
+		},
+		{
+			desc:          "should clone a single branch",
+			options:       deploymentrepo.CloneOptions{Branches: []string{deployBranch}},
+			branch:        deployBranch,
+			expectedFiles: []string{"root.txt", "envs/prod/prod.yaml"},
+		},
+		{
+			desc:          "should create a branch missing in a single branch clone",
+			options:       deploymentrepo.CloneOptions{Depth: 1, Branches: []string{deployBranch, "new"}},
+			branch:        "new",
+			expectShallow: true,
+			expectedFiles: []string{"root.txt", "envs/prod/prod.yaml"},
+		},
+		{
+			desc:            "should check out only the sparse directories",
+			options:         deploymentrepo.CloneOptions{Depth: 1, Branches: []string{deployBranch}, SparseDirectories: []string{"envs/dev", "resources"}},
+			branch:          deployBranch,
+			expectShallow:   true,
+			expectedFiles:   []string{"envs/dev/dev.yaml", "resources/resource.yaml"},
+			expectedMissing: []string{"root.txt", "envs/prod/prod.yaml"},
+		},
+		{
+			desc:                "should check out only the sparse directories of a full clone",
+			options:             deploymentrepo.CloneOptions{SparseDirectories: []string{"envs/dev", "resources"}},
+			branch:              deployBranch,
+			expectedOtherBranch: true,
+			expectedFiles:       []string{"envs/dev/dev.yaml", "resources/resource.yaml"},
+			expectedMissing:     []string{"root.txt", "envs/prod/prod.yaml"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gitConfig := &gitconfig.Config{}
+			repoDir := t.TempDir()
+
+			repo, err := deploymentrepo.CloneRepoWithOptions(bareDir, repoDir, tc.options, gitConfig)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, deploymentrepo.CheckoutAndCreateBranchIfNotExists(repo, tc.branch, gitConfig))
+
+			_, err = repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, "other"), false)
+			assert.Equal(t, tc.expectedOtherBranch, err == nil, "clone should contain branch other: %t", tc.expectedOtherBranch)
+
+			for _, file := range tc.expectedFiles {
+				assert.FileExists(t, filepath.Join(repoDir, file))
+			}
+			for _, file := range tc.expectedMissing {
+				assert.NoFileExists(t, filepath.Join(repoDir, file))
+			}
+
+			workTree, err := repo.Worktree()
+			assert.NoError(t, err)
+			status, err := workTree.Status()
+			assert.NoError(t, err)
+			assert.True(t, status.IsClean(), "worktree should be clean: %s", status)
+
+			testutils.WriteToFile(t, filepath.Join(repoDir, "resources", "new.yaml"), "new")
+			testutils.AddFileToWorkTree(t, workTree, "resources/new.yaml")
+			assert.NoError(t, deploymentrepo.CommitChanges(repo, fmt.Sprintf("Add new.yaml %d", i), "Test User", "noreply@test", gitConfig))
+			assert.NoError(t, deploymentrepo.PushRepo(repo, tc.branch, gitConfig))
+
+			shallow, err := repo.Storer.Shallow()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectShallow, len(shallow) > 0)
+
+			pushed, err := bare.Reference(plumbing.NewBranchReferenceName(tc.branch), true)
+			if !assert.NoError(t, err) {
+				return
+			}
+			commit, err := bare.CommitObject(pushed.Hash())
+			assert.NoError(t, err)
+			for _, file := range []string{"root.txt", "envs/dev/dev.yaml", "envs/prod/prod.yaml", "resources/resource.yaml", "resources/new.yaml"} {
+				_, err := commit.File(file)
+				assert.NoError(t, err, "pushed commit should contain %s", file)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package deploymentrepo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const (
+	// DefaultPushRetries is the default number of times a rejected push is retried on top of the new remote head.
+	DefaultPushRetries = 3
+)
+
+// PushConflictError is returned by SafePushRepo if the commits pushed to the remote branch since the clone
+// changed the same managed files as the commit of the bootstrapper.
+type PushConflictError struct {
+	// Branch is the remote branch.
+	Branch string
+	// RemoteHead is the head of the remote branch the changes have been re-applied on.
+	RemoteHead plumbing.Hash
+	// Files are the files changed on both sides, sorted by path.
+	Files []string
+}
+
+// Error returns the conflict report.
+func (e *PushConflictError) Error() string {
+	return fmt.Sprintf("changes conflict with the remote branch %s at %s, the following managed files have been changed on both sides:\n  %s",
+		e.Branch, e.RemoteHead, strings.Join(e.Files, "\n  "))
+}
+
+// SafePushRepo pushes the HEAD commit of the repository to the branch like PushRepo.
+// If the push is rejected because the remote branch moved, it fetches the branch, re-applies the changes of the HEAD commit
+// on top of the new remote head, re-commits them with the same message and author and retries, at most retries times.
+// It fails with a *PushConflictError if the remote commits changed the same files differently.
+func SafePushRepo(repo *git.Repository, branch string, retries int, gitConfig *gitconfig.Config) error {
+	logger := log.GetLogger()
+
+	for attempt := 0; ; attempt++ {
+		err := PushRepo(repo, branch, gitConfig)
+		if err == nil || !isNonFastForwardError(err) {
+			return err
+		}
+		if attempt >= retries {
+			return fmt.Errorf("push to branch %s still rejected after %d retries: %w", branch, retries, err)
+		}
+
+		logger.Warnf("Push to branch %s rejected because the remote branch moved, re-applying changes on top of it (retry %d/%d)", branch, attempt+1, retries)
+		if err := rebaseOnRemoteBranch(repo, branch, gitConfig); err != nil {
+			return err
+		}
+	}
+}
+
+// isNonFastForwardError returns true if the push has been rejected because the remote branch is not an ancestor of the pushed commit.
+func isNonFastForwardError(err error) bool {
+	return strings.Contains(err.Error(), "non-fast-forward") || strings.Contains(err.Error(), "fetch first")
+}
+
+// rebaseOnRemoteBranch fetches the remote branch and replaces the HEAD commit by a commit of the same changes on top of the remote head.
+func rebaseOnRemoteBranch(repo *git.Repository, branch string, gitConfig *gitconfig.Config) error {
+	logger := log.GetLogger()
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	if commit.NumParents() != 1 {
+		return fmt.Errorf("cannot re-apply commit %s with %d parents", commit.Hash, commit.NumParents())
+	}
+	base := commit.ParentHashes[0]
+
+	remoteHead, err := fetchBranch(repo, branch, gitConfig)
+	if err != nil {
+		return err
+	}
+	logger.Debugf("Remote branch %s moved from %s to %s", branch, base, remoteHead)
+
+	ours, err := ChangedFiles(repo, base, commit.Hash)
+	if err != nil {
+		return err
+	}
+	theirs, err := ChangedFiles(repo, base, remoteHead)
+	if err != nil {
+		return err
+	}
+
+	localTree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get tree of commit %s: %w", commit.Hash, err)
+	}
+	remoteTree, err := commitTree(repo, remoteHead)
+	if err != nil {
+		return err
+	}
+
+	conflicts, err := conflictingFiles(ours, theirs, localTree, remoteTree)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &PushConflictError{Branch: branch, RemoteHead: remoteHead, Files: conflicts}
+	}
+
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	checkoutOptions := &git.CheckoutOptions{Hash: remoteHead, Force: true}
+	if head.Name().IsBranch() {
+		// keep the local branch of an existing checkout checked out, reset to the remote head
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), remoteHead)); err != nil {
+			return fmt.Errorf("failed to reset branch %s to remote head %s: %w", head.Name().Short(), remoteHead, err)
+		}
+		checkoutOptions = &git.CheckoutOptions{Branch: head.Name(), Force: true}
+	}
+	if err := checkout(repo, checkoutOptions); err != nil {
+		return fmt.Errorf("failed to checkout remote head %s: %w", remoteHead, err)
+	}
+
+	for _, change := range ours {
+		logger.Tracef("Re-applying %s file %s", change.Action, change.Path)
+		if change.Action == FileDeleted {
+			if _, err := remoteTree.FindEntry(change.Path); err == nil {
+				if _, err := workTree.Remove(change.Path); err != nil {
+					return fmt.Errorf("failed to remove file %s: %w", change.Path, err)
+				}
+			}
+			continue
+		}
+		if err := copyFileToWorkTree(localTree, workTree, change.Path); err != nil {
+			return err
+		}
+	}
+
+	return CommitChanges(repo, commit.Message, commit.Author.Name, commit.Author.Email, gitConfig)
+}
+
+// fetchBranch fetches the branch from the origin remote and returns its head.
+func fetchBranch(repo *git.Repository, branch string, gitConfig *gitconfig.Config) (plumbing.Hash, error) {
+	remoteURL, err := originURL(repo)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	remoteRef := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch)
+	fetchOptions := &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RemoteURL:  remoteURL,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), remoteRef)),
+		},
+		Progress: gitProgressWriter{},
+	}
+	if err := gitConfig.ConfigureFetchOptions(fetchOptions); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to configure fetch options: %w", err)
+	}
+
+	if err := repo.Fetch(fetchOptions); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return plumbing.ZeroHash, fmt.Errorf("failed to fetch branch %s: %w", branch, err)
+	}
+
+	ref, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get fetched branch %s: %w", branch, err)
+	}
+	return ref.Hash(), nil
+}
+
+// conflictingFiles returns the paths changed both by ours and theirs, whose contents differ between the two trees.
+func conflictingFiles(ours, theirs []FileChange, oursTree, theirsTree *object.Tree) ([]string, error) {
+	theirPaths := make(map[string]bool, len(theirs))
+	for _, change := range theirs {
+		theirPaths[change.Path] = true
+	}
+
+	conflicts := make([]string, 0)
+	for _, change := range ours {
+		if !theirPaths[change.Path] {
+			continue
+		}
+		ourHash, err := fileHash(oursTree, change.Path)
+		if err != nil {
+			return nil, err
+		}
+		theirHash, err := fileHash(theirsTree, change.Path)
+		if err != nil {
+			return nil, err
+		}
+		if ourHash != theirHash {
+			conflicts = append(conflicts, change.Path)
+		}
+	}
+	return conflicts, nil
+}
+
+// fileHash returns the blob hash of the file in the tree, or the zero hash if the tree does not contain the file.
+func fileHash(tree *object.Tree, filePath string) (plumbing.Hash, error) {
+	entry, err := tree.FindEntry(filePath)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to find file %s: %w", filePath, err)
+	}
+	return entry.Hash, nil
+}
+
+// copyFileToWorkTree writes the file of the tree into the worktree and stages it.
+func copyFileToWorkTree(tree *object.Tree, workTree *git.Worktree, filePath string) error {
+	file, err := tree.File(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to get file %s: %w", filePath, err)
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	if err := workTree.Filesystem.MkdirAll(path.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory of file %s: %w", filePath, err)
+	}
+	target, err := workTree.Filesystem.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s in worktree: %w", filePath, err)
+	}
+	if _, err := io.Copy(target, reader); err != nil {
+		_ = target.Close()
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := target.Close(); err != nil {
+		return fmt.Errorf("failed to close file %s: %w", filePath, err)
+	}
+
+	if _, err := workTree.Add(filePath); err != nil {
+		return fmt.Errorf("failed to add file %s to git index: %w", filePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package deploymentrepo_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+
+	deploymentrepo "github.com/openmcp-project/bootstrapper/internal/deployment-repo"
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	testutils "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+func TestSafePushRepo(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		ourFiles          map[string]string
+		retries           int
+		expectedConflicts []string
+		expectError       bool
+		expectNoCommit    bool
+		expectedFiles     map[string]string
+	}{
+		{
+			desc:     "should re-apply changes of other files on top of the remote branch",
+			ourFiles: map[string]string{"a.txt": "ours", "resources/d.txt": "ours"},
+			retries:  1,
+			expectedFiles: map[string]string{
+				"a.txt":           "ours",
+				"b.txt":           "theirs",
+				"c.txt":           "theirs",
+				"resources/d.txt": "ours",
+			},
+		},
+		{
+			desc:           "should accept identical changes on both sides",
+			ourFiles:       map[string]string{"b.txt": "theirs"},
+			retries:        1,
+			expectNoCommit: true,
+			expectedFiles:  map[string]string{"a.txt": "initial", "b.txt": "theirs", "c.txt": "theirs"},
+		},
+		{
+			desc:              "should report conflicting changes",
+			ourFiles:          map[string]string{"a.txt": "ours", "b.txt": "ours", "c.txt": "ours"},
+			retries:           1,
+			expectedConflicts: []string{"b.txt", "c.txt"},
+		},
+		{
+			desc:        "should fail without retries",
+			ourFiles:    map[string]string{"a.txt": "ours"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gitConfig := &gitconfig.Config{}
+			originDir := t.TempDir()
+
+			origin, err := git.PlainInit(originDir, false)
+			assert.NoError(t, err)
+			originWorkTree, err := origin.Worktree()
+			assert.NoError(t, err)
+			for _, file := range []string{"a.txt", "b.txt"} {
+				testutils.WriteToFile(t, filepath.Join(originDir, file), "initial")
+				testutils.AddFileToWorkTree(t, originWorkTree, file)
+			}
+			testutils.WorkTreeCommit(t, originWorkTree, "Initial commit")
+
+			ourDir := t.TempDir()
+			ours, err := deploymentrepo.CloneRepo(originDir, ourDir, gitConfig)
+			assert.NoError(t, err)
+			assert.NoError(t, deploymentrepo.CheckoutAndCreateBranchIfNotExists(ours, testBranchName, gitConfig))
+
+			// someone else pushes to the branch after the clone
+			theirDir := t.TempDir()
+			theirs, err := deploymentrepo.CloneRepo(originDir, theirDir, gitConfig)
+			assert.NoError(t, err)
+			assert.NoError(t, deploymentrepo.CheckoutAndCreateBranchIfNotExists(theirs, testBranchName, gitConfig))
+			commitFiles(t, theirs, theirDir, map[string]string{"b.txt": "theirs", "c.txt": "theirs"}, gitConfig)
+			assert.NoError(t, deploymentrepo.PushRepo(theirs, testBranchName, gitConfig))
+			theirHead, err := theirs.Head()
+			assert.NoError(t, err)
+
+			commitFiles(t, ours, ourDir, tc.ourFiles, gitConfig)
+			err = deploymentrepo.SafePushRepo(ours, testBranchName, tc.retries, gitConfig)
+
+			var conflictErr *deploymentrepo.PushConflictError
+			switch {
+			case tc.expectedConflicts != nil:
+				if assert.True(t, errors.As(err, &conflictErr)) {
+					assert.Equal(t, tc.expectedConflicts, conflictErr.Files)
+					assert.Equal(t, theirHead.Hash(), conflictErr.RemoteHead)
+				}
+				return
+			case tc.expectError:
+				assert.Error(t, err)
+				assert.False(t, errors.As(err, &conflictErr))
+				return
+			}
+			assert.NoError(t, err)
+
+			ref, err := origin.Reference(plumbing.NewBranchReferenceName(testBranchName), true)
+			assert.NoError(t, err)
+			commit, err := origin.CommitObject(ref.Hash())
+			assert.NoError(t, err)
+			if tc.expectNoCommit {
+				assert.Equal(t, theirHead.Hash(), commit.Hash)
+			} else {
+				assert.Equal(t, "Update files", commit.Message)
+				assert.Equal(t, []plumbing.Hash{theirHead.Hash()}, commit.ParentHashes)
+			}
+
+			tree, err := commit.Tree()
+			assert.NoError(t, err)
+			numFiles := 0
+			assert.NoError(t, tree.Files().ForEach(func(file *object.File) error {
+				numFiles++
+				content, err := file.Contents()
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedFiles[file.Name], content, file.Name)
+				return nil
+			}))
+			assert.Equal(t, len(tc.expectedFiles), numFiles)
+		})
+	}
+}
+
+// commitFiles writes the files into the worktree of the repository and commits them.
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string, gitConfig *gitconfig.Config) {
+	workTree, err := repo.Worktree()
+	assert.NoError(t, err)
+	for file, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0o755))
+		testutils.WriteToFile(t, filepath.Join(dir, file), content)
+		testutils.AddFileToWorkTree(t, workTree, file)
+	}
+	assert.NoError(t, deploymentrepo.CommitChanges(repo, "Update files", "Test User", "noreply@test", gitConfig))
+}
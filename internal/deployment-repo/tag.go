@@ -0,0 +1,206 @@
+package deploymentrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const (
+	// remoteTagRefPrefix is the prefix of the references into which the tags of the remote repository are fetched,
+	// so that they can be compared without overwriting the local tags.
+	remoteTagRefPrefix = "refs/openmcp-bootstrapper/tags/"
+)
+
+// tagRelease creates an annotated tag with the name rendered from the tag template on HEAD and pushes it to the deployment repository.
+// The tag message names the environment and the applied component version. It returns the reference of the tag.
+func (m *DeploymentRepoManager) tagRelease(ctx context.Context, templateInput map[string]interface{}, tagger, taggerEmail string) (plumbing.ReferenceName, error) {
+	name, err := TemplateString(ctx, "tag", m.TagTemplate, templateInput, m.relocator, m.compGetter)
+	if err != nil {
+		return "", fmt.Errorf("failed to render tag name: %w", err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("tag template %q rendered an empty tag name", m.TagTemplate)
+	}
+
+	head, err := m.gitRepo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD of deployment repository: %w", err)
+	}
+
+	message := fmt.Sprintf("openMCP environment %s\n\nComponent version %s", m.Config.Environment, m.ComponentLocation())
+	if paragraph := m.resolvedComponentVersion(); paragraph != "" {
+		message += "\n\n" + paragraph
+	}
+
+	err = TagCommit(m.gitRepo, name, message, head.Hash(), object.Signature{Name: tagger, Email: taggerEmail, When: time.Now()}, m.gitConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to tag commit %s: %w", head.Hash(), err)
+	}
+	return plumbing.NewTagReferenceName(name), nil
+}
+
+// TagCommit creates the annotated tag name with the message on the commit hash and pushes it to the remote repository.
+// The tag is signed like the commits, if signing is configured. A tag that already exists on the commit, locally or in the
+// remote repository, is kept. A tag that exists on a different commit is refused, so that release markers are never moved.
+func TagCommit(repo *git.Repository, name, message string, hash plumbing.Hash, tagger object.Signature, gitConfig *gitconfig.Config) error {
+	logger := log.GetLogger()
+
+	tagRef := plumbing.NewTagReferenceName(name)
+	if err := tagRef.Validate(); err != nil {
+		return fmt.Errorf("invalid tag name %q: %w", name, err)
+	}
+
+	remoteURL, err := originURL(repo)
+	if err != nil {
+		return err
+	}
+
+	// the tag of the remote repository is fetched into a separate reference, so that a local tag is not overwritten
+	remoteTagRef := plumbing.ReferenceName(remoteTagRefPrefix + name)
+	remoteTag, err := fetchRemoteTag(repo, remoteURL, tagRef, remoteTagRef, gitConfig)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tag %s: %w", name, err)
+	}
+	if remoteTag != nil {
+		defer func() {
+			_ = repo.Storer.RemoveReference(remoteTagRef)
+		}()
+
+		target, err := tagTarget(repo, remoteTag.Hash())
+		if err != nil {
+			return err
+		}
+		if target != hash {
+			return fmt.Errorf("tag %s already exists on commit %s of the remote repository, refusing to move it to commit %s", name, target, hash)
+		}
+	}
+
+	localTag, err := repo.Reference(tagRef, false)
+	switch {
+	case err == nil:
+		target, err := tagTarget(repo, localTag.Hash())
+		if err != nil {
+			return err
+		}
+		if target != hash {
+			return fmt.Errorf("tag %s already exists on commit %s, refusing to move it to commit %s", name, target, hash)
+		}
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		if remoteTag != nil {
+			break
+		}
+		if err = createTag(repo, tagRef, message, hash, tagger, gitConfig); err != nil {
+			return err
+		}
+		logger.Infof("Created tag %s on commit %s", name, hash)
+	default:
+		return fmt.Errorf("failed to get tag %s: %w", name, err)
+	}
+
+	if remoteTag != nil {
+		logger.Infof("Tag %s already exists on commit %s", name, hash)
+		return nil
+	}
+
+	pushOptions := &git.PushOptions{
+		RemoteURL: remoteURL,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(tagRef + ":" + tagRef),
+		},
+		Progress: gitProgressWriter{},
+	}
+	if err := gitConfig.ConfigurePushOptions(pushOptions); err != nil {
+		return fmt.Errorf("failed to configure push options: %w", err)
+	}
+	if err := repo.Push(pushOptions); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push tag %s: %w", name, err)
+	}
+
+	logger.Infof("Pushed tag %s", name)
+	return nil
+}
+
+// fetchRemoteTag fetches the tag tagRef of the remote repository into dst without following other tags.
+// It returns nil if the remote repository does not contain the tag.
+func fetchRemoteTag(repo *git.Repository, repoURL string, tagRef, dst plumbing.ReferenceName, gitConfig *gitconfig.Config) (*plumbing.Reference, error) {
+	fetchOptions := &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RemoteURL:  repoURL,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", tagRef, dst)),
+		},
+		Tags:     git.NoTags,
+		Progress: gitProgressWriter{},
+	}
+	if err := gitConfig.ConfigureFetchOptions(fetchOptions); err != nil {
+		return nil, fmt.Errorf("failed to configure fetch options: %w", err)
+	}
+
+	err := repo.Fetch(fetchOptions)
+	if errors.Is(err, git.NoMatchingRefSpecError{}) {
+		return nil, nil
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+
+	ref, err := repo.Reference(dst, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fetched reference %s: %w", dst, err)
+	}
+	return ref, nil
+}
+
+// createTag creates the annotated tag tagRef on the commit hash.
+func createTag(repo *git.Repository, tagRef plumbing.ReferenceName, message string, hash plumbing.Hash, tagger object.Signature, gitConfig *gitconfig.Config) error {
+	tag := &object.Tag{
+		Name:       tagRef.Short(),
+		Tagger:     tagger,
+		Message:    strings.TrimSpace(message) + "\n",
+		TargetType: plumbing.CommitObject,
+		Target:     hash,
+	}
+	if err := gitConfig.SignTag(tag); err != nil {
+		return err
+	}
+
+	encoded := repo.Storer.NewEncodedObject()
+	if err := tag.Encode(encoded); err != nil {
+		return fmt.Errorf("failed to encode tag %s: %w", tag.Name, err)
+	}
+	tagHash, err := repo.Storer.SetEncodedObject(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to store tag %s: %w", tag.Name, err)
+	}
+
+	if err = repo.Storer.SetReference(plumbing.NewHashReference(tagRef, tagHash)); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", tag.Name, err)
+	}
+	return nil
+}
+
+// tagTarget returns the commit a tag reference points to, which is either an annotated tag object or the commit itself.
+func tagTarget(repo *git.Repository, hash plumbing.Hash) (plumbing.Hash, error) {
+	for {
+		tag, err := repo.TagObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return hash, nil
+		}
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to get tag object %s: %w", hash, err)
+		}
+		hash = tag.Target
+	}
+}
@@ -0,0 +1,80 @@
+package deploymentrepo_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+
+	deploymentrepo "github.com/openmcp-project/bootstrapper/internal/deployment-repo"
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+	testutils "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+func TestTagCommit(t *testing.T) {
+	originDir := t.TempDir()
+	targetDir := t.TempDir()
+
+	origin, err := git.PlainInit(originDir, true)
+	assert.NoError(t, err)
+
+	gitConfig := &gitconfig.Config{}
+	repo, err := deploymentrepo.CloneRepo(originDir, targetDir, gitConfig)
+	assert.NoError(t, err)
+	assert.NoError(t, deploymentrepo.CheckoutAndCreateBranchIfNotExists(repo, testBranchName, gitConfig))
+
+	repoWorkTree, err := repo.Worktree()
+	assert.NoError(t, err)
+	testutils.WriteToFile(t, filepath.Join(targetDir, "test.txt"), "This is a test file.")
+	testutils.AddFileToWorkTree(t, repoWorkTree, "test.txt")
+	assert.NoError(t, deploymentrepo.CommitChanges(repo, "Add test.txt", "Test User", "noreply@test", gitConfig))
+	assert.NoError(t, deploymentrepo.PushRepo(repo, testBranchName, gitConfig))
+
+	head, err := repo.Head()
+	assert.NoError(t, err)
+
+	tagger := object.Signature{Name: "Test User", Email: "noreply@test", When: time.Now()}
+	err = deploymentrepo.TagCommit(repo, "dev/openmcp-v0.0.1", "openMCP environment dev\n\nComponent version v0.0.1", head.Hash(), tagger, gitConfig)
+	assert.NoError(t, err)
+
+	originTagRef, err := origin.Reference(plumbing.NewTagReferenceName("dev/openmcp-v0.0.1"), false)
+	if assert.NoError(t, err) {
+		originTag, err := origin.TagObject(originTagRef.Hash())
+		if assert.NoError(t, err) {
+			assert.Equal(t, head.Hash(), originTag.Target)
+			assert.Equal(t, "openMCP environment dev\n\nComponent version v0.0.1\n", originTag.Message)
+			assert.Equal(t, "Test User", originTag.Tagger.Name)
+		}
+	}
+
+	// an existing tag on the same commit is kept
+	err = deploymentrepo.TagCommit(repo, "dev/openmcp-v0.0.1", "openMCP environment dev", head.Hash(), tagger, gitConfig)
+	assert.NoError(t, err)
+
+	testutils.WriteToFile(t, filepath.Join(targetDir, "test.txt"), "This is a modified test file.")
+	testutils.AddFileToWorkTree(t, repoWorkTree, "test.txt")
+	assert.NoError(t, deploymentrepo.CommitChanges(repo, "Modify test.txt", "Test User", "noreply@test", gitConfig))
+	head, err = repo.Head()
+	assert.NoError(t, err)
+
+	// an existing tag on a different commit is refused
+	err = deploymentrepo.TagCommit(repo, "dev/openmcp-v0.0.1", "openMCP environment dev", head.Hash(), tagger, gitConfig)
+	assert.ErrorContains(t, err, "refusing to move it")
+
+	// a tag that only exists in the remote repository on a different commit is refused
+	originHead, err := origin.Reference(plumbing.NewBranchReferenceName(testBranchName), true)
+	assert.NoError(t, err)
+	_, err = origin.CreateTag("dev/openmcp-v0.0.2", originHead.Hash(), &git.CreateTagOptions{Tagger: &tagger, Message: "created elsewhere"})
+	assert.NoError(t, err)
+	err = deploymentrepo.TagCommit(repo, "dev/openmcp-v0.0.2", "openMCP environment dev", head.Hash(), tagger, gitConfig)
+	assert.ErrorContains(t, err, "remote repository")
+	_, err = repo.Reference(plumbing.NewTagReferenceName("dev/openmcp-v0.0.2"), false)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+
+	err = deploymentrepo.TagCommit(repo, "invalid..tag", "openMCP environment dev", head.Hash(), tagger, gitConfig)
+	assert.Error(t, err)
+}
@@ -15,13 +15,14 @@ import (
 
 	"github.com/openmcp-project/bootstrapper/internal/log"
 	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/template"
 )
 
 // TemplateDir processes the template files in the specified directory and writes
 // the rendered content to the corresponding files in the Git repository's worktree.
 // It uses the provided template directory and Git repository to perform the operations.
-func TemplateDir(ctx context.Context, templateDirectory string, templateInput map[string]interface{}, compGetter *ocmcli.ComponentGetter, repo *git.Repository) error {
+func TemplateDir(ctx context.Context, templateDirectory string, templateInput map[string]interface{}, relocator *relocation.Relocator, compGetter *ocmcli.ComponentGetter, repo *git.Repository) error {
 	logger := log.GetLogger()
 
 	workTree, err := repo.Worktree()
@@ -39,7 +40,7 @@ func TemplateDir(ctx context.Context, templateDirectory string, templateInput ma
 		}
 	}()
 
-	te := template.NewTemplateExecution().WithOCMComponentGetter(ctx, compGetter).WithMissingKeyOption("zero")
+	te := template.NewTemplateExecution().WithOCMComponentGetter(ctx, compGetter).WithImageRelocator(relocator).WithMissingKeyOption("zero")
 
 	// Recursively walk through all files in the template directory
 	err = filepath.WalkDir(templateDirectory, func(path string, d os.DirEntry, walkError error) error {
@@ -113,8 +114,8 @@ func TemplateDir(ctx context.Context, templateDirectory string, templateInput ma
 	return nil
 }
 
-func TemplateString(ctx context.Context, templateName, templateSource string, templateInput map[string]interface{}, compGetter *ocmcli.ComponentGetter) (string, error) {
-	te := template.NewTemplateExecution().WithOCMComponentGetter(ctx, compGetter).WithMissingKeyOption("zero")
+func TemplateString(ctx context.Context, templateName, templateSource string, templateInput map[string]interface{}, relocator *relocation.Relocator, compGetter *ocmcli.ComponentGetter) (string, error) {
+	te := template.NewTemplateExecution().WithOCMComponentGetter(ctx, compGetter).WithImageRelocator(relocator).WithMissingKeyOption("zero")
 
 	wrappedTemplateInput := map[string]interface{}{
 		"Values": templateInput,
@@ -144,8 +145,9 @@ type ProviderOptions struct {
 	Config           map[string]interface{}
 }
 
-// TemplateProviders templates the specified cluster providers, service providers, and platform services
-func TemplateProviders(ctx context.Context, clusterProviders, serviceProviders, platformServices []config.Provider, imagePullSecrets []string, ocmGetter *ocmcli.ComponentGetter, repo *git.Repository) error {
+// TemplateProviders templates the specified cluster providers, service providers, and platform services.
+// The provider images are relocated by the relocator, which may be nil.
+func TemplateProviders(ctx context.Context, clusterProviders, serviceProviders, platformServices []config.Provider, imagePullSecrets []string, relocator *relocation.Relocator, ocmGetter *ocmcli.ComponentGetter, repo *git.Repository) error {
 	basePath := filepath.Join("resources", "openmcp")
 	clusterProvidersDir := filepath.Join(basePath, "cluster-providers")
 	serviceProvidersDir := filepath.Join(basePath, "service-providers")
@@ -173,22 +175,19 @@ func TemplateProviders(ctx context.Context, clusterProviders, serviceProviders,
 	}
 
 	for _, cp := range clusterProviders {
-		componentVersions, err := ocmGetter.GetReferencedComponentVersionsRecursive(ctx, ocmGetter.RootComponentVersion(), "cluster-provider-"+cp.Name)
+		componentVersion, err := getProviderComponentVersion(ctx, ocmGetter, "cluster-provider-", cp)
 		if err != nil {
-			return fmt.Errorf("failed to get component version for cluster provider %s: %w", cp, err)
-		}
-		if len(componentVersions) != 1 {
-			return fmt.Errorf("expected exactly one component version for cluster provider %s, got %d", cp, len(componentVersions))
+			return fmt.Errorf("failed to get component version for cluster provider %s: %w", cp.Name, err)
 		}
 
-		imageResource, err := getImageResource(&componentVersions[0])
+		image, err := getProviderImage(componentVersion, cp, relocator)
 		if err != nil {
-			return fmt.Errorf("failed to get image resource for cluster provider %s: %w", cp, err)
+			return fmt.Errorf("failed to get image for cluster provider %s: %w", cp.Name, err)
 		}
 
 		opts := &ProviderOptions{
 			Name:             cp.Name,
-			Image:            *imageResource.Access.ImageReference,
+			Image:            image,
 			ImagePullSecrets: imagePullSecrets,
 			Config:           cp.ConfigParsed,
 		}
@@ -200,22 +199,19 @@ func TemplateProviders(ctx context.Context, clusterProviders, serviceProviders,
 	}
 
 	for _, sp := range serviceProviders {
-		componentVersions, err := ocmGetter.GetReferencedComponentVersionsRecursive(ctx, ocmGetter.RootComponentVersion(), "service-provider-"+sp.Name)
+		componentVersion, err := getProviderComponentVersion(ctx, ocmGetter, "service-provider-", sp)
 		if err != nil {
-			return fmt.Errorf("failed to get component version for service provider %s: %w", sp, err)
-		}
-		if len(componentVersions) != 1 {
-			return fmt.Errorf("expected exactly one component version for service provider %s, got %d", sp, len(componentVersions))
+			return fmt.Errorf("failed to get component version for service provider %s: %w", sp.Name, err)
 		}
 
-		imageResource, err := getImageResource(&componentVersions[0])
+		image, err := getProviderImage(componentVersion, sp, relocator)
 		if err != nil {
-			return fmt.Errorf("failed to get image resource for service provider %s: %w", sp, err)
+			return fmt.Errorf("failed to get image for service provider %s: %w", sp.Name, err)
 		}
 
 		opts := &ProviderOptions{
 			Name:             sp.Name,
-			Image:            *imageResource.Access.ImageReference,
+			Image:            image,
 			ImagePullSecrets: imagePullSecrets,
 			Config:           sp.ConfigParsed,
 		}
@@ -227,22 +223,19 @@ func TemplateProviders(ctx context.Context, clusterProviders, serviceProviders,
 	}
 
 	for _, ps := range platformServices {
-		componentVersions, err := ocmGetter.GetReferencedComponentVersionsRecursive(ctx, ocmGetter.RootComponentVersion(), "platform-service-"+ps.Name)
+		componentVersion, err := getProviderComponentVersion(ctx, ocmGetter, "platform-service-", ps)
 		if err != nil {
-			return fmt.Errorf("failed to get component version for platform service %s: %w", ps, err)
-		}
-		if len(componentVersions) != 1 {
-			return fmt.Errorf("expected exactly one component version for platform service %s, got %d", ps, len(componentVersions))
+			return fmt.Errorf("failed to get component version for platform service %s: %w", ps.Name, err)
 		}
 
-		imageResource, err := getImageResource(&componentVersions[0])
+		image, err := getProviderImage(componentVersion, ps, relocator)
 		if err != nil {
-			return fmt.Errorf("failed to get image resource for platform service %s: %w", ps, err)
+			return fmt.Errorf("failed to get image for platform service %s: %w", ps.Name, err)
 		}
 
 		opts := &ProviderOptions{
 			Name:             ps.Name,
-			Image:            *imageResource.Access.ImageReference,
+			Image:            image,
 			ImagePullSecrets: imagePullSecrets,
 			Config:           ps.ConfigParsed,
 		}
@@ -266,6 +259,60 @@ func getImageResource(cv *ocmcli.ComponentVersion) (*ocmcli.Resource, error) {
 	return nil, fmt.Errorf("image resource not found for component %s", cv.Component.Name)
 }
 
+// getProviderComponentVersion returns the component version of a provider.
+// By default, this is the component version the root component references as <referencePrefix><name>, e.g. "cluster-provider-kind".
+// The component name and version can be overridden in the provider configuration. If the root component does not reference
+// the provider, both are required.
+func getProviderComponentVersion(ctx context.Context, ocmGetter *ocmcli.ComponentGetter, referencePrefix string, provider config.Provider) (*ocmcli.ComponentVersion, error) {
+	logger := log.GetLogger()
+
+	componentName, version := provider.ComponentName, provider.Version
+	if len(componentName) == 0 || len(version) == 0 {
+		componentVersions, err := ocmGetter.GetReferencedComponentVersionsRecursive(ctx, ocmGetter.RootComponentVersion(), referencePrefix+provider.Name)
+		if err != nil {
+			if len(componentName) > 0 {
+				return nil, fmt.Errorf("version of component %s is required, as the root component does not reference it: %w", componentName, err)
+			}
+			return nil, err
+		}
+		if len(componentVersions) != 1 {
+			return nil, fmt.Errorf("expected exactly one component version for reference %s, got %d", referencePrefix+provider.Name, len(componentVersions))
+		}
+
+		if len(componentName) == 0 && len(version) == 0 {
+			return &componentVersions[0], nil
+		}
+		if len(componentName) == 0 {
+			componentName = componentVersions[0].Component.Name
+		}
+		if len(version) == 0 {
+			version = componentVersions[0].Component.Version
+		}
+	}
+
+	componentVersion, err := ocmGetter.GetComponentVersion(ctx, componentName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Using component version %s:%s for provider %s", componentVersion.Component.Name, componentVersion.Component.Version, provider.Name)
+	return componentVersion, nil
+}
+
+// getProviderImage returns the relocated image of a provider, either the image override of the provider configuration
+// or the image resource of the provider component version.
+func getProviderImage(cv *ocmcli.ComponentVersion, provider config.Provider, relocator *relocation.Relocator) (string, error) {
+	if len(provider.Image) > 0 {
+		return relocator.RelocateImage(provider.Image)
+	}
+
+	imageResource, err := getImageResource(cv)
+	if err != nil {
+		return "", fmt.Errorf("failed to get image resource: %w", err)
+	}
+	return relocator.ResourceImage(imageResource)
+}
+
 func templateProvider(options *ProviderOptions, templateSource, dir string, repo *git.Repository) error {
 	logger := log.GetLogger()
 	providerPath := filepath.Join(dir, options.Name+".yaml")
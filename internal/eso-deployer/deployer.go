@@ -17,6 +17,7 @@ import (
 	cfg "github.com/openmcp-project/bootstrapper/internal/config"
 	"github.com/openmcp-project/bootstrapper/internal/flux_deployer"
 	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/util"
 )
 
@@ -87,7 +88,11 @@ func (d *EsoDeployer) DeployWithComponentManager(ctx context.Context, componentM
 }
 
 func (d *EsoDeployer) deployHelmRelease(ctx context.Context, res *ocmcli.Resource) error {
-	name, tag, _, err := util.ParseImageVersionAndTag(*res.Access.ImageReference)
+	image, err := relocation.NewRelocator(d.Config).ResourceImage(res)
+	if err != nil {
+		return err
+	}
+	name, tag, _, err := util.ParseImageVersionAndTag(image)
 	if err != nil {
 		return fmt.Errorf("failed to parse image resource: %w", err)
 	}
@@ -129,7 +134,11 @@ func (d *EsoDeployer) deployHelmRelease(ctx context.Context, res *ocmcli.Resourc
 }
 
 func (d *EsoDeployer) deployRepo(ctx context.Context, res *ocmcli.Resource, repoName string) error {
-	name, tag, digest, err := util.ParseImageVersionAndTag(*res.Access.ImageReference)
+	image, err := relocation.NewRelocator(d.Config).ResourceImage(res)
+	if err != nil {
+		return err
+	}
+	name, tag, digest, err := util.ParseImageVersionAndTag(image)
 	if err != nil {
 		return err
 	}
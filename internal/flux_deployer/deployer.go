@@ -16,6 +16,7 @@ import (
 
 	cfg "github.com/openmcp-project/bootstrapper/internal/config"
 	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/util"
 )
 
@@ -66,10 +67,16 @@ func (d *FluxDeployer) DeployWithComponentManager(ctx context.Context, component
 		return fmt.Errorf("error creating/updating namespace %s: %w", d.fluxNamespace, err)
 	}
 
-	if err := CreateGitCredentialsSecret(ctx, d.log, d.GitConfigPath, GitSecretName, d.fluxNamespace, d.platformCluster.Client()); err != nil {
+	if err := CreateGitCredentialsSecret(ctx, d.log, d.GitConfigPath, d.Config.DeploymentRepository.RepoURL, GitSecretName, d.fluxNamespace, d.platformCluster.Client()); err != nil {
 		return err
 	}
 
+	if d.Config.ImagePullSecretFromOCMConfig != nil {
+		if err := CreateImagePullSecret(ctx, d.log, d.OcmConfigPath, d.Config.ImagePullSecretFromOCMConfig, d.platformCluster.Client()); err != nil {
+			return err
+		}
+	}
+
 	// Create temporary working directory
 	d.log.Info("Creating working directory for gitops-templates")
 	d.workDir, err = util.CreateTempDir()
@@ -191,27 +198,28 @@ func (d *FluxDeployer) ArrangeTemplates() (err error) {
 func (d *FluxDeployer) Template() (err error) {
 	d.log.Infof("Applying templates from %s to deployment repository", d.Config.Component.FluxcdTemplateResourcePath)
 	templateInput := NewTemplateInputFromConfig(d.Config)
+	relocator := relocation.NewRelocator(d.Config)
 
-	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDSourceControllerResourceName, "sourceController"); err != nil {
+	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDSourceControllerResourceName, "sourceController", relocator); err != nil {
 		return fmt.Errorf("failed to apply fluxcd source controller template input: %w", err)
 	}
-	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDKustomizationControllerResourceName, "kustomizeController"); err != nil {
+	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDKustomizationControllerResourceName, "kustomizeController", relocator); err != nil {
 		return fmt.Errorf("failed to apply fluxcd kustomize controller template input: %w", err)
 	}
-	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDHelmControllerResourceName, "helmController"); err != nil {
+	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDHelmControllerResourceName, "helmController", relocator); err != nil {
 		return fmt.Errorf("failed to apply fluxcd helm controller template input: %w", err)
 	}
-	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDNotificationControllerName, "notificationController"); err != nil {
+	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDNotificationControllerName, "notificationController", relocator); err != nil {
 		return fmt.Errorf("failed to apply fluxcd notification controller template input: %w", err)
 	}
-	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDImageReflectorControllerName, "imageReflectorController"); err != nil {
+	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDImageReflectorControllerName, "imageReflectorController", relocator); err != nil {
 		return fmt.Errorf("failed to apply fluxcd image reflector controller template input: %w", err)
 	}
-	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDImageAutomationControllerName, "imageAutomationController"); err != nil {
+	if err = templateInput.AddImageResource(d.fluxcdCV, FluxCDImageAutomationControllerName, "imageAutomationController", relocator); err != nil {
 		return fmt.Errorf("failed to apply fluxcd image automation controller template input: %w", err)
 	}
 
-	if err = TemplateDirectory(d.templatesDir, d.repoDir, templateInput, d.log); err != nil {
+	if err = TemplateDirectory(d.templatesDir, d.repoDir, templateInput, relocator, d.log); err != nil {
 		return fmt.Errorf("failed to apply templates from directory %s: %w", d.templatesDir, err)
 	}
 
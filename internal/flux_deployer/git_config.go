@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/openmcp-project/controller-utils/pkg/resources"
 	"github.com/sirupsen/logrus"
@@ -20,13 +21,19 @@ const (
 	Identity   = "identity"
 	KnownHosts = "known_hosts"
 	CACert     = "ca.crt"
+
+	GitHubAppID             = "githubAppID"
+	GitHubAppInstallationID = "githubAppInstallationID"
+	GitHubAppPrivateKey     = "githubAppPrivateKey"
+	GitHubAppBaseURL        = "githubAppBaseURL"
 )
 
 // CreateGitCredentialsSecret creates or updates a Secret with name "git" in the fluxcd namespace.
 // The secret contains git credentials for the flux sync, read from the file gitCredentialsPath.
 // The file should contain a YAML of a map[string]string, whose keys are described
 // in https://fluxcd.io/flux/components/source/gitrepositories/#secret-reference, e.g. username and password.
-func CreateGitCredentialsSecret(ctx context.Context, log *logrus.Logger, gitCredentialsPath, secretName, secretNamespace string, platformClient client.Client) error {
+// Credential references of the configuration are resolved for the repository at repoURL.
+func CreateGitCredentialsSecret(ctx context.Context, log *logrus.Logger, gitCredentialsPath, repoURL, secretName, secretNamespace string, platformClient client.Client) error {
 
 	log.Infof("Creating/updating git credentials secret %s/%s", secretNamespace, secretName)
 
@@ -44,6 +51,18 @@ func CreateGitCredentialsSecret(ctx context.Context, log *logrus.Logger, gitCred
 			return fmt.Errorf("error validating git credentials for flux sync: %w", err)
 		}
 
+		log.Debugf("Resolving git credentials")
+		if err = config.ResolveCredentials(repoURL); err != nil {
+			return fmt.Errorf("error resolving git credentials for flux sync: %w", err)
+		}
+
+		if config.Authentication.SSHAgent != nil {
+			return fmt.Errorf("ssh-agent authentication cannot be used for flux sync, as the agent is not available in the cluster: configure an SSH private key instead")
+		}
+		if config.Authentication.SSHPrivateKey != nil && config.Authentication.SSHPrivateKey.HasPassphrase() {
+			return fmt.Errorf("password protected SSH private keys cannot be used for flux sync, as the passphrase would have to be stored next to the key: configure an SSH private key without passphrase instead")
+		}
+
 		if config.Authentication.BasicAuth != nil {
 			log.Debug("Using basic auth credentials for git operations")
 			gitCredentialsData[Username] = []byte(config.Authentication.BasicAuth.Username)
@@ -53,6 +72,20 @@ func CreateGitCredentialsSecret(ctx context.Context, log *logrus.Logger, gitCred
 			log.Debug("Using bearer token for git operations")
 			gitCredentialsData[Token] = []byte(config.Authentication.BearerToken.Token)
 		}
+		if githubApp := config.Authentication.GitHubApp; githubApp != nil {
+			log.Debug("Using GitHub App for git operations")
+			privateKey, err := githubApp.DecodePrivateKey()
+			if err != nil {
+				return err
+			}
+
+			gitCredentialsData[GitHubAppID] = []byte(strconv.FormatInt(githubApp.AppID, 10))
+			gitCredentialsData[GitHubAppInstallationID] = []byte(strconv.FormatInt(githubApp.InstallationID, 10))
+			gitCredentialsData[GitHubAppPrivateKey] = privateKey
+			if githubApp.BaseURL != "" {
+				gitCredentialsData[GitHubAppBaseURL] = []byte(githubApp.BaseURL)
+			}
+		}
 		if config.Authentication.SSHPrivateKey != nil {
 			log.Debug("Using ssh private key for git operations")
 			privateKey, err := config.Authentication.SSHPrivateKey.DecodePrivateKey()
@@ -62,6 +95,10 @@ func CreateGitCredentialsSecret(ctx context.Context, log *logrus.Logger, gitCred
 
 			gitCredentialsData[Identity] = privateKey
 
+			if config.Authentication.SSHPrivateKey.InsecureIgnoreHostKey {
+				log.Warn("insecureIgnoreHostKey only applies to the git operations of the bootstrapper, Flux verifies the host key with the known hosts of the secret")
+			}
+
 			if config.Authentication.SSHPrivateKey.KnownHosts != "" {
 				knownHostsPath := config.Authentication.SSHPrivateKey.KnownHosts
 				if _, err := os.Stat(knownHostsPath); os.IsNotExist(err) {
@@ -14,16 +14,20 @@ import (
 
 func TestCreateGitCredentialsSecret(t *testing.T) {
 	platformClient := fake.NewClientBuilder().Build()
+	t.Setenv("TEST_GIT_USERNAME", "env-user")
+	t.Setenv("TEST_GIT_PASSWORD", "env-pass")
 
 	testCases := []struct {
 		desc          string
 		gitConfigPath string
+		repoURL       string
 		secretName    string
 		expectedData  map[string][]byte
 	}{
 		{
 			desc:          "Git secret with basic auth credentials",
 			gitConfigPath: "./testdata/02/git-config-basic.yaml",
+			repoURL:       "https://github.com/openmcp-project/bootstrapper.git",
 			secretName:    "test-secret-basic",
 			expectedData: map[string][]byte{
 				flux_deployer.Username: []byte("test-user"),
@@ -33,6 +37,7 @@ func TestCreateGitCredentialsSecret(t *testing.T) {
 		{
 			desc:          "Git secret with basic ssh credentials",
 			gitConfigPath: "./testdata/02/git-config-ssh.yaml",
+			repoURL:       "ssh://git@github.com/openmcp-project/bootstrapper.git",
 			secretName:    "test-secret-ssh",
 			expectedData: map[string][]byte{
 				flux_deployer.Identity:   []byte("test-key"),
@@ -42,6 +47,7 @@ func TestCreateGitCredentialsSecret(t *testing.T) {
 		{
 			desc:          "Git secret with basic auth and CA bundle",
 			gitConfigPath: "./testdata/02/git-config-basic-with-ca.yaml",
+			repoURL:       "https://github.com/openmcp-project/bootstrapper.git",
 			secretName:    "test-secret-basic-ca",
 			expectedData: map[string][]byte{
 				flux_deployer.Username: []byte("test-user"),
@@ -49,11 +55,72 @@ func TestCreateGitCredentialsSecret(t *testing.T) {
 				flux_deployer.CACert:   []byte("-----BEGIN CERTIFICATE-----\nMIIDETCCAfmgAwIBAgIUHI87wIw1K6ujI4fL+D8dyoFGkKEwDQYJKoZIhvcNAQEL\nBQAwGDEWMBQGA1UEAwwNTestIFJvb3QgQ0EwHhcNMjQwMTAxMDAwMDAwWhcNMjUw\nMTAxMDAwMDAwWjAYMRYwFAYDVQQDDA1UZXN0IFJvb3QgQ0EwggEiMA0GCSqGSIb3\nDQEBAQUAA4IBDwAwggEKAoIBAQDEqkv2tkuHZqfFNXHrUnBvxqiZKJbqpWK3q17t\n7poq/tWRZg3TqfAqZIP7dDqEtPslQjFoNHu6Aq3h5Yw9v1NMB7tWxLVwCN4GHvqI\nDaoQBQpn3jFpE7GPKAF8Vh2zAeBjSSd7PvE4QKaovF37SWN5cOvqYHgUSZdOICSl\np7QiueVAhxANn6vi5EhAcas9hotQVR0c/XJfkq8t6MSvMcJdOZA0r7pb09D9piZ0\ntFC7KY8SNAXKvwgLqIOIKZtPglXawA7bKpGVtbalzF7LxdRqq/Q6xqzLCDdS8Z43\n6Pkh2p7iu7WYK4HXhiwcJ3HZkJZwcXzK6+KtK+V+8bbvXAgMBAAGjUzBRMB0GA1Ud\nDgQWBBSr46WEDePg6xrf4PSpJ9oNQeExOzAfBgNVHSMEGDAWgBSr46WEDePg6xrf\n4PSpJ9oNQeExOzAPBgNVHRMBAf8EBTADAQH/MA0GCSqGSIb3DQEBCwUAA4IBAQAB\nfQdFERsB7IKDkU6KimQrPv9075m4TPVTcv2SxhjNXimdF5q67K0+BHKTDLOQIsDv\nh3jFtKe3PUDLV0bP3V0B0Xs0CDTZPsygJwsifmFqWgqKBE4pfr4Flbjf1B0D9TlD\nPBaseyhPJIjPPVekVN3zzE5rPN5O9RY/Bsy3Cr8gvWi40ZFuWrn0ue9P7yiiBfyh\nLM7SiHJOpS8EWYtpFNZryUbzdV4/YqKRYKUX7VD2QYLZ7CAu3ok/i2fzqaLmdCQc\nBg9AHK3fVs7LHQkNeuWQ9cQypoZW7YNPkpZdt47AFzBiQnUbNibug4SfW1ZjGec0\ndQURJgLQ9tNdg4A=\n-----END CERTIFICATE-----\n"),
 			},
 		},
+		{
+			desc:          "Git secret with GitHub App",
+			gitConfigPath: "./testdata/02/git-config-github-app.yaml",
+			repoURL:       "https://github.example.com/openmcp-project/bootstrapper.git",
+			secretName:    "test-secret-github-app",
+			expectedData: map[string][]byte{
+				flux_deployer.GitHubAppID:             []byte("123"),
+				flux_deployer.GitHubAppInstallationID: []byte("456"),
+				flux_deployer.GitHubAppPrivateKey:     []byte("test-key"),
+				flux_deployer.GitHubAppBaseURL:        []byte("https://github.example.com/api/v3"),
+			},
+		},
+		{
+			desc:          "Git secret with basic auth credentials from environment variables",
+			gitConfigPath: "./testdata/02/git-config-basic-env.yaml",
+			repoURL:       "https://github.com/openmcp-project/bootstrapper.git",
+			secretName:    "test-secret-basic-env",
+			expectedData: map[string][]byte{
+				flux_deployer.Username: []byte("env-user"),
+				flux_deployer.Password: []byte("env-pass"),
+			},
+		},
+		{
+			desc:          "Git secret with bearer token from file",
+			gitConfigPath: "./testdata/02/git-config-token-file.yaml",
+			repoURL:       "https://github.com/openmcp-project/bootstrapper.git",
+			secretName:    "test-secret-token-file",
+			expectedData: map[string][]byte{
+				flux_deployer.Token: []byte("test-token"),
+			},
+		},
+		{
+			desc:          "Git secret with basic auth credentials from netrc",
+			gitConfigPath: "./testdata/02/git-config-netrc.yaml",
+			repoURL:       "https://github.com/openmcp-project/bootstrapper.git",
+			secretName:    "test-secret-netrc",
+			expectedData: map[string][]byte{
+				flux_deployer.Username: []byte("netrc-user"),
+				flux_deployer.Password: []byte("netrc-pass"),
+			},
+		},
+		{
+			desc:          "Git secret with basic auth credentials from default netrc entry",
+			gitConfigPath: "./testdata/02/git-config-netrc.yaml",
+			repoURL:       "https://git.example.com/openmcp-project/bootstrapper.git",
+			secretName:    "test-secret-netrc-default",
+			expectedData: map[string][]byte{
+				flux_deployer.Username: []byte("default-user"),
+				flux_deployer.Password: []byte("default-pass"),
+			},
+		},
+		{
+			desc:          "Git secret with basic auth credentials from credential helper",
+			gitConfigPath: "./testdata/02/git-config-credential-helper.yaml",
+			repoURL:       "https://github.com/openmcp-project/bootstrapper.git",
+			secretName:    "test-secret-credential-helper",
+			expectedData: map[string][]byte{
+				flux_deployer.Username: []byte("helper-user"),
+				flux_deployer.Password: []byte("helper-pass"),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			err := flux_deployer.CreateGitCredentialsSecret(t.Context(), logging.GetLogger(), tc.gitConfigPath, tc.secretName, flux_deployer.FluxSystemNamespace, platformClient)
+			err := flux_deployer.CreateGitCredentialsSecret(t.Context(), logging.GetLogger(), tc.gitConfigPath, tc.repoURL, tc.secretName, flux_deployer.FluxSystemNamespace, platformClient)
 			assert.NoError(t, err, "Error creating git credentials secret")
 			secret := &corev1.Secret{}
 			err = platformClient.Get(t.Context(), client.ObjectKey{Name: tc.secretName, Namespace: flux_deployer.FluxSystemNamespace}, secret)
@@ -62,3 +129,31 @@ func TestCreateGitCredentialsSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateGitCredentialsSecretWithLocalSSHAuth(t *testing.T) {
+	platformClient := fake.NewClientBuilder().Build()
+
+	testCases := []struct {
+		desc          string
+		gitConfigPath string
+	}{
+		{
+			desc:          "Git secret with password protected ssh key",
+			gitConfigPath: "./testdata/02/git-config-ssh-passphrase.yaml",
+		},
+		{
+			desc:          "Git secret with ssh agent",
+			gitConfigPath: "./testdata/02/git-config-ssh-agent.yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := flux_deployer.CreateGitCredentialsSecret(t.Context(), logging.GetLogger(), tc.gitConfigPath, "ssh://git@github.com/openmcp-project/bootstrapper.git", "test-secret", flux_deployer.FluxSystemNamespace, platformClient)
+			assert.Error(t, err, "Expected error creating git credentials secret")
+			secret := &corev1.Secret{}
+			err = platformClient.Get(t.Context(), client.ObjectKey{Name: "test-secret", Namespace: flux_deployer.FluxSystemNamespace}, secret)
+			assert.Error(t, err, "Expected no git credentials secret")
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package flux_deployer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openmcp-project/controller-utils/pkg/resources"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// CreateImagePullSecret creates or updates an image pull secret of type kubernetes.io/dockerconfigjson in each of the configured namespaces.
+// The secret contains the credentials of the configured registries, read from the OCM configuration file ocmConfigPath,
+// e.g. from the docker config file of a DockerConfig repository.
+func CreateImagePullSecret(ctx context.Context, log *logrus.Logger, ocmConfigPath string, secretConfig *config.ImagePullSecretFromOCMConfig, platformClient client.Client) error {
+	if ocmConfigPath == ocmcli.NoOcmConfig {
+		return fmt.Errorf("an OCM configuration is required to create the image pull secret %s", secretConfig.Name)
+	}
+
+	log.Debugf("Reading credentials of registries %v from OCM configuration %s", secretConfig.Registries, ocmConfigPath)
+	dockerConfigJSON, err := ocmcli.DockerConfigJSON(ocmConfigPath, secretConfig.Registries)
+	if err != nil {
+		return fmt.Errorf("error reading registry credentials for image pull secret %s: %w", secretConfig.Name, err)
+	}
+
+	for _, namespace := range secretConfig.Namespaces {
+		log.Infof("Creating/updating image pull secret %s/%s", namespace, secretConfig.Name)
+
+		namespaceMutator := resources.NewNamespaceMutator(namespace)
+		if err := resources.CreateOrUpdateResource(ctx, platformClient, namespaceMutator); err != nil {
+			return fmt.Errorf("error creating/updating namespace %s: %w", namespace, err)
+		}
+
+		secretMutator := resources.NewSecretMutator(secretConfig.Name, namespace, map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON}, corev1.SecretTypeDockerConfigJson)
+		if err := resources.CreateOrUpdateResource(ctx, platformClient, secretMutator); err != nil {
+			return fmt.Errorf("error creating or updating image pull secret %s/%s: %w", namespace, secretConfig.Name, err)
+		}
+	}
+
+	return nil
+}
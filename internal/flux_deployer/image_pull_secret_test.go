@@ -0,0 +1,50 @@
+package flux_deployer_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	"github.com/openmcp-project/bootstrapper/internal/flux_deployer"
+	logging "github.com/openmcp-project/bootstrapper/internal/log"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+func TestCreateImagePullSecret(t *testing.T) {
+	platformClient := fake.NewClientBuilder().Build()
+
+	secretConfig := &config.ImagePullSecretFromOCMConfig{
+		Name:       "registry-credentials",
+		Registries: []string{"ghcr.io"},
+		Namespaces: []string{flux_deployer.FluxSystemNamespace, "openmcp-system"},
+	}
+
+	err := flux_deployer.CreateImagePullSecret(t.Context(), logging.GetLogger(), "./testdata/04/ocm-config.yaml", secretConfig, platformClient)
+	if !assert.NoError(t, err, "Error creating image pull secret") {
+		return
+	}
+
+	for _, namespace := range secretConfig.Namespaces {
+		secret := &corev1.Secret{}
+		err = platformClient.Get(t.Context(), client.ObjectKey{Name: secretConfig.Name, Namespace: namespace}, secret)
+		if !assert.NoError(t, err, "Error getting image pull secret") {
+			continue
+		}
+		assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+
+		var dockerConfig struct {
+			Auths map[string]map[string]string `json:"auths"`
+		}
+		assert.NoError(t, json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &dockerConfig))
+		assert.Equal(t, "docker-user", dockerConfig.Auths["ghcr.io"]["username"])
+		assert.Equal(t, "docker-token", dockerConfig.Auths["ghcr.io"]["password"])
+	}
+
+	err = flux_deployer.CreateImagePullSecret(t.Context(), logging.GetLogger(), ocmcli.NoOcmConfig, secretConfig, platformClient)
+	assert.Error(t, err, "Expected error without OCM configuration")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/openmcp-project/bootstrapper/internal/config"
 	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/util"
 )
 
@@ -29,14 +30,20 @@ func NewTemplateInputFromConfig(c *config.BootstrapperConfig) TemplateInput {
 	return t
 }
 
-func (t TemplateInput) AddImageResource(cv *ocmcli.ComponentVersion, resourceName, key string) error {
+// AddImageResource adds the image of the given resource under the given key to the images of the template input.
+// The image reference is relocated by the relocator, which may be nil.
+func (t TemplateInput) AddImageResource(cv *ocmcli.ComponentVersion, resourceName, key string, relocator *relocation.Relocator) error {
 	resource, err := cv.GetResource(resourceName)
 	if err != nil {
 		return fmt.Errorf("failed to get resource %s: %w", resourceName, err)
 	}
-	imageName, imageTag, imageDigest, err := util.ParseImageVersionAndTag(*resource.Access.ImageReference)
+	image, err := relocator.ResourceImage(resource)
 	if err != nil {
-		return fmt.Errorf("failed to parse image reference %s: %w", *resource.Access.ImageReference, err)
+		return err
+	}
+	imageName, imageTag, imageDigest, err := util.ParseImageVersionAndTag(image)
+	if err != nil {
+		return fmt.Errorf("failed to parse image reference %s: %w", image, err)
 	}
 
 	if _, found := t["images"]; !found {
@@ -72,7 +72,7 @@ func TestTemplateInput_AddImageResource(t *testing.T) {
 	}
 
 	ti := flux_deployer.TemplateInput{}
-	err := ti.AddImageResource(cv, "test-resource", "testKey")
+	err := ti.AddImageResource(cv, "test-resource", "testKey", nil)
 	assert.NoError(t, err, "Expected no error adding image resource")
 	images, ok := ti["images"].(map[string]any)
 	assert.True(t, ok, "Expected images to be a map")
@@ -7,12 +7,14 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/template"
 )
 
 // TemplateDirectory processes the template files in the specified directory and writes
 // the rendered content to the corresponding files in the result directory.
-func TemplateDirectory(templateDirectory, resultDirectory string, templateInput TemplateInput, log *logrus.Logger) error {
+// Images parsed in the templates are relocated by the relocator, which may be nil.
+func TemplateDirectory(templateDirectory, resultDirectory string, templateInput TemplateInput, relocator *relocation.Relocator, log *logrus.Logger) error {
 	log.Debug("Templating")
 
 	templateDir, err := os.Open(templateDirectory)
@@ -25,7 +27,7 @@ func TemplateDirectory(templateDirectory, resultDirectory string, templateInput
 		}
 	}()
 
-	te := template.NewTemplateExecution().WithMissingKeyOption("zero")
+	te := template.NewTemplateExecution().WithImageRelocator(relocator).WithMissingKeyOption("zero")
 
 	// Recursively walk through all files in the template directory
 	err = filepath.WalkDir(templateDirectory, func(path string, d os.DirEntry, walkError error) error {
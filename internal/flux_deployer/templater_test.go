@@ -18,7 +18,7 @@ func TestTemplateDirectory(t *testing.T) {
 		"test2": "bar",
 	}
 	resultDir := t.TempDir()
-	err := flux_deployer.TemplateDirectory("./testdata/03/templates", resultDir, ti, logging.GetLogger())
+	err := flux_deployer.TemplateDirectory("./testdata/03/templates", resultDir, ti, nil, logging.GetLogger())
 	assert.NoError(t, err, "error templating directory")
 
 	contentA, err := os.ReadFile(path.Join(resultDir, "a.yaml"))
@@ -0,0 +1,161 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+const (
+	// GitHub is the forge type of GitHub and GitHub Enterprise Server.
+	GitHub = "github"
+	// GitLab is the forge type of GitLab.
+	GitLab = "gitlab"
+
+	gitHubHost   = "github.com"
+	gitHubAPIURL = "https://api.github.com"
+
+	// maxErrorBodySize limits the size of the response body included in API errors.
+	maxErrorBodySize = 4096
+	// apiTimeout is the timeout of the requests to the forge API.
+	apiTimeout = 30 * time.Second
+)
+
+// Kinds are the supported forge types.
+var Kinds = []string{GitHub, GitLab}
+
+// Forge opens pull requests through the API of a git server.
+type Forge interface {
+	// CreatePullRequest opens a pull request, or merge request in GitLab terms, and returns its URL.
+	CreatePullRequest(ctx context.Context, pr PullRequest) (string, error)
+}
+
+// PullRequest describes a pull request to be opened.
+type PullRequest struct {
+	// Title is the title of the pull request.
+	Title string
+	// Description is the markdown description of the pull request.
+	Description string
+	// SourceBranch is the branch containing the changes.
+	SourceBranch string
+	// TargetBranch is the branch the changes are merged into.
+	TargetBranch string
+}
+
+// New creates the Forge of the given kind for the repository, e.g. "openmcp-project/deployment".
+// The API at apiURL is called with httpClient and authenticated with the token.
+func New(kind, apiURL, repository, token string, httpClient *http.Client) (Forge, error) {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	client := &apiClient{httpClient: httpClient}
+
+	switch kind {
+	case GitHub:
+		return &gitHubForge{client: client, apiURL: apiURL, repository: repository, token: token}, nil
+	case GitLab:
+		return &gitLabForge{client: client, apiURL: apiURL, repository: repository, token: token}, nil
+	default:
+		return nil, fmt.Errorf("unsupported forge %q, must be one of %s", kind, strings.Join(Kinds, ", "))
+	}
+}
+
+// NewHTTPClient returns the HTTP client of the forge API, which trusts the PEM encoded caBundle in addition to the system CAs.
+func NewHTTPClient(caBundle []byte) (*http.Client, error) {
+	client, err := util.NewHTTPClient(caBundle, apiTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create forge API client: %w", err)
+	}
+	return client, nil
+}
+
+// DefaultAPIURL returns the API URL of the forge hosting the repository at repoURL.
+// It is "https://api.github.com" for github.com, "<host>/api/v3" for GitHub Enterprise Server and "<host>/api/v4" for GitLab.
+func DefaultAPIURL(kind, repoURL string) (string, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URL %s: %w", repoURL, err)
+	}
+
+	baseURL := "https://" + endpoint.Host
+	if endpoint.Protocol == "http" || endpoint.Protocol == "https" {
+		baseURL = endpoint.Protocol + "://" + endpoint.Host
+		if endpoint.Port != 0 {
+			baseURL = fmt.Sprintf("%s:%d", baseURL, endpoint.Port)
+		}
+	}
+
+	switch kind {
+	case GitHub:
+		if endpoint.Host == gitHubHost {
+			return gitHubAPIURL, nil
+		}
+		return baseURL + "/api/v3", nil
+	case GitLab:
+		return baseURL + "/api/v4", nil
+	default:
+		return "", fmt.Errorf("unsupported forge %q, must be one of %s", kind, strings.Join(Kinds, ", "))
+	}
+}
+
+// RepositoryFromURL returns the repository path of the repository URL,
+// e.g. "openmcp-project/deployment" for "git@github.com:openmcp-project/deployment.git".
+func RepositoryFromURL(repoURL string) (string, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URL %s: %w", repoURL, err)
+	}
+
+	repository := strings.TrimSuffix(strings.Trim(endpoint.Path, "/"), ".git")
+	if !strings.Contains(repository, "/") {
+		return "", fmt.Errorf("repository URL %s does not contain a repository path", repoURL)
+	}
+	return repository, nil
+}
+
+// apiClient sends the JSON requests of the forges.
+type apiClient struct {
+	httpClient *http.Client
+}
+
+// postJSON sends the request body as JSON to the url and decodes the JSON response into result.
+func (c *apiClient) postJSON(ctx context.Context, url string, headers map[string]string, body, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errorBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("request to %s failed with status %s: %s", url, resp.Status, strings.TrimSpace(string(errorBody)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package forge_test
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openmcp-project/bootstrapper/internal/forge"
+)
+
+const testToken = "test-token"
+
+var testPullRequest = forge.PullRequest{
+	Title:        "Update openMCP",
+	Description:  "Updates the component version",
+	SourceBranch: "openmcp-bootstrapper/dev-v0.0.2",
+	TargetBranch: "main",
+}
+
+func TestGitHubCreatePullRequest(t *testing.T) {
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/repos/openmcp-project/deployment/pulls", r.URL.Path)
+		assert.Equal(t, "Bearer "+testToken, r.Header.Get("Authorization"))
+		assert.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 42, "html_url": "https://github.com/openmcp-project/deployment/pull/42"}`))
+	}))
+	defer server.Close()
+
+	f, err := forge.New(forge.GitHub, server.URL+"/", "openmcp-project/deployment", testToken, server.Client())
+	assert.NoError(t, err)
+
+	prURL, err := f.CreatePullRequest(t.Context(), testPullRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/openmcp-project/deployment/pull/42", prURL)
+	assert.Equal(t, map[string]any{
+		"title": testPullRequest.Title,
+		"body":  testPullRequest.Description,
+		"head":  testPullRequest.SourceBranch,
+		"base":  testPullRequest.TargetBranch,
+	}, request)
+}
+
+func TestGitLabCreatePullRequest(t *testing.T) {
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v4/projects/openmcp%2Fdeployment/merge_requests", r.URL.EscapedPath())
+		assert.Equal(t, testToken, r.Header.Get("PRIVATE-TOKEN"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"iid": 7, "web_url": "https://gitlab.example.com/openmcp/deployment/-/merge_requests/7"}`))
+	}))
+	defer server.Close()
+
+	f, err := forge.New(forge.GitLab, server.URL+"/api/v4", "openmcp/deployment", testToken, server.Client())
+	assert.NoError(t, err)
+
+	prURL, err := f.CreatePullRequest(t.Context(), testPullRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://gitlab.example.com/openmcp/deployment/-/merge_requests/7", prURL)
+	assert.Equal(t, map[string]any{
+		"title":                testPullRequest.Title,
+		"description":          testPullRequest.Description,
+		"source_branch":        testPullRequest.SourceBranch,
+		"target_branch":        testPullRequest.TargetBranch,
+		"remove_source_branch": true,
+	}, request)
+}
+
+func TestCreatePullRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message": "A pull request already exists"}`))
+	}))
+	defer server.Close()
+
+	f, err := forge.New(forge.GitHub, server.URL, "openmcp-project/deployment", testToken, server.Client())
+	assert.NoError(t, err)
+
+	_, err = f.CreatePullRequest(t.Context(), testPullRequest)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "A pull request already exists")
+	}
+
+	_, err = forge.New("bitbucket", server.URL, "openmcp-project/deployment", testToken, server.Client())
+	assert.Error(t, err)
+}
+
+func TestCreatePullRequestWithCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 1, "html_url": "https://github.example.com/openmcp/deployment/pull/1"}`))
+	}))
+	defer server.Close()
+
+	// Without the CA certificate of the server, the request fails.
+	httpClient, err := forge.NewHTTPClient(nil)
+	assert.NoError(t, err)
+	f, err := forge.New(forge.GitHub, server.URL, "openmcp/deployment", testToken, httpClient)
+	assert.NoError(t, err)
+	_, err = f.CreatePullRequest(t.Context(), testPullRequest)
+	assert.Error(t, err)
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	httpClient, err = forge.NewHTTPClient(caCert)
+	assert.NoError(t, err)
+	f, err = forge.New(forge.GitHub, server.URL, "openmcp/deployment", testToken, httpClient)
+	assert.NoError(t, err)
+	prURL, err := f.CreatePullRequest(t.Context(), testPullRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/openmcp/deployment/pull/1", prURL)
+
+	_, err = forge.NewHTTPClient([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestDefaultAPIURL(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		kind     string
+		repoURL  string
+		expected string
+	}{
+		{
+			desc:     "github.com via https",
+			kind:     forge.GitHub,
+			repoURL:  "https://github.com/openmcp-project/deployment.git",
+			expected: "https://api.github.com",
+		},
+		{
+			desc:     "github.com via ssh",
+			kind:     forge.GitHub,
+			repoURL:  "git@github.com:openmcp-project/deployment.git",
+			expected: "https://api.github.com",
+		},
+		{
+			desc:     "GitHub Enterprise Server",
+			kind:     forge.GitHub,
+			repoURL:  "https://github.example.com/openmcp/deployment",
+			expected: "https://github.example.com/api/v3",
+		},
+		{
+			desc:     "GitLab with port",
+			kind:     forge.GitLab,
+			repoURL:  "http://gitlab.example.com:8080/openmcp/deployment.git",
+			expected: "http://gitlab.example.com:8080/api/v4",
+		},
+		{
+			desc:     "GitLab via ssh",
+			kind:     forge.GitLab,
+			repoURL:  "ssh://git@gitlab.example.com:2222/openmcp/deployment.git",
+			expected: "https://gitlab.example.com/api/v4",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			apiURL, err := forge.DefaultAPIURL(tc.kind, tc.repoURL)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, apiURL)
+		})
+	}
+}
+
+func TestRepositoryFromURL(t *testing.T) {
+	testCases := []struct {
+		repoURL     string
+		expected    string
+		expectError bool
+	}{
+		{repoURL: "https://github.com/openmcp-project/deployment.git", expected: "openmcp-project/deployment"},
+		{repoURL: "git@github.com:openmcp-project/deployment.git", expected: "openmcp-project/deployment"},
+		{repoURL: "https://gitlab.example.com/group/subgroup/deployment", expected: "group/subgroup/deployment"},
+		{repoURL: "https://github.com/deployment", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.repoURL, func(t *testing.T) {
+			repository, err := forge.RepositoryFromURL(tc.repoURL)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, repository)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const gitHubAPIVersion = "2022-11-28"
+
+// gitHubForge opens pull requests through the GitHub REST API.
+type gitHubForge struct {
+	client     *apiClient
+	apiURL     string
+	repository string
+	token      string
+}
+
+type gitHubPullRequest struct {
+	Title string `json:"title"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Body  string `json:"body"`
+}
+
+type gitHubPullRequestResponse struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// CreatePullRequest opens a pull request of the source branch against the target branch.
+func (f *gitHubForge) CreatePullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	log.GetLogger().Debugf("Opening GitHub pull request %s -> %s in %s", pr.SourceBranch, pr.TargetBranch, f.repository)
+
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"Authorization":        "Bearer " + f.token,
+		"X-GitHub-Api-Version": gitHubAPIVersion,
+	}
+	request := gitHubPullRequest{
+		Title: pr.Title,
+		Head:  pr.SourceBranch,
+		Base:  pr.TargetBranch,
+		Body:  pr.Description,
+	}
+
+	var response gitHubPullRequestResponse
+	if err := f.client.postJSON(ctx, fmt.Sprintf("%s/repos/%s/pulls", f.apiURL, f.repository), headers, request, &response); err != nil {
+		return "", fmt.Errorf("failed to create GitHub pull request: %w", err)
+	}
+	return response.HTMLURL, nil
+}
@@ -0,0 +1,53 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+// gitLabForge opens merge requests through the GitLab REST API.
+type gitLabForge struct {
+	client     *apiClient
+	apiURL     string
+	repository string
+	token      string
+}
+
+type gitLabMergeRequest struct {
+	SourceBranch       string `json:"source_branch"`
+	TargetBranch       string `json:"target_branch"`
+	Title              string `json:"title"`
+	Description        string `json:"description"`
+	RemoveSourceBranch bool   `json:"remove_source_branch"`
+}
+
+type gitLabMergeRequestResponse struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
+// CreatePullRequest opens a merge request of the source branch into the target branch.
+// The source branch is removed when the merge request is merged.
+func (f *gitLabForge) CreatePullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	log.GetLogger().Debugf("Opening GitLab merge request %s -> %s in %s", pr.SourceBranch, pr.TargetBranch, f.repository)
+
+	headers := map[string]string{
+		"PRIVATE-TOKEN": f.token,
+	}
+	request := gitLabMergeRequest{
+		SourceBranch:       pr.SourceBranch,
+		TargetBranch:       pr.TargetBranch,
+		Title:              pr.Title,
+		Description:        pr.Description,
+		RemoveSourceBranch: true,
+	}
+
+	var response gitLabMergeRequestResponse
+	if err := f.client.postJSON(ctx, fmt.Sprintf("%s/projects/%s/merge_requests", f.apiURL, url.PathEscape(f.repository)), headers, request, &response); err != nil {
+		return "", fmt.Errorf("failed to create GitLab merge request: %w", err)
+	}
+	return response.WebURL, nil
+}
@@ -3,13 +3,28 @@ package gitconfig
 import (
 	"encoding/base64"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
 	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const (
+	// defaultSSHUser is the SSH user of git servers like GitHub and GitLab.
+	defaultSSHUser = "git"
+	// defaultSSHPort is the port of SSH repository URLs without explicit port.
+	defaultSSHPort = 22
 )
 
 // Config represents the configuration for git operations.
@@ -17,6 +32,8 @@ type Config struct {
 	Authentication Authentication `json:"auth,omitempty"`
 	// TLSCACert is the base64 encoded custom CA certificate for TLS verification.
 	TLSCACert string `json:"tlsCACert,omitempty"`
+	// Signing configures the signing of the commits created by the bootstrapper. Nil creates unsigned commits.
+	Signing *Signing `json:"signing,omitempty"`
 }
 
 // Authentication holds the authentication methods for git operations.
@@ -24,14 +41,27 @@ type Authentication struct {
 	BasicAuth     *BasicAuth     `json:"basic,omitempty"`
 	BearerToken   *BearerToken   `json:"bearerToken,omitempty"`
 	SSHPrivateKey *SSHPrivateKey `json:"sshPrivateKey,omitempty"`
+	SSHAgent      *SSHAgent      `json:"sshAgent,omitempty"`
+	// Netrc reads the basic authentication credentials of the repository host from a netrc file.
+	Netrc *Netrc `json:"netrc,omitempty"`
+	// CredentialHelper gets the basic authentication credentials of the repository from a git credential helper.
+	CredentialHelper *CredentialHelper `json:"credentialHelper,omitempty"`
+	// GitHubApp authenticates with an installation token of a GitHub App.
+	GitHubApp *GitHubApp `json:"githubApp,omitempty"`
 }
 
 // BasicAuth represents basic authentication credentials.
 type BasicAuth struct {
 	// Username is the username for basic authentication.
 	Username string `json:"username,omitempty"`
+	// UsernameFromEnv is the name of the environment variable containing the username.
+	UsernameFromEnv string `json:"usernameFromEnv,omitempty"`
 	// Password is the password for basic authentication.
 	Password string `json:"password,omitempty"`
+	// PasswordFromEnv is the name of the environment variable containing the password, e.g. "GIT_TOKEN".
+	PasswordFromEnv string `json:"passwordFromEnv,omitempty"`
+	// PasswordFromFile is the path to the file containing the password.
+	PasswordFromFile string `json:"passwordFromFile,omitempty"`
 }
 
 // BearerToken represents a bearer token for authentication.
@@ -39,15 +69,87 @@ type BasicAuth struct {
 type BearerToken struct {
 	// Token is the bearer token used for authentication.
 	Token string `json:"token,omitempty"`
+	// TokenFromEnv is the name of the environment variable containing the bearer token.
+	TokenFromEnv string `json:"tokenFromEnv,omitempty"`
+	// TokenFromFile is the path to the file containing the bearer token.
+	TokenFromFile string `json:"tokenFromFile,omitempty"`
 }
 
 // SSHPrivateKey represents an SSH private key for authentication.
 type SSHPrivateKey struct {
 	// PrivateKey is the base64 encoded SSH private key.
-	// Password protected keys are not yet supported.
 	PrivateKey string `json:"privateKey,omitempty"`
+	// Passphrase is the passphrase of a password protected private key.
+	Passphrase string `json:"passphrase,omitempty"`
+	// PassphraseFromEnv is the name of the environment variable containing the passphrase of a password protected private key.
+	PassphraseFromEnv string `json:"passphraseFromEnv,omitempty"`
+	// PassphraseFromFile is the path to the file containing the passphrase of a password protected private key.
+	PassphraseFromFile string `json:"passphraseFromFile,omitempty"`
 	// KnownHosts is the path to the known hosts file for SSH.
+	// It is used by Flux and by the git operations of the bootstrapper to verify the host key of the git server.
+	KnownHosts string `json:"knownHosts,omitempty"`
+	// InsecureIgnoreHostKey disables the host key verification of the git operations of the bootstrapper.
+	InsecureIgnoreHostKey bool `json:"insecureIgnoreHostKey,omitempty"`
+}
+
+// SSHAgent represents authentication with the keys of the running ssh-agent.
+// The agent is reached via the socket in the SSH_AUTH_SOCK environment variable.
+type SSHAgent struct {
+	// User is the SSH user. Defaults to "git".
+	User string `json:"user,omitempty"`
+	// KnownHosts is the path to the known hosts file used to verify the host key of the git server.
 	KnownHosts string `json:"knownHosts,omitempty"`
+	// InsecureIgnoreHostKey disables the host key verification.
+	InsecureIgnoreHostKey bool `json:"insecureIgnoreHostKey,omitempty"`
+}
+
+// HasPassphrase returns true if a passphrase is configured for the private key.
+func (s *SSHPrivateKey) HasPassphrase() bool {
+	return s.Passphrase != "" || s.PassphraseFromEnv != "" || s.PassphraseFromFile != ""
+}
+
+// ResolvePassphrase returns the passphrase of the private key, read from the configured environment variable or file if necessary.
+// It returns an empty passphrase if none is configured.
+func (s *SSHPrivateKey) ResolvePassphrase() (string, error) {
+	return resolvePassphrase(s.Passphrase, s.PassphraseFromEnv, s.PassphraseFromFile, "SSH key")
+}
+
+// resolvePassphrase returns the passphrase of a key, read from the environment variable fromEnv or the file fromFile if set.
+// keyName names the key in errors, e.g. "SSH key".
+func resolvePassphrase(passphrase, fromEnv, fromFile, keyName string) (string, error) {
+	return resolveValue(passphrase, fromEnv, fromFile, keyName+" passphrase")
+}
+
+// resolveValue returns the value, read from the environment variable fromEnv or the file fromFile if set.
+// A trailing newline of the file is removed. name names the value in errors, e.g. "git password".
+func resolveValue(value, fromEnv, fromFile, name string) (string, error) {
+	switch {
+	case fromEnv != "":
+		value, found := os.LookupEnv(fromEnv)
+		if !found {
+			return "", fmt.Errorf("environment variable %s containing the %s is not set", fromEnv, name)
+		}
+		return value, nil
+	case fromFile != "":
+		value, err := os.ReadFile(fromFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s file: %w", name, err)
+		}
+		return strings.TrimRight(string(value), "\r\n"), nil
+	default:
+		return value, nil
+	}
+}
+
+// countNonEmpty returns the number of non-empty values.
+func countNonEmpty(values ...string) int {
+	n := 0
+	for _, value := range values {
+		if value != "" {
+			n++
+		}
+	}
+	return n
 }
 
 // DecodePrivateKey decodes the base64 encoded SSH private key.
@@ -74,6 +176,19 @@ func (c *Config) DecodeTLSCACert() ([]byte, error) {
 	return caCertDecoded, nil
 }
 
+// GitHubAppInstallationToken returns the installation token of the GitHub App authentication.
+// The GitHub API is trusted with the TLS CA certificate of the Config, like the git server.
+func (c *Config) GitHubAppInstallationToken() (string, error) {
+	if c.Authentication.GitHubApp == nil {
+		return "", fmt.Errorf("no GitHub App authentication configured")
+	}
+	caBundle, err := c.DecodeTLSCACert()
+	if err != nil {
+		return "", err
+	}
+	return c.Authentication.GitHubApp.InstallationToken(caBundle)
+}
+
 // ParseConfig reads a YAML configuration file and returns a Config object.
 func ParseConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -99,6 +214,18 @@ func (c *Config) Validate() error {
 	if c.Authentication.SSHPrivateKey != nil {
 		numMethods++
 	}
+	if c.Authentication.SSHAgent != nil {
+		numMethods++
+	}
+	if c.Authentication.Netrc != nil {
+		numMethods++
+	}
+	if c.Authentication.CredentialHelper != nil {
+		numMethods++
+	}
+	if c.Authentication.GitHubApp != nil {
+		numMethods++
+	}
 	if numMethods > 1 {
 		return fmt.Errorf("multiple authentication methods provided, only one is allowed")
 	}
@@ -106,28 +233,50 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("no authentication method provided, at least one is required")
 	}
 
-	if c.Authentication.BasicAuth != nil {
-		if c.Authentication.BasicAuth.Username == "" || c.Authentication.BasicAuth.Password == "" {
-			return fmt.Errorf("invalid basic authentication: username and password must be provided")
+	if basicAuth := c.Authentication.BasicAuth; basicAuth != nil {
+		if countNonEmpty(basicAuth.Username, basicAuth.UsernameFromEnv) != 1 || countNonEmpty(basicAuth.Password, basicAuth.PasswordFromEnv, basicAuth.PasswordFromFile) != 1 {
+			return fmt.Errorf("invalid basic authentication: exactly one of username and usernameFromEnv and exactly one of password, passwordFromEnv and passwordFromFile must be provided")
 		}
 	}
-	if c.Authentication.BearerToken != nil {
-		if c.Authentication.BearerToken.Token == "" {
-			return fmt.Errorf("invalid bearer token: token must be provided")
+	if bearerToken := c.Authentication.BearerToken; bearerToken != nil {
+		if countNonEmpty(bearerToken.Token, bearerToken.TokenFromEnv, bearerToken.TokenFromFile) != 1 {
+			return fmt.Errorf("invalid bearer token: exactly one of token, tokenFromEnv and tokenFromFile must be provided")
 		}
 	}
 	if c.Authentication.SSHPrivateKey != nil {
 		if c.Authentication.SSHPrivateKey.PrivateKey == "" {
 			return fmt.Errorf("invalid SSH private key: private key must be provided")
 		}
+		if countNonEmpty(c.Authentication.SSHPrivateKey.Passphrase, c.Authentication.SSHPrivateKey.PassphraseFromEnv, c.Authentication.SSHPrivateKey.PassphraseFromFile) > 1 {
+			return fmt.Errorf("invalid SSH private key: only one of passphrase, passphraseFromEnv and passphraseFromFile is allowed")
+		}
+		if c.Authentication.SSHPrivateKey.KnownHosts != "" && c.Authentication.SSHPrivateKey.InsecureIgnoreHostKey {
+			return fmt.Errorf("invalid SSH private key: knownHosts and insecureIgnoreHostKey are mutually exclusive")
+		}
+	}
+	if c.Authentication.GitHubApp != nil {
+		if err := c.Authentication.GitHubApp.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Authentication.SSHAgent != nil {
+		if c.Authentication.SSHAgent.KnownHosts != "" && c.Authentication.SSHAgent.InsecureIgnoreHostKey {
+			return fmt.Errorf("invalid SSH agent: knownHosts and insecureIgnoreHostKey are mutually exclusive")
+		}
+	}
+	if c.Signing != nil {
+		if err := c.Signing.validate(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 // ConfigureCloneOptions configures the provided git.CloneOptions with the authentication method from the Config.
+// For SSH, the host key of the server at options.URL is verified with the configured known hosts file.
 func (c *Config) ConfigureCloneOptions(options *git.CloneOptions) error {
-	auth, err := c.configureAuth()
+	auth, err := c.configureAuth(options.URL)
 	if err != nil {
 		return err
 	}
@@ -146,8 +295,9 @@ func (c *Config) ConfigureCloneOptions(options *git.CloneOptions) error {
 }
 
 // ConfigurePushOptions configures the provided git.PushOptions with the authentication method from the Config.
+// For SSH, the host key of the server at options.RemoteURL is verified with the configured known hosts file.
 func (c *Config) ConfigurePushOptions(options *git.PushOptions) error {
-	auth, err := c.configureAuth()
+	auth, err := c.configureAuth(options.RemoteURL)
 	if err != nil {
 		return err
 	}
@@ -165,17 +315,103 @@ func (c *Config) ConfigurePushOptions(options *git.PushOptions) error {
 	return nil
 }
 
-func (c *Config) configureAuth() (auth transport.AuthMethod, err error) {
-	if c.Authentication.BasicAuth != nil {
+// ConfigureFetchOptions configures the provided git.FetchOptions with the authentication method from the Config.
+// For SSH, the host key of the server at options.RemoteURL is verified with the configured known hosts file.
+func (c *Config) ConfigureFetchOptions(options *git.FetchOptions) error {
+	auth, err := c.configureAuth(options.RemoteURL)
+	if err != nil {
+		return err
+	}
+	options.Auth = auth
+
+	// Add CA bundle if provided
+	if c.TLSCACert != "" {
+		caBundle, err := c.DecodeTLSCACert()
+		if err != nil {
+			return fmt.Errorf("failed to decode CA bundle: %w", err)
+		}
+		options.CABundle = caBundle
+	}
+
+	return nil
+}
+
+// ConfigureCommitOptions configures the provided git.CommitOptions with the signer from the Config.
+// Without signing configuration, the commit is not signed.
+func (c *Config) ConfigureCommitOptions(options *git.CommitOptions) error {
+	if c.Signing == nil {
+		return nil
+	}
+
+	signer, err := c.Signing.signer()
+	if err != nil {
+		return fmt.Errorf("failed to create commit signer: %w", err)
+	}
+	options.Signer = signer
+
+	return nil
+}
+
+// SignTag signs the annotated tag with the signer from the Config, like the commits.
+// Without signing configuration, the tag is not signed.
+func (c *Config) SignTag(tag *object.Tag) error {
+	if c.Signing == nil {
+		return nil
+	}
+
+	signer, err := c.Signing.signer()
+	if err != nil {
+		return fmt.Errorf("failed to create tag signer: %w", err)
+	}
+
+	encoded := &plumbing.MemoryObject{}
+	if err = tag.EncodeWithoutSignature(encoded); err != nil {
+		return fmt.Errorf("failed to encode tag %s: %w", tag.Name, err)
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read encoded tag %s: %w", tag.Name, err)
+	}
+	signature, err := signer.Sign(reader)
+	if err != nil {
+		return fmt.Errorf("failed to sign tag %s: %w", tag.Name, err)
+	}
+	tag.PGPSignature = string(signature)
+
+	return nil
+}
+
+func (c *Config) configureAuth(repoURL string) (auth transport.AuthMethod, err error) {
+	// credential references are resolved here, unless ResolveCredentials has been called before
+	authentication, err := c.Authentication.resolve(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve git credentials: %w", err)
+	}
+
+	if authentication.BasicAuth != nil {
 		auth = &http.BasicAuth{
-			Username: c.Authentication.BasicAuth.Username,
-			Password: c.Authentication.BasicAuth.Password,
+			Username: authentication.BasicAuth.Username,
+			Password: authentication.BasicAuth.Password,
 		}
 	}
 
-	if c.Authentication.BearerToken != nil {
+	if authentication.BearerToken != nil {
 		auth = &http.TokenAuth{
-			Token: c.Authentication.BearerToken.Token,
+			Token: authentication.BearerToken.Token,
+		}
+	}
+
+	if authentication.GitHubApp != nil {
+		if _, err = httpEndpoint(repoURL, "GitHub App"); err != nil {
+			return nil, err
+		}
+		token, err := c.GitHubAppInstallationToken()
+		if err != nil {
+			return nil, err
+		}
+		auth = &http.BasicAuth{
+			Username: gitHubAppTokenUser,
+			Password: token,
 		}
 	}
 
@@ -185,12 +421,71 @@ func (c *Config) configureAuth() (auth transport.AuthMethod, err error) {
 			return nil, err
 		}
 
-		publicKeys, err := ssh.NewPublicKeys("git", privateKeyDecoded, "")
+		passphrase, err := c.Authentication.SSHPrivateKey.ResolvePassphrase()
+		if err != nil {
+			return nil, err
+		}
+
+		publicKeys, err := ssh.NewPublicKeys(defaultSSHUser, privateKeyDecoded, passphrase)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse SSH private key: %w", err)
 		}
+		publicKeys.HostKeyCallbackHelper, err = hostKeyCallbackHelper(c.Authentication.SSHPrivateKey.KnownHosts, c.Authentication.SSHPrivateKey.InsecureIgnoreHostKey, repoURL)
+		if err != nil {
+			return nil, err
+		}
 		auth = publicKeys
 	}
 
+	if c.Authentication.SSHAgent != nil {
+		user := c.Authentication.SSHAgent.User
+		if user == "" {
+			user = defaultSSHUser
+		}
+
+		agentAuth, err := ssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+		}
+		agentAuth.HostKeyCallbackHelper, err = hostKeyCallbackHelper(c.Authentication.SSHAgent.KnownHosts, c.Authentication.SSHAgent.InsecureIgnoreHostKey, repoURL)
+		if err != nil {
+			return nil, err
+		}
+		auth = agentAuth
+	}
+
 	return auth, nil
 }
+
+// hostKeyCallbackHelper returns the host key verification of SSH connections to the repository at repoURL.
+// The host key is verified with the known hosts file knownHosts, and only the host key algorithms of the
+// repository host listed in it are negotiated. Without known hosts file, the default verification of go-git
+// is kept, which uses the files in SSH_KNOWN_HOSTS or ~/.ssh/known_hosts.
+func hostKeyCallbackHelper(knownHosts string, insecureIgnoreHostKey bool, repoURL string) (ssh.HostKeyCallbackHelper, error) {
+	if insecureIgnoreHostKey {
+		log.GetLogger().Warnf("Host key verification of the git server is disabled")
+		return ssh.HostKeyCallbackHelper{HostKeyCallback: gossh.InsecureIgnoreHostKey()}, nil
+	}
+	if knownHosts == "" {
+		return ssh.HostKeyCallbackHelper{}, nil
+	}
+
+	db, err := ssh.NewKnownHostsDb(knownHosts)
+	if err != nil {
+		return ssh.HostKeyCallbackHelper{}, fmt.Errorf("failed to read known hosts file %s: %w", knownHosts, err)
+	}
+
+	helper := ssh.HostKeyCallbackHelper{HostKeyCallback: db.HostKeyCallback()}
+	if repoURL != "" {
+		endpoint, err := transport.NewEndpoint(repoURL)
+		if err != nil {
+			return ssh.HostKeyCallbackHelper{}, fmt.Errorf("invalid repository URL %s: %w", repoURL, err)
+		}
+		port := endpoint.Port
+		if port == 0 {
+			port = defaultSSHPort
+		}
+		helper.HostKeyAlgorithms = db.HostKeyAlgorithms(net.JoinHostPort(endpoint.Host, strconv.Itoa(port)))
+	}
+	return helper, nil
+}
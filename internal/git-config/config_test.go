@@ -0,0 +1,618 @@
+package gitconfig_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-git/v5"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	gitconfig "github.com/openmcp-project/bootstrapper/internal/git-config"
+)
+
+const testPassphrase = "test-passphrase"
+
+func TestConfigureCloneOptionsWithPasswordProtectedKey(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(privateKey, "", []byte(testPassphrase))
+	assert.NoError(t, err)
+	encodedKey := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(block))
+
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	assert.NoError(t, os.WriteFile(passphraseFile, []byte(testPassphrase+"\n"), 0o600))
+	t.Setenv("TEST_SSH_KEY_PASSPHRASE", testPassphrase)
+
+	testCases := []struct {
+		desc        string
+		key         *gitconfig.SSHPrivateKey
+		expectError bool
+	}{
+		{
+			desc: "inline passphrase",
+			key:  &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, Passphrase: testPassphrase},
+		},
+		{
+			desc: "passphrase from environment variable",
+			key:  &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, PassphraseFromEnv: "TEST_SSH_KEY_PASSPHRASE"},
+		},
+		{
+			desc: "passphrase from file",
+			key:  &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, PassphraseFromFile: passphraseFile},
+		},
+		{
+			desc:        "missing passphrase",
+			key:         &gitconfig.SSHPrivateKey{PrivateKey: encodedKey},
+			expectError: true,
+		},
+		{
+			desc:        "wrong passphrase",
+			key:         &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, Passphrase: "wrong"},
+			expectError: true,
+		},
+		{
+			desc:        "unset environment variable",
+			key:         &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, PassphraseFromEnv: "TEST_SSH_KEY_PASSPHRASE_UNSET"},
+			expectError: true,
+		},
+		{
+			desc:        "missing passphrase file",
+			key:         &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, PassphraseFromFile: filepath.Join(t.TempDir(), "missing")},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := &gitconfig.Config{Authentication: gitconfig.Authentication{SSHPrivateKey: tc.key}}
+			assert.NoError(t, config.Validate())
+
+			options := &git.CloneOptions{}
+			err := config.ConfigureCloneOptions(options)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.IsType(t, &gitssh.PublicKeys{}, options.Auth)
+			}
+		})
+	}
+}
+
+func TestConfigureCloneOptionsWithSSHAgent(t *testing.T) {
+	// unix socket paths are limited in length, so the default temporary directory is used
+	socketDir, err := os.MkdirTemp("", "ssh-agent")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "agent.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keyring := agent.NewKeyring()
+	assert.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: privateKey}))
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	config := &gitconfig.Config{Authentication: gitconfig.Authentication{SSHAgent: &gitconfig.SSHAgent{}}}
+	assert.NoError(t, config.Validate())
+
+	t.Setenv("SSH_AUTH_SOCK", socketPath)
+	options := &git.PushOptions{}
+	if assert.NoError(t, config.ConfigurePushOptions(options)) {
+		agentAuth, ok := options.Auth.(*gitssh.PublicKeysCallback)
+		if assert.True(t, ok) {
+			assert.Equal(t, "git", agentAuth.User)
+			signers, err := agentAuth.Callback()
+			assert.NoError(t, err)
+			assert.Len(t, signers, 1)
+		}
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+	assert.Error(t, config.ConfigurePushOptions(&git.PushOptions{}))
+}
+
+func TestConfigureHostKeyVerification(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	assert.NoError(t, err)
+	encodedKey := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(block))
+
+	hostKey := newTestPublicKey(t)
+	otherHostKey := newTestPublicKey(t)
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	assert.NoError(t, os.WriteFile(knownHosts, []byte(knownhosts.Line([]string{"[127.0.0.1]:2222"}, hostKey)+"\n"), 0o600))
+
+	const repoURL = "ssh://git@127.0.0.1:2222/openmcp/deployment.git"
+	hostAddr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2222}
+
+	testCases := []struct {
+		desc              string
+		key               *gitconfig.SSHPrivateKey
+		hostKey           ssh.PublicKey
+		expectError       bool
+		expectAlgorithms  []string
+		expectConfigError bool
+	}{
+		{
+			desc:             "known host key",
+			key:              &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, KnownHosts: knownHosts},
+			hostKey:          hostKey,
+			expectAlgorithms: []string{ssh.KeyAlgoED25519},
+		},
+		{
+			desc:             "unknown host key",
+			key:              &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, KnownHosts: knownHosts},
+			hostKey:          otherHostKey,
+			expectError:      true,
+			expectAlgorithms: []string{ssh.KeyAlgoED25519},
+		},
+		{
+			desc:    "insecure host key",
+			key:     &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, InsecureIgnoreHostKey: true},
+			hostKey: otherHostKey,
+		},
+		{
+			desc:              "missing known hosts file",
+			key:               &gitconfig.SSHPrivateKey{PrivateKey: encodedKey, KnownHosts: filepath.Join(t.TempDir(), "missing")},
+			expectConfigError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := &gitconfig.Config{Authentication: gitconfig.Authentication{SSHPrivateKey: tc.key}}
+			assert.NoError(t, config.Validate())
+
+			cloneOptions := &git.CloneOptions{URL: repoURL}
+			pushOptions := &git.PushOptions{RemoteURL: repoURL}
+			if tc.expectConfigError {
+				assert.Error(t, config.ConfigureCloneOptions(cloneOptions))
+				assert.Error(t, config.ConfigurePushOptions(pushOptions))
+				return
+			}
+			assert.NoError(t, config.ConfigureCloneOptions(cloneOptions))
+			assert.NoError(t, config.ConfigurePushOptions(pushOptions))
+
+			for _, auth := range []any{cloneOptions.Auth, pushOptions.Auth} {
+				publicKeys, ok := auth.(*gitssh.PublicKeys)
+				if !assert.True(t, ok) {
+					return
+				}
+				err := publicKeys.HostKeyCallback("127.0.0.1:2222", hostAddr, tc.hostKey)
+				if tc.expectError {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, tc.expectAlgorithms, publicKeys.HostKeyAlgorithms)
+			}
+		})
+	}
+}
+
+func newTestPublicKey(t *testing.T) ssh.PublicKey {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	assert.NoError(t, err)
+	return sshPublicKey
+}
+
+func TestConfigureCommitOptionsWithSigning(t *testing.T) {
+	message := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nsigned commit\n"
+
+	entity, err := openpgp.NewEntity("openmcp", "", "noreply@openmcp.cloud", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.PrivateKey.Encrypt([]byte(testPassphrase)))
+	var armoredKey bytes.Buffer
+	w, err := armor.Encode(&armoredKey, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.SerializePrivateWithoutSigning(w, nil))
+	assert.NoError(t, w.Close())
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	assert.NoError(t, err)
+	sshKeyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	assert.NoError(t, os.WriteFile(sshKeyFile, pem.EncodeToMemory(block), 0o600))
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc        string
+		signing     *gitconfig.Signing
+		verify      func(t *testing.T, signature []byte)
+		expectError bool
+	}{
+		{
+			desc:    "openpgp key with passphrase",
+			signing: &gitconfig.Signing{Key: base64.StdEncoding.EncodeToString(armoredKey.Bytes()), Passphrase: testPassphrase},
+			verify: func(t *testing.T, signature []byte) {
+				_, err := openpgp.CheckArmoredDetachedSignature(openpgp.EntityList{entity}, strings.NewReader(message), bytes.NewReader(signature), nil)
+				assert.NoError(t, err)
+			},
+		},
+		{
+			desc:        "openpgp key without passphrase",
+			signing:     &gitconfig.Signing{Format: gitconfig.SigningFormatOpenPGP, Key: base64.StdEncoding.EncodeToString(armoredKey.Bytes())},
+			expectError: true,
+		},
+		{
+			desc:    "ssh key file",
+			signing: &gitconfig.Signing{Format: gitconfig.SigningFormatSSH, KeyFile: sshKeyFile},
+			verify: func(t *testing.T, signature []byte) {
+				verifySSHSignature(t, sshPublicKey, message, signature)
+			},
+		},
+		{
+			desc:        "missing key file",
+			signing:     &gitconfig.Signing{Format: gitconfig.SigningFormatSSH, KeyFile: filepath.Join(t.TempDir(), "missing")},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := &gitconfig.Config{
+				Authentication: gitconfig.Authentication{BearerToken: &gitconfig.BearerToken{Token: "token"}},
+				Signing:        tc.signing,
+			}
+			assert.NoError(t, config.Validate())
+
+			options := &git.CommitOptions{}
+			err := config.ConfigureCommitOptions(options)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) || !assert.NotNil(t, options.Signer) {
+				return
+			}
+
+			signature, err := options.Signer.Sign(strings.NewReader(message))
+			assert.NoError(t, err)
+			tc.verify(t, signature)
+		})
+	}
+}
+
+// verifySSHSignature verifies an armored SSH signature of the message in the namespace "git" with ssh-keygen,
+// like git verify-commit with gpg.format=ssh. The test is skipped if ssh-keygen is not installed.
+func verifySSHSignature(t *testing.T, publicKey ssh.PublicKey, message string, armored []byte) {
+	sshKeygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+
+	dir := t.TempDir()
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	assert.NoError(t, os.WriteFile(allowedSigners, []byte("noreply@openmcp.cloud "+string(ssh.MarshalAuthorizedKey(publicKey))), 0o600))
+	signatureFile := filepath.Join(dir, "signature")
+	assert.NoError(t, os.WriteFile(signatureFile, armored, 0o600))
+
+	verify := func(message string) error {
+		cmd := exec.Command(sshKeygen, "-Y", "verify", "-f", allowedSigners, "-I", "noreply@openmcp.cloud", "-n", "git", "-s", signatureFile)
+		cmd.Stdin = strings.NewReader(message)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, output)
+		}
+		return nil
+	}
+	assert.NoError(t, verify(message))
+	assert.Error(t, verify(message+"tampered\n"))
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		auth        gitconfig.Authentication
+		signing     *gitconfig.Signing
+		expectError bool
+	}{
+		{
+			desc: "ssh agent",
+			auth: gitconfig.Authentication{SSHAgent: &gitconfig.SSHAgent{User: "git"}},
+		},
+		{
+			desc:        "ssh agent and ssh private key",
+			auth:        gitconfig.Authentication{SSHAgent: &gitconfig.SSHAgent{}, SSHPrivateKey: &gitconfig.SSHPrivateKey{PrivateKey: "a2V5"}},
+			expectError: true,
+		},
+		{
+			desc:        "known hosts and insecure host key",
+			auth:        gitconfig.Authentication{SSHPrivateKey: &gitconfig.SSHPrivateKey{PrivateKey: "a2V5", KnownHosts: "known_hosts", InsecureIgnoreHostKey: true}},
+			expectError: true,
+		},
+		{
+			desc:        "ssh agent with known hosts and insecure host key",
+			auth:        gitconfig.Authentication{SSHAgent: &gitconfig.SSHAgent{KnownHosts: "known_hosts", InsecureIgnoreHostKey: true}},
+			expectError: true,
+		},
+		{
+			desc:        "signing without key",
+			auth:        gitconfig.Authentication{BearerToken: &gitconfig.BearerToken{Token: "token"}},
+			signing:     &gitconfig.Signing{Format: gitconfig.SigningFormatSSH},
+			expectError: true,
+		},
+		{
+			desc:        "signing with unsupported format",
+			auth:        gitconfig.Authentication{BearerToken: &gitconfig.BearerToken{Token: "token"}},
+			signing:     &gitconfig.Signing{Format: "x509", KeyFile: "key.pem"},
+			expectError: true,
+		},
+		{
+			desc:        "multiple passphrases",
+			auth:        gitconfig.Authentication{SSHPrivateKey: &gitconfig.SSHPrivateKey{PrivateKey: "a2V5", Passphrase: "a", PassphraseFromEnv: "B"}},
+			expectError: true,
+		},
+		{
+			desc: "basic auth from environment and file",
+			auth: gitconfig.Authentication{BasicAuth: &gitconfig.BasicAuth{UsernameFromEnv: "USER", PasswordFromFile: "password"}},
+		},
+		{
+			desc:        "basic auth with password and password from environment",
+			auth:        gitconfig.Authentication{BasicAuth: &gitconfig.BasicAuth{Username: "user", Password: "a", PasswordFromEnv: "B"}},
+			expectError: true,
+		},
+		{
+			desc:        "bearer token without token",
+			auth:        gitconfig.Authentication{BearerToken: &gitconfig.BearerToken{}},
+			expectError: true,
+		},
+		{
+			desc: "credential helper",
+			auth: gitconfig.Authentication{CredentialHelper: &gitconfig.CredentialHelper{Helper: "store"}},
+		},
+		{
+			desc: "github app",
+			auth: gitconfig.Authentication{GitHubApp: &gitconfig.GitHubApp{AppID: 123, InstallationID: 456, PrivateKeyFile: "app.pem"}},
+		},
+		{
+			desc:        "github app without installation",
+			auth:        gitconfig.Authentication{GitHubApp: &gitconfig.GitHubApp{AppID: 123, PrivateKeyFile: "app.pem"}},
+			expectError: true,
+		},
+		{
+			desc:        "netrc and basic auth",
+			auth:        gitconfig.Authentication{Netrc: &gitconfig.Netrc{}, BasicAuth: &gitconfig.BasicAuth{Username: "user", Password: "pass"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := &gitconfig.Config{Authentication: tc.auth, Signing: tc.signing}
+			err := config.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResolveCredentials(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+	netrcFile := filepath.Join(dir, "netrc")
+	assert.NoError(t, os.WriteFile(netrcFile, []byte("machine github.com\n  login netrc-user\n  password netrc-pass\n"), 0o600))
+	t.Setenv("TEST_GIT_USERNAME", "env-user")
+	t.Setenv("TEST_GIT_PASSWORD", "env-pass")
+
+	const repoURL = "https://github.com/openmcp-project/bootstrapper.git"
+
+	testCases := []struct {
+		desc        string
+		auth        gitconfig.Authentication
+		repoURL     string
+		expected    gitconfig.Authentication
+		expectError bool
+	}{
+		{
+			desc:     "basic auth from environment",
+			auth:     gitconfig.Authentication{BasicAuth: &gitconfig.BasicAuth{UsernameFromEnv: "TEST_GIT_USERNAME", PasswordFromEnv: "TEST_GIT_PASSWORD"}},
+			expected: gitconfig.Authentication{BasicAuth: &gitconfig.BasicAuth{Username: "env-user", Password: "env-pass"}},
+		},
+		{
+			desc:        "basic auth from unset environment variable",
+			auth:        gitconfig.Authentication{BasicAuth: &gitconfig.BasicAuth{Username: "user", PasswordFromEnv: "TEST_GIT_UNSET"}},
+			expectError: true,
+		},
+		{
+			desc:     "bearer token from file",
+			auth:     gitconfig.Authentication{BearerToken: &gitconfig.BearerToken{TokenFromFile: tokenFile}},
+			expected: gitconfig.Authentication{BearerToken: &gitconfig.BearerToken{Token: "file-token"}},
+		},
+		{
+			desc:     "netrc",
+			auth:     gitconfig.Authentication{Netrc: &gitconfig.Netrc{File: netrcFile}},
+			expected: gitconfig.Authentication{BasicAuth: &gitconfig.BasicAuth{Username: "netrc-user", Password: "netrc-pass"}},
+		},
+		{
+			desc:        "netrc without entry for the host",
+			auth:        gitconfig.Authentication{Netrc: &gitconfig.Netrc{File: netrcFile}},
+			repoURL:     "https://gitlab.com/openmcp-project/bootstrapper.git",
+			expectError: true,
+		},
+		{
+			desc:        "netrc with ssh repository URL",
+			auth:        gitconfig.Authentication{Netrc: &gitconfig.Netrc{File: netrcFile}},
+			repoURL:     "git@github.com:openmcp-project/bootstrapper.git",
+			expectError: true,
+		},
+		{
+			desc:     "credential helper",
+			auth:     gitconfig.Authentication{CredentialHelper: &gitconfig.CredentialHelper{Helper: "!f() { cat >/dev/null; echo username=helper-user; echo password=helper-pass; }; f"}},
+			expected: gitconfig.Authentication{BasicAuth: &gitconfig.BasicAuth{Username: "helper-user", Password: "helper-pass"}},
+		},
+		{
+			desc:        "credential helper without password",
+			auth:        gitconfig.Authentication{CredentialHelper: &gitconfig.CredentialHelper{Helper: "!true"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			url := tc.repoURL
+			if url == "" {
+				url = repoURL
+			}
+			config := &gitconfig.Config{Authentication: tc.auth}
+			err := config.ResolveCredentials(url)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, config.Authentication)
+
+			cloneOptions := &git.CloneOptions{URL: url}
+			assert.NoError(t, (&gitconfig.Config{Authentication: tc.auth}).ConfigureCloneOptions(cloneOptions))
+			assert.NotNil(t, cloneOptions.Auth)
+		})
+	}
+}
+
+func TestConfigureCloneOptionsWithGitHubApp(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.NoError(t, err) {
+		return
+	}
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/456/access_tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// verify the JWT signed with the private key of the app
+		jwt := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		if len(jwt) != 3 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(jwt[2])
+		digest := sha256.Sum256([]byte(jwt[0] + "." + jwt[1]))
+		claims, _ := base64.RawURLEncoding.DecodeString(jwt[1])
+		if rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature) != nil || !strings.Contains(string(claims), `"iss":"123"`) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"token":"installation-token","expires_at":"%s"}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	}))
+	defer server.Close()
+	// the GitHub API of a GitHub Enterprise Server with a private CA is trusted with the TLS CA certificate of the git config
+	tlsCACert := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	testCases := []struct {
+		desc        string
+		githubApp   *gitconfig.GitHubApp
+		repoURL     string
+		tlsCACert   string
+		expectError bool
+	}{
+		{
+			desc:      "installation token",
+			githubApp: &gitconfig.GitHubApp{AppID: 123, InstallationID: 456, PrivateKey: base64.StdEncoding.EncodeToString(privateKeyPEM), BaseURL: server.URL},
+			repoURL:   "https://github.com/openmcp-project/bootstrapper.git",
+			tlsCACert: tlsCACert,
+		},
+		{
+			desc:        "untrusted GitHub API certificate",
+			githubApp:   &gitconfig.GitHubApp{AppID: 123, InstallationID: 456, PrivateKey: base64.StdEncoding.EncodeToString(privateKeyPEM), BaseURL: server.URL},
+			repoURL:     "https://github.com/openmcp-project/bootstrapper.git",
+			expectError: true,
+		},
+		{
+			desc:        "unknown installation",
+			githubApp:   &gitconfig.GitHubApp{AppID: 123, InstallationID: 789, PrivateKey: base64.StdEncoding.EncodeToString(privateKeyPEM), BaseURL: server.URL},
+			repoURL:     "https://github.com/openmcp-project/bootstrapper.git",
+			tlsCACert:   tlsCACert,
+			expectError: true,
+		},
+		{
+			desc:        "ssh repository URL",
+			githubApp:   &gitconfig.GitHubApp{AppID: 123, InstallationID: 456, PrivateKey: base64.StdEncoding.EncodeToString(privateKeyPEM), BaseURL: server.URL},
+			repoURL:     "git@github.com:openmcp-project/bootstrapper.git",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			requests = 0
+			config := &gitconfig.Config{Authentication: gitconfig.Authentication{GitHubApp: tc.githubApp}, TLSCACert: tc.tlsCACert}
+			if !assert.NoError(t, config.Validate()) {
+				return
+			}
+
+			cloneOptions := &git.CloneOptions{URL: tc.repoURL}
+			err := config.ConfigureCloneOptions(cloneOptions)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &githttp.BasicAuth{Username: "x-access-token", Password: "installation-token"}, cloneOptions.Auth)
+
+			// the installation token is reused until it is about to expire
+			pushOptions := &git.PushOptions{RemoteURL: tc.repoURL}
+			assert.NoError(t, config.ConfigurePushOptions(pushOptions))
+			assert.Equal(t, cloneOptions.Auth, pushOptions.Auth)
+			assert.Equal(t, 1, requests)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package gitconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const (
+	// netrcEnv is the environment variable containing the path to the netrc file, as used by curl and git.
+	netrcEnv = "NETRC"
+)
+
+// Netrc represents basic authentication with the credentials of the repository host in a netrc file.
+type Netrc struct {
+	// File is the path to the netrc file. Defaults to the file in the NETRC environment variable or ~/.netrc.
+	File string `json:"file,omitempty"`
+}
+
+// CredentialHelper represents basic authentication with the credentials returned by a git credential helper.
+type CredentialHelper struct {
+	// Helper is the credential helper in the format of the git configuration credential.helper,
+	// e.g. "store", "cache --timeout 300", the absolute path of a helper or a shell command prefixed with "!".
+	// Empty uses the credential helpers configured for git by running "git credential fill".
+	Helper string `json:"helper,omitempty"`
+}
+
+// ResolveCredentials resolves the credential references of the authentication for the repository at repoURL
+// and replaces them by the resolved credentials: the environment variables and files of the basic authentication
+// and the bearer token are read, the credentials of a netrc file or credential helper are replaced by basic authentication.
+// The resolved credentials are used by the git operations and the Flux git secret.
+func (c *Config) ResolveCredentials(repoURL string) error {
+	authentication, err := c.Authentication.resolve(repoURL)
+	if err != nil {
+		return err
+	}
+	c.Authentication = *authentication
+	return nil
+}
+
+// HostBound returns whether the authentication contains credentials for a single repository host, which must not be
+// sent to other hosts: basic authentication, bearer tokens and GitHub App installation tokens. The credentials of a netrc file
+// and a credential helper are looked up per host, SSH authentication does not send a secret to the server.
+func (a *Authentication) HostBound() bool {
+	return a.BasicAuth != nil || a.BearerToken != nil || a.GitHubApp != nil
+}
+
+// resolve returns a copy of the authentication with resolved credential references.
+func (a *Authentication) resolve(repoURL string) (*Authentication, error) {
+	resolved := *a
+
+	switch {
+	case a.BasicAuth != nil:
+		username, err := resolveValue(a.BasicAuth.Username, a.BasicAuth.UsernameFromEnv, "", "git username")
+		if err != nil {
+			return nil, err
+		}
+		password, err := resolveValue(a.BasicAuth.Password, a.BasicAuth.PasswordFromEnv, a.BasicAuth.PasswordFromFile, "git password")
+		if err != nil {
+			return nil, err
+		}
+		resolved.BasicAuth = &BasicAuth{Username: username, Password: password}
+	case a.BearerToken != nil:
+		token, err := resolveValue(a.BearerToken.Token, a.BearerToken.TokenFromEnv, a.BearerToken.TokenFromFile, "git bearer token")
+		if err != nil {
+			return nil, err
+		}
+		resolved.BearerToken = &BearerToken{Token: token}
+	case a.Netrc != nil:
+		basicAuth, err := a.Netrc.credentials(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Netrc = nil
+		resolved.BasicAuth = basicAuth
+	case a.CredentialHelper != nil:
+		basicAuth, err := a.CredentialHelper.credentials(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		resolved.CredentialHelper = nil
+		resolved.BasicAuth = basicAuth
+	}
+
+	return &resolved, nil
+}
+
+// httpEndpoint parses the repository URL, which must be an http or https URL for basic authentication.
+func httpEndpoint(repoURL, method string) (*transport.Endpoint, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URL %s: %w", repoURL, err)
+	}
+	if endpoint.Protocol != "http" && endpoint.Protocol != "https" {
+		return nil, fmt.Errorf("%s authentication requires an http or https repository URL, got %s", method, repoURL)
+	}
+	return endpoint, nil
+}
+
+// credentials returns the credentials of the host of the repository at repoURL from the netrc file.
+// If the file has no entry for the host, the default entry is used.
+func (n *Netrc) credentials(repoURL string) (*BasicAuth, error) {
+	endpoint, err := httpEndpoint(repoURL, "netrc")
+	if err != nil {
+		return nil, err
+	}
+
+	path := n.File
+	if path == "" {
+		path = os.Getenv(netrcEnv)
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory for the netrc file: %w", err)
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read netrc file: %w", err)
+	}
+
+	basicAuth := parseNetrc(string(data), endpoint.Host)
+	if basicAuth == nil {
+		return nil, fmt.Errorf("netrc file %s contains no credentials for host %s", path, endpoint.Host)
+	}
+	log.GetLogger().Debugf("Using credentials of host %s from netrc file %s", endpoint.Host, path)
+	return basicAuth, nil
+}
+
+// parseNetrc returns the login and password of the machine entry of the host in the netrc data, or of the default entry.
+// It returns nil if there is neither an entry for the host nor a default entry with a password.
+func parseNetrc(data, host string) *BasicAuth {
+	var (
+		machine, defaultEntry *BasicAuth
+		current               *BasicAuth
+	)
+
+	tokens := strings.Fields(data)
+	for i := 0; i < len(tokens); i++ {
+		next := func() string {
+			if i+1 < len(tokens) {
+				i++
+				return tokens[i]
+			}
+			return ""
+		}
+
+		switch tokens[i] {
+		case "machine":
+			current = nil
+			if next() == host && machine == nil {
+				machine = &BasicAuth{}
+				current = machine
+			}
+		case "default":
+			current = nil
+			if defaultEntry == nil {
+				defaultEntry = &BasicAuth{}
+				current = defaultEntry
+			}
+		case "login":
+			if value := next(); current != nil {
+				current.Username = value
+			}
+		case "password":
+			if value := next(); current != nil {
+				current.Password = value
+			}
+		case "account":
+			next()
+		case "macdef":
+			// macro definitions end at the next empty line, which is lost by splitting into fields, so stop parsing
+			current = nil
+			i = len(tokens)
+		}
+	}
+
+	for _, entry := range []*BasicAuth{machine, defaultEntry} {
+		if entry != nil && entry.Password != "" {
+			return entry
+		}
+	}
+	return nil
+}
+
+// credentials returns the credentials of the repository at repoURL from the credential helper.
+// It implements the "get" action of the protocol described in https://git-scm.com/docs/gitcredentials.
+func (h *CredentialHelper) credentials(repoURL string) (*BasicAuth, error) {
+	endpoint, err := httpEndpoint(repoURL, "credential helper")
+	if err != nil {
+		return nil, err
+	}
+
+	host := endpoint.Host
+	if endpoint.Port != 0 {
+		host = net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+	}
+	input := fmt.Sprintf("protocol=%s\nhost=%s\n\n", endpoint.Protocol, host)
+
+	var cmd *exec.Cmd
+	switch helper := strings.TrimSpace(h.Helper); {
+	case helper == "":
+		cmd = exec.Command("git", "credential", "fill")
+	case strings.HasPrefix(helper, "!"):
+		cmd = exec.Command("sh", "-c", strings.TrimPrefix(helper, "!")+" get")
+	case filepath.IsAbs(helper):
+		cmd = exec.Command("sh", "-c", helper+" get")
+	default:
+		cmd = exec.Command("sh", "-c", "git credential-"+helper+" get")
+	}
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stderr = os.Stderr
+	// never prompt for credentials, the bootstrapper runs non-interactively
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials of host %s from credential helper: %w", host, err)
+	}
+
+	basicAuth := &BasicAuth{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "username":
+			basicAuth.Username = value
+		case "password":
+			basicAuth.Password = value
+		}
+	}
+	if basicAuth.Password == "" {
+		return nil, fmt.Errorf("credential helper returned no password for host %s", host)
+	}
+	log.GetLogger().Debugf("Using credentials of host %s from credential helper", host)
+	return basicAuth, nil
+}
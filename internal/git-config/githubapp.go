@@ -0,0 +1,214 @@
+package gitconfig
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+const (
+	// defaultGitHubAPIURL is the API URL of github.com.
+	defaultGitHubAPIURL = "https://api.github.com"
+	// gitHubAppTokenUser is the username of basic authentication with an installation token.
+	gitHubAppTokenUser = "x-access-token"
+	// gitHubAppJWTLifetime is the lifetime of the JWT authenticating the app, GitHub allows at most 10 minutes.
+	gitHubAppJWTLifetime = 9 * time.Minute
+	// gitHubAppTokenRefreshMargin is the remaining lifetime below which an installation token is renewed.
+	gitHubAppTokenRefreshMargin = 5 * time.Minute
+	// gitHubAPITimeout is the timeout of the requests to the GitHub API.
+	gitHubAPITimeout = 30 * time.Second
+)
+
+// GitHubApp represents authentication as the installation of a GitHub App.
+// The bootstrapper exchanges the private key of the app for an installation token, Flux does the same with the
+// keys githubAppID, githubAppInstallationID and githubAppPrivateKey of its secret, if the provider of the deployment repository is "github".
+type GitHubApp struct {
+	// AppID is the ID of the GitHub App.
+	AppID int64 `json:"appID,omitempty"`
+	// InstallationID is the ID of the installation of the GitHub App in the organization or account of the repository.
+	InstallationID int64 `json:"installationID,omitempty"`
+	// PrivateKey is the base64 encoded PEM private key of the GitHub App.
+	PrivateKey string `json:"privateKey,omitempty"`
+	// PrivateKeyFile is the path to the PEM private key file of the GitHub App.
+	PrivateKeyFile string `json:"privateKeyFile,omitempty"`
+	// BaseURL is the GitHub API URL, e.g. "https://github.example.com/api/v3" for GitHub Enterprise Server.
+	// Defaults to "https://api.github.com".
+	BaseURL string `json:"baseURL,omitempty"`
+
+	// token is the last installation token, reused until it is about to expire.
+	token *installationToken
+}
+
+// installationToken is the response of the GitHub API creating an installation access token.
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// validate checks that the app, the installation and exactly one private key are configured.
+func (g *GitHubApp) validate() error {
+	if g.AppID <= 0 || g.InstallationID <= 0 {
+		return fmt.Errorf("invalid GitHub App: appID and installationID must be provided")
+	}
+	if countNonEmpty(g.PrivateKey, g.PrivateKeyFile) != 1 {
+		return fmt.Errorf("invalid GitHub App: exactly one of privateKey and privateKeyFile must be provided")
+	}
+	return nil
+}
+
+// DecodePrivateKey returns the PEM private key of the GitHub App, decoded from PrivateKey or read from PrivateKeyFile.
+func (g *GitHubApp) DecodePrivateKey() ([]byte, error) {
+	if g.PrivateKeyFile != "" {
+		privateKey, err := os.ReadFile(g.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key file: %w", err)
+		}
+		return privateKey, nil
+	}
+
+	privateKey, err := base64.StdEncoding.DecodeString(g.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode GitHub App private key: %w", err)
+	}
+	return privateKey, nil
+}
+
+// InstallationToken returns an installation access token of the GitHub App.
+// The token is requested from the GitHub API, which is trusted with the system CAs and the PEM encoded caBundle,
+// e.g. the private CA of a GitHub Enterprise Server. It is reused until it is about to expire.
+func (g *GitHubApp) InstallationToken(caBundle []byte) (string, error) {
+	if g.token != nil && time.Until(g.token.ExpiresAt) > gitHubAppTokenRefreshMargin {
+		return g.token.Token, nil
+	}
+
+	jwt, err := g.jwt(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	baseURL := g.BaseURL
+	if baseURL == "" {
+		baseURL = defaultGitHubAPIURL
+	}
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(baseURL, "/"), g.InstallationID)
+
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub App installation token request: %w", err)
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("Authorization", "Bearer "+jwt)
+	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	client, err := gitHubAPIClient(caBundle)
+	if err != nil {
+		return "", err
+	}
+
+	log.GetLogger().Debugf("Requesting installation token of GitHub App %d for installation %d", g.AppID, g.InstallationID)
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to request GitHub App installation token: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GitHub App installation token response: %w", err)
+	}
+	if response.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to request GitHub App installation token: %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	token := &installationToken{}
+	if err = json.Unmarshal(body, token); err != nil {
+		return "", fmt.Errorf("failed to parse GitHub App installation token response: %w", err)
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("GitHub App installation token response contains no token")
+	}
+
+	g.token = token
+	return token.Token, nil
+}
+
+// gitHubAPIClient returns the HTTP client of the GitHub API, which trusts the PEM encoded caBundle in addition to the system CAs.
+func gitHubAPIClient(caBundle []byte) (*http.Client, error) {
+	client, err := util.NewHTTPClient(caBundle, gitHubAPITimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub API client: %w", err)
+	}
+	return client, nil
+}
+
+// jwt returns the JSON Web Token authenticating the GitHub App, signed with its private key.
+// It is backdated by a minute to allow for clock drift, as recommended by GitHub.
+func (g *GitHubApp) jwt(now time.Time) (string, error) {
+	privateKeyPEM, err := g.DecodePrivateKey()
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := parseRSAPrivateKey(privateKeyPEM)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(gitHubAppJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(g.AppID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses a PEM encoded PKCS #1 or PKCS #8 RSA private key, as downloaded from GitHub.
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA private key, got %T", key)
+	}
+	return privateKey, nil
+}
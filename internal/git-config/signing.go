@@ -0,0 +1,221 @@
+package gitconfig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	// SigningFormatOpenPGP signs commits with an OpenPGP key, like git's gpg.format "openpgp".
+	SigningFormatOpenPGP = "openpgp"
+	// SigningFormatSSH signs commits with an SSH key, like git's gpg.format "ssh".
+	SigningFormatSSH = "ssh"
+
+	// sshSignatureNamespace is the namespace of SSH signatures of git objects.
+	sshSignatureNamespace = "git"
+	// sshSignatureHashAlgorithm is the hash algorithm of the signed data, as used by ssh-keygen.
+	sshSignatureHashAlgorithm = "sha512"
+	sshSignatureMagic         = "SSHSIG"
+	sshSignatureVersion       = 1
+	// sshSignatureLineLength is the line length of armored SSH signatures, as used by ssh-keygen.
+	sshSignatureLineLength = 70
+)
+
+// SigningFormats are the supported signing formats.
+var SigningFormats = []string{SigningFormatOpenPGP, SigningFormatSSH}
+
+// Signing configures the signing of the commits created by the bootstrapper.
+type Signing struct {
+	// Format is the signature format, "openpgp" or "ssh". Defaults to "openpgp".
+	Format string `json:"format,omitempty"`
+	// Key is the base64 encoded private key, an armored OpenPGP private key or an OpenSSH private key.
+	Key string `json:"key,omitempty"`
+	// KeyFile is the path to the private key file.
+	KeyFile string `json:"keyFile,omitempty"`
+	// Passphrase is the passphrase of a password protected private key.
+	Passphrase string `json:"passphrase,omitempty"`
+	// PassphraseFromEnv is the name of the environment variable containing the passphrase of a password protected private key.
+	PassphraseFromEnv string `json:"passphraseFromEnv,omitempty"`
+	// PassphraseFromFile is the path to the file containing the passphrase of a password protected private key.
+	PassphraseFromFile string `json:"passphraseFromFile,omitempty"`
+}
+
+// validate checks the signing configuration for correctness.
+func (s *Signing) validate() error {
+	if s.Format != "" && s.Format != SigningFormatOpenPGP && s.Format != SigningFormatSSH {
+		return fmt.Errorf("invalid signing: unsupported format %q, must be one of %s", s.Format, strings.Join(SigningFormats, ", "))
+	}
+	if (s.Key == "") == (s.KeyFile == "") {
+		return fmt.Errorf("invalid signing: exactly one of key and keyFile must be provided")
+	}
+	if countNonEmpty(s.Passphrase, s.PassphraseFromEnv, s.PassphraseFromFile) > 1 {
+		return fmt.Errorf("invalid signing: only one of passphrase, passphraseFromEnv and passphraseFromFile is allowed")
+	}
+	return nil
+}
+
+// readKey returns the private key, decoded from Key or read from KeyFile.
+func (s *Signing) readKey() ([]byte, error) {
+	if s.KeyFile != "" {
+		key, err := os.ReadFile(s.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key file: %w", err)
+		}
+		return key, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(s.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signing key: %w", err)
+	}
+	return key, nil
+}
+
+// signer creates the git.Signer of the configured key.
+func (s *Signing) signer() (git.Signer, error) {
+	key, err := s.readKey()
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := resolvePassphrase(s.Passphrase, s.PassphraseFromEnv, s.PassphraseFromFile, "signing key")
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Format == SigningFormatSSH {
+		return newSSHSigner(key, passphrase)
+	}
+	return newOpenPGPSigner(key, passphrase)
+}
+
+// openPGPSigner creates armored detached OpenPGP signatures.
+type openPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+func newOpenPGPSigner(key []byte, passphrase string) (*openPGPSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenPGP signing key: %w", err)
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+		if entity.PrivateKey.Encrypted {
+			if passphrase == "" {
+				return nil, fmt.Errorf("OpenPGP signing key is password protected, but no passphrase is configured")
+			}
+			if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("failed to decrypt OpenPGP signing key: %w", err)
+			}
+		}
+		return &openPGPSigner{entity: entity}, nil
+	}
+	return nil, fmt.Errorf("OpenPGP signing key does not contain a private key")
+}
+
+// Sign returns the armored detached signature of the message.
+func (s *openPGPSigner) Sign(message io.Reader) ([]byte, error) {
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("failed to sign with OpenPGP key: %w", err)
+	}
+	return signature.Bytes(), nil
+}
+
+// sshSigner creates armored SSH signatures in the format of "ssh-keygen -Y sign", which git verifies with gpg.format "ssh".
+// See https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig.
+type sshSigner struct {
+	signer gossh.Signer
+}
+
+func newSSHSigner(key []byte, passphrase string) (*sshSigner, error) {
+	var (
+		signer gossh.Signer
+		err    error
+	)
+	if passphrase != "" {
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		signer, err = gossh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH signing key: %w", err)
+	}
+	return &sshSigner{signer: signer}, nil
+}
+
+// sshSignedData is the data signed by an SSH signature.
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          string
+}
+
+// sshSignatureBlob is the content of an armored SSH signature.
+type sshSignatureBlob struct {
+	Version       uint32
+	PublicKey     string
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     string
+}
+
+// Sign returns the armored SSH signature of the message.
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	hash := sha512.New()
+	if _, err := io.Copy(hash, message); err != nil {
+		return nil, fmt.Errorf("failed to hash message: %w", err)
+	}
+
+	signedData := append([]byte(sshSignatureMagic), gossh.Marshal(sshSignedData{
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: sshSignatureHashAlgorithm,
+		Hash:          string(hash.Sum(nil)),
+	})...)
+
+	var (
+		signature *gossh.Signature
+		err       error
+	)
+	// RSA keys must not sign with the SHA-1 based "ssh-rsa" algorithm.
+	if algorithmSigner, ok := s.signer.(gossh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == gossh.KeyAlgoRSA {
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signedData, gossh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = s.signer.Sign(rand.Reader, signedData)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with SSH key: %w", err)
+	}
+
+	blob := append([]byte(sshSignatureMagic), gossh.Marshal(sshSignatureBlob{
+		Version:       sshSignatureVersion,
+		PublicKey:     string(s.signer.PublicKey().Marshal()),
+		Namespace:     sshSignatureNamespace,
+		HashAlgorithm: sshSignatureHashAlgorithm,
+		Signature:     string(gossh.Marshal(signature)),
+	})...)
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var armored bytes.Buffer
+	armored.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > sshSignatureLineLength {
+		armored.WriteString(encoded[:sshSignatureLineLength] + "\n")
+		encoded = encoded[sshSignatureLineLength:]
+	}
+	armored.WriteString(encoded + "\n")
+	armored.WriteString("-----END SSH SIGNATURE-----\n")
+	return armored.Bytes(), nil
+}
@@ -0,0 +1,167 @@
+package ocm_cli
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+const (
+	// artifactSetIndexFileName is the name of the index of an OCM artifact set, the format of OCI artifacts stored as local blob.
+	artifactSetIndexFileName = "index.json"
+	// artifactSetLegacyIndexFileName is the name of the index of an OCM artifact set in the legacy format.
+	artifactSetLegacyIndexFileName = "artifact-descriptor.json"
+	// artifactSetBlobsDirectoryName is the name of the directory containing the blobs of an OCM artifact set.
+	artifactSetBlobsDirectoryName = "blobs"
+	// ArtifactSetMainAnnotation marks the main artifact of an OCM artifact set. It is set on the index with the digest of the main artifact,
+	// or on the descriptor of the main artifact with the value "true".
+	ArtifactSetMainAnnotation = "software.ocm/main"
+)
+
+// ReadArtifactSetIndex reads the index of an extracted OCM artifact set.
+func ReadArtifactSetIndex(dir string) (*v1.IndexManifest, error) {
+	indexData, err := os.ReadFile(filepath.Join(dir, artifactSetIndexFileName))
+	if os.IsNotExist(err) {
+		indexData, err = os.ReadFile(filepath.Join(dir, artifactSetLegacyIndexFileName))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading artifact set index: %w", err)
+	}
+
+	index, err := v1.ParseIndexManifest(bytes.NewReader(indexData))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing artifact set index: %w", err)
+	}
+	return index, nil
+}
+
+// ArtifactSetMainManifest returns the descriptor of the main artifact of an OCM artifact set.
+// Without main annotation, the first artifact is the main artifact.
+func ArtifactSetMainManifest(index *v1.IndexManifest) (v1.Descriptor, error) {
+	if len(index.Manifests) == 0 {
+		return v1.Descriptor{}, fmt.Errorf("artifact set does not contain any artifact")
+	}
+
+	if mainDigest := index.Annotations[ArtifactSetMainAnnotation]; mainDigest != "" {
+		for _, desc := range index.Manifests {
+			if desc.Digest.String() == mainDigest {
+				return desc, nil
+			}
+		}
+		return v1.Descriptor{}, fmt.Errorf("main artifact %s not found in artifact set", mainDigest)
+	}
+
+	for _, desc := range index.Manifests {
+		if desc.Annotations[ArtifactSetMainAnnotation] == "true" {
+			return desc, nil
+		}
+	}
+	return index.Manifests[0], nil
+}
+
+// ArtifactSetBlobPath returns the path of a blob in an extracted artifact set.
+// Both the OCM layout (blobs/sha256.<hex>) and the OCI layout (blobs/sha256/<hex>) are supported.
+func ArtifactSetBlobPath(dir string, digest v1.Hash) string {
+	path := filepath.Join(dir, artifactSetBlobsDirectoryName, digest.Algorithm+"."+digest.Hex)
+	if _, err := os.Stat(path); err != nil {
+		return filepath.Join(dir, artifactSetBlobsDirectoryName, digest.Algorithm, digest.Hex)
+	}
+	return path
+}
+
+// verifyArtifactSet verifies that the main artifact of the OCM artifact set in blob has the expected manifest digest,
+// and that the manifests and blobs of the main artifact match their digests.
+func verifyArtifactSet(blob io.Reader, expectedDigest string) error {
+	tempDir, err := util.CreateTempDir()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = util.DeleteTempDir(tempDir)
+	}()
+
+	if err = util.ExtractTarArchive(blob, tempDir); err != nil {
+		return fmt.Errorf("error extracting artifact set: %w", err)
+	}
+
+	index, err := ReadArtifactSetIndex(tempDir)
+	if err != nil {
+		return err
+	}
+	main, err := ArtifactSetMainManifest(index)
+	if err != nil {
+		return err
+	}
+	if main.Digest.Hex != expectedDigest {
+		return fmt.Errorf("main artifact has digest %s, expected %s", main.Digest, expectedDigest)
+	}
+
+	return verifyArtifactSetManifest(tempDir, main)
+}
+
+// verifyArtifactSetManifest verifies the manifest with the given descriptor and everything it references in an extracted artifact set.
+func verifyArtifactSetManifest(dir string, desc v1.Descriptor) error {
+	if err := verifyArtifactSetBlob(dir, desc.Digest); err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(ArtifactSetBlobPath(dir, desc.Digest))
+	if err != nil {
+		return fmt.Errorf("error reading manifest %s: %w", desc.Digest, err)
+	}
+
+	if desc.MediaType.IsIndex() {
+		index, err := v1.ParseIndexManifest(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Errorf("error parsing index %s: %w", desc.Digest, err)
+		}
+		for _, child := range index.Manifests {
+			if err = verifyArtifactSetManifest(dir, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	manifest, err := v1.ParseManifest(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("error parsing manifest %s: %w", desc.Digest, err)
+	}
+	for _, blobDesc := range append([]v1.Descriptor{manifest.Config}, manifest.Layers...) {
+		if err = verifyArtifactSetBlob(dir, blobDesc.Digest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyArtifactSetBlob verifies that the blob with the given digest in an extracted artifact set matches its digest.
+func verifyArtifactSetBlob(dir string, digest v1.Hash) error {
+	if digest.Algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm %s of blob %s", digest.Algorithm, digest)
+	}
+
+	blob, err := os.Open(ArtifactSetBlobPath(dir, digest))
+	if err != nil {
+		return fmt.Errorf("error opening blob %s of artifact set: %w", digest, err)
+	}
+	defer func() {
+		_ = blob.Close()
+	}()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, blob); err != nil {
+		return fmt.Errorf("error reading blob %s of artifact set: %w", digest, err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != digest.Hex {
+		return fmt.Errorf("blob %s of artifact set has digest sha256:%s", digest, actual)
+	}
+	return nil
+}
@@ -0,0 +1,338 @@
+package ocm_cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+const (
+	// DefaultCacheDirName is the name of the cache directory below the user cache directory.
+	DefaultCacheDirName = "openmcp-bootstrapper"
+
+	cacheDescriptorsDir = "component-descriptors"
+	cacheResourcesDir   = "resources"
+)
+
+var (
+	defaultCache   = NewCache("")
+	defaultCacheMu sync.RWMutex
+)
+
+// Cache caches component versions and downloaded directory resources.
+// Component versions are memoized in memory for the lifetime of the cache, callers receive copies of them.
+// If a directory is configured, component descriptors and resources are additionally persisted on disk.
+// Component descriptors are stored under the SHA-256 hash of their repository, component name and version.
+// Only references with an explicit version to a remote repository are persisted, as these component versions are immutable,
+// while a local CTF may be rebuilt with the same version. Directory resources are content-addressed by the digest of the
+// resource in its component descriptor, resources without digest are not persisted.
+type Cache struct {
+	dir string
+
+	memoMu sync.Mutex
+	// memo contains the encoded component versions, so that every caller receives its own copy
+	memo map[string][]byte
+}
+
+// NewCache creates a cache persisting its entries in dir. An empty dir disables the persistent cache.
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir:  dir,
+		memo: map[string][]byte{},
+	}
+}
+
+// Dir returns the directory of the persistent cache, or an empty string if the persistent cache is disabled.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// SetCacheDir configures the directory of the persistent cache used by NewClient.
+// An empty dir disables the persistent cache; component versions are still memoized in memory.
+func SetCacheDir(dir string) {
+	defaultCacheMu.Lock()
+	defer defaultCacheMu.Unlock()
+	defaultCache = NewCache(dir)
+}
+
+// DefaultCache returns the cache used by NewClient.
+func DefaultCache() *Cache {
+	defaultCacheMu.RLock()
+	defer defaultCacheMu.RUnlock()
+	return defaultCache
+}
+
+// DefaultCacheDir returns the default directory of the persistent cache below the user cache directory.
+func DefaultCacheDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error determining user cache directory: %w", err)
+	}
+	return filepath.Join(userCacheDir, DefaultCacheDirName), nil
+}
+
+// NewCachingClient wraps client so that component versions and directory resources are served from cache.
+func NewCachingClient(client Client, cache *Cache) Client {
+	return &cachingClient{
+		client: client,
+		cache:  cache,
+	}
+}
+
+// cachingClient implements Client by serving requests from a Cache and delegating cache misses.
+type cachingClient struct {
+	client Client
+	cache  *Cache
+}
+
+var _ Client = (*cachingClient)(nil)
+
+func (c *cachingClient) GetComponentVersion(ctx context.Context, componentReference string) (*ComponentVersion, error) {
+	logger := log.GetLogger()
+
+	repository, componentName, version, err := parseComponentReference(componentReference)
+	if err != nil {
+		return nil, err
+	}
+	key := cacheKey(repository, componentName, version)
+
+	cv, err := c.cache.getMemo(key)
+	if err != nil {
+		return nil, err
+	}
+	if cv != nil {
+		logger.Tracef("Component version %s served from memory", componentReference)
+		return cv, nil
+	}
+
+	persist := version != "" && c.cache.dir != "" && !isLocalRepository(repository)
+	descriptorPath := filepath.Join(c.cache.dir, cacheDescriptorsDir, cacheHash(key)+".yaml")
+
+	if persist {
+		cv, err := readCachedComponentVersion(descriptorPath)
+		if err != nil {
+			logger.Debugf("Ignoring cached component version %s: %v", componentReference, err)
+		}
+		if cv != nil {
+			logger.Tracef("Component version %s served from cache %s", componentReference, descriptorPath)
+			if err = c.cache.setMemo(key, cv); err != nil {
+				return nil, err
+			}
+			return cv, nil
+		}
+	}
+
+	cv, err = c.client.GetComponentVersion(ctx, componentReference)
+	if err != nil {
+		return nil, err
+	}
+
+	if persist {
+		if err = writeCachedComponentVersion(descriptorPath, cv); err != nil {
+			logger.Debugf("Failed to cache component version %s: %v", componentReference, err)
+		}
+	}
+	if err = c.cache.setMemo(key, cv); err != nil {
+		return nil, err
+	}
+	return cv, nil
+}
+
+func (c *cachingClient) ListComponentVersions(ctx context.Context, repository, componentName string) ([]string, error) {
+	// the available versions change over time and are never cached
+	return c.client.ListComponentVersions(ctx, repository, componentName)
+}
+
+func (c *cachingClient) DownloadDirectoryResource(ctx context.Context, componentLocation, resourceName, downloadDir string) error {
+	logger := log.GetLogger()
+
+	_, _, version, err := parseComponentReference(componentLocation)
+	if err != nil {
+		return err
+	}
+
+	if version == "" || c.cache.dir == "" {
+		return c.client.DownloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir)
+	}
+
+	digest, err := c.resourceDigest(ctx, componentLocation, resourceName)
+	if err != nil {
+		return err
+	}
+	if digest == nil {
+		logger.Debugf("Resource %s of component version %s has no digest and is not cached", resourceName, componentLocation)
+		return c.client.DownloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir)
+	}
+
+	resourcesDir := filepath.Join(c.cache.dir, cacheResourcesDir)
+	resourceDir := filepath.Join(resourcesDir, cacheHash(digest.HashAlgorithm+":"+digest.Value))
+
+	if _, err = os.Stat(resourceDir); err == nil {
+		logger.Tracef("Resource %s of component version %s served from cache %s", resourceName, componentLocation, resourceDir)
+		return util.CopyDir(resourceDir, downloadDir)
+	}
+
+	if err = os.MkdirAll(resourcesDir, 0o755); err != nil {
+		logger.Debugf("Failed to create resource cache directory %s: %v", resourcesDir, err)
+		return c.client.DownloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir)
+	}
+
+	// download into a temporary directory next to the final location, so that the cache entry appears atomically
+	tempDir, err := os.MkdirTemp(resourcesDir, ".download-")
+	if err != nil {
+		logger.Debugf("Failed to create temporary directory in resource cache %s: %v", resourcesDir, err)
+		return c.client.DownloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir)
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+
+	entryDir := filepath.Join(tempDir, "entry")
+	if err = c.client.DownloadDirectoryResource(ctx, componentLocation, resourceName, entryDir); err != nil {
+		return err
+	}
+
+	if err = os.Rename(entryDir, resourceDir); err != nil {
+		logger.Debugf("Failed to cache resource %s of component version %s: %v", resourceName, componentLocation, err)
+		return util.CopyDir(entryDir, downloadDir)
+	}
+
+	return util.CopyDir(resourceDir, downloadDir)
+}
+
+// resourceDigest returns the digest of the resource in the component descriptor, or nil if the resource has no digest.
+func (c *cachingClient) resourceDigest(ctx context.Context, componentLocation, resourceName string) (*DigestSpec, error) {
+	cv, err := c.GetComponentVersion(ctx, componentLocation)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range cv.Component.Resources {
+		if resource.Name == resourceName {
+			if resource.Digest == nil || resource.Digest.Value == "" {
+				return nil, nil
+			}
+			return resource.Digest, nil
+		}
+	}
+	return nil, fmt.Errorf("resource %s not found in component version %s", resourceName, componentLocation)
+}
+
+func (c *cachingClient) DownloadResource(ctx context.Context, componentLocation, resourceName, outputFile string) error {
+	// raw resources are usually OCI artifacts, which are too large to be duplicated into the cache
+	return c.client.DownloadResource(ctx, componentLocation, resourceName, outputFile)
+}
+
+func (c *cachingClient) VerifyComponentVersion(ctx context.Context, componentReference string, options VerifyOptions) error {
+	// verification must always read the component descriptors from the repository
+	return c.client.VerifyComponentVersion(ctx, componentReference, options)
+}
+
+// getMemo returns a copy of the memoized component version, or nil if it is not memoized.
+func (c *Cache) getMemo(key string) (*ComponentVersion, error) {
+	c.memoMu.Lock()
+	data, ok := c.memo[key]
+	c.memoMu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	cv := &ComponentVersion{}
+	if err := json.Unmarshal(data, cv); err != nil {
+		return nil, fmt.Errorf("error decoding memoized component version: %w", err)
+	}
+	return cv, nil
+}
+
+// setMemo memoizes a copy of the component version.
+func (c *Cache) setMemo(key string, cv *ComponentVersion) error {
+	data, err := json.Marshal(cv)
+	if err != nil {
+		return fmt.Errorf("error encoding component version %s:%s: %w", cv.Component.Name, cv.Component.Version, err)
+	}
+
+	c.memoMu.Lock()
+	defer c.memoMu.Unlock()
+	c.memo[key] = data
+	return nil
+}
+
+// isLocalRepository returns true if the repository is a CTF on the local file system.
+func isLocalRepository(repository string) bool {
+	_, ref := splitRepositoryType(repository)
+	return isLocalPath(ref)
+}
+
+// cacheKey returns the key of a component version. Local repositories are keyed by their absolute path.
+func cacheKey(repository, componentName, version string) string {
+	repositoryType, ref := splitRepositoryType(repository)
+	if isLocalPath(ref) {
+		if abs, err := filepath.Abs(ref); err == nil {
+			ref = abs
+		}
+	}
+	if repositoryType != "" {
+		ref = repositoryType + "::" + ref
+	}
+	return ref + "//" + componentName + ":" + version
+}
+
+func cacheHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func readCachedComponentVersion(path string) (*ComponentVersion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cv := &ComponentVersion{}
+	if err = yaml.Unmarshal(data, cv); err != nil {
+		return nil, err
+	}
+	return cv, nil
+}
+
+func writeCachedComponentVersion(path string, cv *ComponentVersion) error {
+	data, err := yaml.Marshal(cv)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that concurrent runs never read a partially written entry
+	tempFile, err := os.CreateTemp(filepath.Dir(path), ".descriptor-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tempFile.Name())
+	}()
+
+	if _, err = tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
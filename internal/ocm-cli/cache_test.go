@@ -0,0 +1,214 @@
+package ocm_cli_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// countingClient is a fake OCM client that counts the requests it serves.
+type countingClient struct {
+	getCalls      int
+	downloadCalls int
+	// digestSuffix is appended to the digests of the resources, e.g. to simulate a rebuilt component version
+	digestSuffix string
+}
+
+func (c *countingClient) GetComponentVersion(_ context.Context, componentReference string) (*ocmcli.ComponentVersion, error) {
+	c.getCalls++
+	repository, nameAndVersion, _ := strings.Cut(componentReference, "//")
+	name, version, _ := strings.Cut(nameAndVersion, ":")
+	if name == "unknown-component" {
+		return nil, fmt.Errorf("component %s not found", name)
+	}
+	if version == "" {
+		version = "v0.0.1"
+	}
+	return &ocmcli.ComponentVersion{
+		Repository: repository,
+		Component: ocmcli.Component{
+			Name:    name,
+			Version: version,
+			Resources: []ocmcli.Resource{
+				{Name: "templates", Digest: &ocmcli.DigestSpec{HashAlgorithm: "SHA-256", Value: "templates-" + c.digestSuffix}},
+				{Name: "other", Digest: &ocmcli.DigestSpec{HashAlgorithm: "SHA-256", Value: "other-" + c.digestSuffix}},
+				{Name: "undigested"},
+			},
+		},
+	}, nil
+}
+
+func (c *countingClient) ListComponentVersions(_ context.Context, _, _ string) ([]string, error) {
+	return []string{"v0.0.1"}, nil
+}
+
+func (c *countingClient) DownloadDirectoryResource(_ context.Context, _, resourceName, downloadDir string) error {
+	c.downloadCalls++
+	if err := os.MkdirAll(downloadDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(downloadDir, "resource.yaml"), []byte(resourceName), 0o644)
+}
+
+func (c *countingClient) DownloadResource(_ context.Context, _, resourceName, outputFile string) error {
+	return os.WriteFile(outputFile, []byte(resourceName), 0o644)
+}
+
+func (c *countingClient) VerifyComponentVersion(_ context.Context, _ string, _ ocmcli.VerifyOptions) error {
+	return nil
+}
+
+func TestCachingClientGetComponentVersion(t *testing.T) {
+	const reference = "ghcr.io/openmcp-project//github.com/openmcp-project/test:v0.0.1"
+
+	cacheDir := t.TempDir()
+	backend := &countingClient{}
+
+	client := ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+	for range 3 {
+		cv, err := client.GetComponentVersion(t.Context(), reference)
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/openmcp-project/test", cv.Component.Name)
+	}
+	assert.Equal(t, 1, backend.getCalls, "component version should be memoized")
+
+	// a new cache on the same directory simulates a subsequent run
+	client = ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+	cv, err := client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.0.1", cv.Component.Version)
+	assert.Equal(t, "ghcr.io/openmcp-project", cv.Repository)
+	assert.Equal(t, 1, backend.getCalls, "component version should be served from the persistent cache")
+
+	// a different version is a different cache entry
+	_, err = client.GetComponentVersion(t.Context(), "ghcr.io/openmcp-project//github.com/openmcp-project/test:v0.0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, backend.getCalls)
+
+	// errors are not cached
+	for range 2 {
+		_, err = client.GetComponentVersion(t.Context(), "ghcr.io/openmcp-project//unknown-component:v0.0.1")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 4, backend.getCalls)
+}
+
+func TestCachingClientWithoutVersion(t *testing.T) {
+	const reference = "ghcr.io/openmcp-project//github.com/openmcp-project/test"
+
+	cacheDir := t.TempDir()
+	backend := &countingClient{}
+
+	client := ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+	_, err := client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	_, err = client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.getCalls, "component version should be memoized")
+
+	client = ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+	_, err = client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, backend.getCalls, "references without version must not be persisted")
+}
+
+func TestCachingClientNoPersistentCache(t *testing.T) {
+	const reference = "ghcr.io/openmcp-project//github.com/openmcp-project/test:v0.0.1"
+
+	backend := &countingClient{}
+
+	client := ocmcli.NewCachingClient(backend, ocmcli.NewCache(""))
+	_, err := client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	_, err = client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, backend.getCalls, "component version should be memoized")
+
+	client = ocmcli.NewCachingClient(backend, ocmcli.NewCache(""))
+	_, err = client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, backend.getCalls)
+
+	err = client.DownloadDirectoryResource(t.Context(), reference, "templates", t.TempDir())
+	assert.NoError(t, err)
+	err = client.DownloadDirectoryResource(t.Context(), reference, "templates", t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, backend.downloadCalls)
+}
+
+func TestCachingClientDownloadDirectoryResource(t *testing.T) {
+	const reference = "ghcr.io/openmcp-project//github.com/openmcp-project/test:v0.0.1"
+
+	cacheDir := t.TempDir()
+	backend := &countingClient{}
+
+	for range 2 {
+		client := ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+		downloadDir := filepath.Join(t.TempDir(), "download")
+
+		err := client.DownloadDirectoryResource(t.Context(), reference, "templates", downloadDir)
+		assert.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(downloadDir, "resource.yaml"))
+		assert.NoError(t, err)
+		assert.Equal(t, "templates", string(content))
+	}
+	assert.Equal(t, 1, backend.downloadCalls, "resource should be served from the persistent cache")
+
+	client := ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+	err := client.DownloadDirectoryResource(t.Context(), reference, "other", t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, backend.downloadCalls, "a different resource is a different cache entry")
+
+	err = client.DownloadDirectoryResource(t.Context(), reference, "undigested", t.TempDir())
+	assert.NoError(t, err)
+	err = client.DownloadDirectoryResource(t.Context(), reference, "undigested", t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, backend.downloadCalls, "resources without digest must not be persisted")
+}
+
+func TestCachingClientLocalRepository(t *testing.T) {
+	reference := filepath.Join(t.TempDir(), "ctf") + "//github.com/openmcp-project/test:v0.0.1"
+
+	cacheDir := t.TempDir()
+	backend := &countingClient{}
+
+	client := ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+	_, err := client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.NoError(t, client.DownloadDirectoryResource(t.Context(), reference, "templates", t.TempDir()))
+	assert.Equal(t, 1, backend.getCalls)
+	assert.Equal(t, 1, backend.downloadCalls)
+
+	// the CTF is rebuilt with the same version, but different resource contents
+	backend.digestSuffix = "rebuilt"
+	client = ocmcli.NewCachingClient(backend, ocmcli.NewCache(cacheDir))
+	cv, err := client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.Equal(t, "templates-rebuilt", cv.Component.Resources[0].Digest.Value)
+	assert.Equal(t, 2, backend.getCalls, "component versions of local repositories must not be persisted")
+	assert.NoError(t, client.DownloadDirectoryResource(t.Context(), reference, "templates", t.TempDir()))
+	assert.Equal(t, 2, backend.downloadCalls, "resources are cached by their digest")
+}
+
+func TestCachingClientReturnsCopies(t *testing.T) {
+	const reference = "ghcr.io/openmcp-project//github.com/openmcp-project/test:v0.0.1"
+
+	client := ocmcli.NewCachingClient(&countingClient{}, ocmcli.NewCache(""))
+	cv, err := client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	cv.Component.Version = "modified"
+	cv.Component.Resources[0].Digest.Value = "modified"
+
+	cv, err = client.GetComponentVersion(t.Context(), reference)
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.0.1", cv.Component.Version)
+	assert.Equal(t, "templates-", cv.Component.Resources[0].Digest.Value)
+}
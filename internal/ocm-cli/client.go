@@ -0,0 +1,154 @@
+package ocm_cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Backend selects the implementation that is used to access OCM repositories.
+type Backend string
+
+const (
+	// BackendNative resolves component versions and downloads resources in-process.
+	BackendNative Backend = "native"
+	// BackendExec shells out to the ocm CLI, which must be available in the PATH.
+	BackendExec Backend = "exec"
+)
+
+var (
+	defaultBackend   = BackendNative
+	defaultBackendMu sync.RWMutex
+)
+
+// Client provides access to component versions and their resources stored in OCM repositories.
+type Client interface {
+	// GetComponentVersion retrieves a component version by its reference in the format <repo>//<component>:<version>.
+	GetComponentVersion(ctx context.Context, componentReference string) (*ComponentVersion, error)
+	// ListComponentVersions lists all versions of the component with the given name in the given repository.
+	ListComponentVersions(ctx context.Context, repository, componentName string) ([]string, error)
+	// DownloadDirectoryResource downloads the resource with the given name of the component version at
+	// componentLocation and extracts the contained directory tree into downloadDir.
+	DownloadDirectoryResource(ctx context.Context, componentLocation, resourceName, downloadDir string) error
+	// DownloadResource downloads the unmodified content of the resource with the given name of the component version at
+	// componentLocation into outputFile.
+	DownloadResource(ctx context.Context, componentLocation, resourceName, outputFile string) error
+	// VerifyComponentVersion verifies the signatures of the component version with the given reference, and the digests of
+	// its resources and of all component versions it references, directly or transitively.
+	VerifyComponentVersion(ctx context.Context, componentReference string, options VerifyOptions) error
+}
+
+// SetDefaultBackend sets the backend that is used by NewClient.
+func SetDefaultBackend(backend string) error {
+	b := Backend(strings.ToLower(strings.TrimSpace(backend)))
+	if b != BackendNative && b != BackendExec {
+		return fmt.Errorf("unsupported OCM backend %q, supported backends are %q and %q", backend, BackendNative, BackendExec)
+	}
+
+	defaultBackendMu.Lock()
+	defer defaultBackendMu.Unlock()
+	defaultBackend = b
+	return nil
+}
+
+// DefaultBackend returns the backend that is used by NewClient.
+func DefaultBackend() Backend {
+	defaultBackendMu.RLock()
+	defer defaultBackendMu.RUnlock()
+	return defaultBackend
+}
+
+// NewClient creates a client for the default backend, which is served from the default cache.
+// The `ocmConfig` parameter is the path to the OCM configuration file. Passing `NoOcmConfig` indicates that no configuration file should be used.
+func NewClient(ocmConfig string) (Client, error) {
+	client, err := NewClientForBackend(DefaultBackend(), ocmConfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewCachingClient(client, DefaultCache()), nil
+}
+
+// newUncachedClient creates a client for the default backend, which memoizes component versions in memory only.
+func newUncachedClient(ocmConfig string) (Client, error) {
+	client, err := NewClientForBackend(DefaultBackend(), ocmConfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewCachingClient(client, NewCache("")), nil
+}
+
+// NewClientForBackend creates a client for the given backend.
+func NewClientForBackend(backend Backend, ocmConfig string) (Client, error) {
+	switch backend {
+	case BackendExec:
+		return &execClient{ocmConfig: ocmConfig}, nil
+	case BackendNative:
+		return newNativeClient(ocmConfig)
+	default:
+		return nil, fmt.Errorf("unsupported OCM backend %q", backend)
+	}
+}
+
+// execClient implements Client by executing the ocm CLI.
+type execClient struct {
+	ocmConfig string
+}
+
+var _ Client = (*execClient)(nil)
+
+func (c *execClient) GetComponentVersion(ctx context.Context, componentReference string) (*ComponentVersion, error) {
+	out, err := ExecuteOutput(ctx, []string{"get", "componentversion", componentReference}, []string{"--output", "yaml"}, c.ocmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cv, err := decodeComponentVersion(out)
+	if err != nil {
+		return nil, err
+	}
+
+	cv.Repository = strings.SplitN(componentReference, "//", 2)[0]
+
+	return cv, nil
+}
+
+func (c *execClient) ListComponentVersions(ctx context.Context, repository, componentName string) ([]string, error) {
+	out, err := ExecuteOutput(ctx, []string{"list", "componentversion", repository + "//" + componentName}, []string{"--output", "yaml"}, c.ocmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeComponentVersionList(out)
+}
+
+func (c *execClient) DownloadDirectoryResource(ctx context.Context, componentLocation, resourceName, downloadDir string) error {
+	return Execute(ctx,
+		[]string{"download", "resources", componentLocation, resourceName},
+		[]string{"--downloader", "ocm/dirtree", "--outfile", downloadDir},
+		c.ocmConfig,
+	)
+}
+
+func (c *execClient) DownloadResource(ctx context.Context, componentLocation, resourceName, outputFile string) error {
+	return Execute(ctx,
+		[]string{"download", "resources", componentLocation, resourceName},
+		[]string{"--outfile", outputFile},
+		c.ocmConfig,
+	)
+}
+
+func (c *execClient) VerifyComponentVersion(ctx context.Context, componentReference string, options VerifyOptions) error {
+	var args []string
+	for _, signature := range options.Signatures {
+		args = append(args, "--signature", signature.Name)
+		if signature.PublicKeyPath != "" {
+			args = append(args, "--public-key", signature.Name+"="+signature.PublicKeyPath)
+		}
+		if signature.CACertPath != "" {
+			args = append(args, "--ca-cert", signature.CACertPath)
+		}
+	}
+
+	return Execute(ctx, []string{"verify", "componentversions", componentReference}, args, c.ocmConfig)
+}
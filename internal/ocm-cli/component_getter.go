@@ -14,9 +14,15 @@ type ComponentGetter struct {
 	// Path to the deployment templates resource in the format <componentRef1>/.../<componentRefN>/<resourceName>.
 	deploymentTemplates string
 	ocmConfig           string
+	// verification is the signature verification of the root component version, if configured.
+	verification *VerifyOptions
 
 	// Fields derived during InitializeComponents
-	repo string
+	// versionConstraint is the version constraint of the root component location, if it has been resolved to an exact version.
+	versionConstraint string
+	repo              string
+	client            Client
+	graph             *ComponentGraph
 
 	rootComponentVersion       *ComponentVersion
 	templatesComponentVersion  *ComponentVersion
@@ -32,6 +38,13 @@ func NewComponentGetter(rootComponentLocation, deploymentTemplates, ocmConfig st
 	}
 }
 
+// SetVerification configures the signature verification of the root component version.
+// With a verification, InitializeComponents verifies the root component version before it is used, and the persistent cache is bypassed,
+// so that only component descriptors and resources read from the repository are used.
+func (g *ComponentGetter) SetVerification(options VerifyOptions) {
+	g.verification = &options
+}
+
 func (g *ComponentGetter) InitializeComponents(ctx context.Context) error {
 	var err error
 
@@ -40,12 +53,46 @@ func (g *ComponentGetter) InitializeComponents(ctx context.Context) error {
 		return err
 	}
 
-	rootComponentVersion, err := GetComponentVersion(ctx, g.rootComponentLocation, g.ocmConfig)
+	if g.verification != nil {
+		if dir := DefaultCache().Dir(); dir != "" {
+			log.GetLogger().Warnf("Bypassing the persistent cache %s, as component versions with signature verification are always read from their repository", dir)
+		}
+		g.client, err = newUncachedClient(g.ocmConfig)
+	} else {
+		g.client, err = NewClient(g.ocmConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating OCM client: %w", err)
+	}
+
+	resolvedLocation, versionConstraint, err := resolveComponentLocation(ctx, g.client, g.rootComponentLocation)
+	if err != nil {
+		return fmt.Errorf("error resolving root component location %s: %w", g.rootComponentLocation, err)
+	}
+	if versionConstraint != "" {
+		log.GetLogger().Infof("Resolved version constraint %q of root component location %s to %s", versionConstraint, g.rootComponentLocation, resolvedLocation)
+		g.rootComponentLocation = resolvedLocation
+		g.versionConstraint = versionConstraint
+	}
+
+	if g.verification != nil {
+		if err = g.client.VerifyComponentVersion(ctx, g.rootComponentLocation, *g.verification); err != nil {
+			return fmt.Errorf("error verifying component version %s: %w", g.rootComponentLocation, err)
+		}
+		log.GetLogger().Infof("Verified signatures of component version %s and all referenced component versions", g.rootComponentLocation)
+	}
+
+	rootComponentVersion, err := g.client.GetComponentVersion(ctx, g.rootComponentLocation)
 	if err != nil {
 		return fmt.Errorf("error getting root component version %s: %w", g.rootComponentLocation, err)
 	}
 	g.rootComponentVersion = rootComponentVersion
 
+	g.graph, err = ResolveComponentGraph(ctx, g.client, g.repo, g.rootComponentVersion, DefaultMaxConcurrentResolutions)
+	if err != nil {
+		return fmt.Errorf("error resolving component references of root component version %s: %w", g.rootComponentLocation, err)
+	}
+
 	g.deploymentTemplates = strings.TrimSpace(g.deploymentTemplates)
 	segments := strings.Split(g.deploymentTemplates, "/")
 	if len(segments) == 0 {
@@ -71,6 +118,18 @@ func (g *ComponentGetter) InitializeComponents(ctx context.Context) error {
 	return nil
 }
 
+// RootComponentLocation returns the location of the root component in the format <repo>//<component>:<version>.
+// After InitializeComponents, a version constraint in the location is replaced by the resolved version.
+func (g *ComponentGetter) RootComponentLocation() string {
+	return g.rootComponentLocation
+}
+
+// VersionConstraint returns the version constraint of the root component location, e.g. "~0.0.18" or "latest",
+// or an empty string if the location contains an exact version.
+func (g *ComponentGetter) VersionConstraint() string {
+	return g.versionConstraint
+}
+
 func (g *ComponentGetter) RootComponentVersion() *ComponentVersion {
 	return g.rootComponentVersion
 }
@@ -91,6 +150,16 @@ func (g *ComponentGetter) OCMConfig() string {
 	return g.ocmConfig
 }
 
+// Client returns the OCM client used by the ComponentGetter.
+func (g *ComponentGetter) Client() Client {
+	return g.client
+}
+
+// Graph returns the resolved graph of the root component version and all component versions it references.
+func (g *ComponentGetter) Graph() *ComponentGraph {
+	return g.graph
+}
+
 func (g *ComponentGetter) GetReferencedComponentVersions(ctx context.Context, parentCV *ComponentVersion, refName string) ([]ComponentVersion, error) {
 	logger := log.GetLogger()
 	logger.Tracef("Comp_Getter: Getting component reference %s in component version %s", refName, parentCV.Component.Name)
@@ -99,10 +168,9 @@ func (g *ComponentGetter) GetReferencedComponentVersions(ctx context.Context, pa
 	componentVersions := make([]ComponentVersion, 0, len(refs))
 
 	for _, ref := range refs {
-		location := buildLocation(g.repo, ref.ComponentName, ref.Version)
-		cv, err := GetComponentVersion(ctx, location, g.ocmConfig)
+		cv, err := g.getComponentVersion(ctx, ref.ComponentName, ref.Version)
 		if err != nil {
-			return nil, fmt.Errorf("error getting component version %s: %w", location, err)
+			return nil, err
 		}
 		componentVersions = append(componentVersions, *cv)
 	}
@@ -111,6 +179,49 @@ func (g *ComponentGetter) GetReferencedComponentVersions(ctx context.Context, pa
 	return componentVersions, nil
 }
 
+// GetComponentVersion returns the component version with the given name and version from the repository of the root component.
+// The version may be a version constraint, e.g. "~0.1.0", which is resolved against the versions in the repository.
+func (g *ComponentGetter) GetComponentVersion(ctx context.Context, componentName, version string) (*ComponentVersion, error) {
+	if IsVersionConstraint(version) {
+		versions, err := g.client.ListComponentVersions(ctx, g.repo, componentName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing versions of component %s: %w", componentName, err)
+		}
+		constraint := version
+		version, err = ResolveVersionConstraint(constraint, versions)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving version of component %s: %w", componentName, err)
+		}
+		log.GetLogger().Debugf("Resolved version constraint %q of component %s to %s", constraint, componentName, version)
+	}
+	return g.getComponentVersion(ctx, componentName, version)
+}
+
+// getComponentVersion returns the component version from the resolved graph.
+// Component versions outside the graph are fetched from the repository. They are not covered by the signature of the root component version,
+// so with a verification, their own signatures are verified before they are used.
+func (g *ComponentGetter) getComponentVersion(ctx context.Context, componentName, version string) (*ComponentVersion, error) {
+	if g.graph != nil {
+		cv, found, err := g.graph.Lookup(componentName, version)
+		if found {
+			return cv, err
+		}
+	}
+
+	location := buildLocation(g.repo, componentName, version)
+	if g.verification != nil {
+		if err := g.client.VerifyComponentVersion(ctx, location, *g.verification); err != nil {
+			return nil, fmt.Errorf("error verifying component version %s: %w", location, err)
+		}
+		log.GetLogger().Infof("Verified signatures of component version %s, which is not referenced by the root component version", location)
+	}
+	cv, err := g.client.GetComponentVersion(ctx, location)
+	if err != nil {
+		return nil, fmt.Errorf("error getting component version %s: %w", location, err)
+	}
+	return cv, nil
+}
+
 func (g *ComponentGetter) GetReferencedComponentVersionsRecursive(ctx context.Context, parentCV *ComponentVersion, refName string) ([]ComponentVersion, error) {
 	logger := log.GetLogger()
 	logger.Tracef("Comp_Getter: Searching for component reference %s in component version %s", refName, parentCV.Component.Name)
@@ -170,7 +281,7 @@ func (g *ComponentGetter) GetComponentVersionsForResourceRecursive(ctx context.C
 }
 
 func (g *ComponentGetter) DownloadTemplatesResource(ctx context.Context, downloadDir string) error {
-	return downloadDirectoryResource(ctx, g.templatesComponentLocation, g.templatesResourceName, downloadDir, g.ocmConfig)
+	return g.client.DownloadDirectoryResource(ctx, g.templatesComponentLocation, g.templatesResourceName, downloadDir)
 }
 
 func (g *ComponentGetter) DownloadDirectoryResourceByLocation(ctx context.Context, rootCV *ComponentVersion, location string, downloadDir string) error {
@@ -196,20 +307,12 @@ func (g *ComponentGetter) DownloadDirectoryResourceByLocation(ctx context.Contex
 	}
 
 	componentLocation := buildLocation(g.repo, cv.Component.Name, cv.Component.Version)
-	return downloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir, g.ocmConfig)
+	return g.client.DownloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir)
 }
 
 func (g *ComponentGetter) DownloadDirectoryResource(ctx context.Context, cv *ComponentVersion, resourceName string, downloadDir string) error {
 	componentLocation := buildLocation(g.repo, cv.Component.Name, cv.Component.Version)
-	return downloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir, g.ocmConfig)
-}
-
-func downloadDirectoryResource(ctx context.Context, componentLocation string, resourceName string, downloadDir string, ocmConfig string) error {
-	return Execute(ctx,
-		[]string{"download", "resources", componentLocation, resourceName},
-		[]string{"--downloader", "ocm/dirtree", "--outfile", downloadDir},
-		ocmConfig,
-	)
+	return g.client.DownloadDirectoryResource(ctx, componentLocation, resourceName, downloadDir)
 }
 
 func extractRepoFromLocation(location string) (string, error) {
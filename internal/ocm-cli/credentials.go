@@ -0,0 +1,136 @@
+package ocm_cli
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+const (
+	ociRegistryConsumerType = "OCIRegistry"
+
+	identityKeyType       = "type"
+	identityKeyHostname   = "hostname"
+	identityKeyPort       = "port"
+	identityKeyPathPrefix = "pathprefix"
+
+	credentialPropertyUsername      = "username"
+	credentialPropertyPassword      = "password"
+	credentialPropertyIdentityToken = "identityToken"
+)
+
+// ocmConfigKeychain resolves registry credentials from the consumers and the DockerConfig repositories of an OCM configuration.
+type ocmConfigKeychain struct {
+	consumers     []OCMConfigConsumer
+	dockerConfigs []*configfile.ConfigFile
+}
+
+var _ authn.Keychain = (*ocmConfigKeychain)(nil)
+
+// NewKeychain returns a keychain that resolves registry credentials from the OCM configuration file ocmConfig.
+// Passing `NoOcmConfig` returns a keychain that uses anonymous access.
+func NewKeychain(ocmConfig string) (authn.Keychain, error) {
+	if ocmConfig == NoOcmConfig {
+		return authn.NewMultiKeychain(), nil
+	}
+
+	config, err := readOCMConfig(ocmConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCM configuration: %w", err)
+	}
+	keychain, err := newOCMConfigKeychain(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCM configuration: %w", err)
+	}
+	return keychain, nil
+}
+
+func newOCMConfigKeychain(config *OCMConfiguration) (*ocmConfigKeychain, error) {
+	k := &ocmConfigKeychain{}
+	for _, typedConfig := range config.Configurations {
+		if !strings.HasPrefix(typedConfig.Type, CredentialsConfigType) {
+			continue
+		}
+		k.consumers = append(k.consumers, typedConfig.Consumers...)
+
+		for _, repo := range typedConfig.Repositories {
+			if repo.Repository == nil || !strings.Contains(repo.Repository.Type, DockerConfigRepositoryType) {
+				continue
+			}
+			dockerConfig, err := loadDockerConfig(repo.Repository)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s repository: %w", repo.Repository.Type, err)
+			}
+			k.dockerConfigs = append(k.dockerConfigs, dockerConfig)
+		}
+	}
+	return k, nil
+}
+
+// Resolve returns the credentials of the most specific OCIRegistry consumer matching the resource.
+// If no consumer matches, the credentials of the first DockerConfig repository that has credentials for the registry are used.
+// Otherwise, anonymous access is used.
+func (k *ocmConfigKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	registry := resource.RegistryStr()
+	host, port, err := net.SplitHostPort(registry)
+	if err != nil {
+		host = registry
+		port = ""
+	}
+	path := strings.TrimPrefix(strings.TrimPrefix(resource.String(), registry), "/")
+
+	var (
+		match       *OCMConfigConsumer
+		matchPrefix = -1
+	)
+	for i := range k.consumers {
+		consumer := &k.consumers[i]
+		identity := consumer.Identity
+		if identity[identityKeyType] != ociRegistryConsumerType || identity[identityKeyHostname] != host {
+			continue
+		}
+		if identity[identityKeyPort] != "" && identity[identityKeyPort] != port {
+			continue
+		}
+		prefix := strings.Trim(identity[identityKeyPathPrefix], "/")
+		if prefix != "" && path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		if len(prefix) > matchPrefix {
+			match = consumer
+			matchPrefix = len(prefix)
+		}
+	}
+
+	if match == nil {
+		for _, dockerConfig := range k.dockerConfigs {
+			authConfig, err := resolveDockerConfigCredentials(dockerConfig, registry)
+			if err != nil {
+				return nil, err
+			}
+			if authConfig != nil {
+				return authn.FromConfig(*authConfig), nil
+			}
+		}
+		return authn.Anonymous, nil
+	}
+
+	authConfig := authn.AuthConfig{}
+	for _, credential := range match.Credentials {
+		authConfig.Username = valueOrDefault(credential.Properties[credentialPropertyUsername], authConfig.Username)
+		authConfig.Password = valueOrDefault(credential.Properties[credentialPropertyPassword], authConfig.Password)
+		authConfig.IdentityToken = valueOrDefault(credential.Properties[credentialPropertyIdentityToken], authConfig.IdentityToken)
+	}
+
+	return authn.FromConfig(authConfig), nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
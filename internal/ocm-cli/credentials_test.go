@@ -0,0 +1,127 @@
+package ocm_cli_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/assert"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+func TestKeychain(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		ocmConfig    string
+		image        string
+		expectedAuth authn.AuthConfig
+		expectError  bool
+	}{
+		{
+			desc:         "should use anonymous access without OCM configuration",
+			ocmConfig:    ocmcli.NoOcmConfig,
+			image:        "ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0",
+			expectedAuth: authn.AuthConfig{},
+		},
+		{
+			desc:         "should prefer the credentials of a matching consumer",
+			ocmConfig:    "./testdata/06/ocm-config-docker-config-file.yaml",
+			image:        "ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0",
+			expectedAuth: authn.AuthConfig{Username: "consumer-user", Password: "consumer-token"},
+		},
+		{
+			desc:         "should use the credentials of the docker config file",
+			ocmConfig:    "./testdata/06/ocm-config-docker-config-file.yaml",
+			image:        "ghcr.io/fluxcd/source-controller:v1.6.0",
+			expectedAuth: authn.AuthConfig{Username: "docker-user", Password: "docker-token"},
+		},
+		{
+			desc:         "should use the credentials of the docker config file for a registry with port",
+			ocmConfig:    "./testdata/06/ocm-config-docker-config-file.yaml",
+			image:        "registry.internal:5000/openmcp/openmcp-operator:v0.1.0",
+			expectedAuth: authn.AuthConfig{Username: "internal-user", Password: "internal-password"},
+		},
+		{
+			desc:         "should use the docker hub credentials of the docker config file",
+			ocmConfig:    "./testdata/06/ocm-config-docker-config-file.yaml",
+			image:        "fluxcd/source-controller:v1.6.0",
+			expectedAuth: authn.AuthConfig{Username: "hub-user", Password: "hub-password"},
+		},
+		{
+			desc:         "should use anonymous access for a registry without credentials",
+			ocmConfig:    "./testdata/06/ocm-config-docker-config-file.yaml",
+			image:        "quay.io/jetstack/cert-manager-controller:v1.18.0",
+			expectedAuth: authn.AuthConfig{},
+		},
+		{
+			desc:         "should use the credentials of an inline docker config",
+			ocmConfig:    "./testdata/06/ocm-config-docker-config-inline.yaml",
+			image:        "ghcr.io/fluxcd/source-controller:v1.6.0",
+			expectedAuth: authn.AuthConfig{Username: "inline-user", Password: "inline-token"},
+		},
+		{
+			desc:        "should fail for a missing docker config file",
+			ocmConfig:   "./testdata/01/missing-docker-config-ocm-config.yaml",
+			expectError: true,
+		},
+		{
+			desc:        "should fail for an invalid docker config file",
+			ocmConfig:   "./testdata/06/ocm-config-docker-config-invalid.yaml",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			keychain, err := ocmcli.NewKeychain(tc.ocmConfig)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			ref, err := name.ParseReference(tc.image)
+			assert.NoError(t, err)
+			authenticator, err := keychain.Resolve(ref.Context())
+			if !assert.NoError(t, err) {
+				return
+			}
+			authConfig, err := authenticator.Authorization()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expectedAuth, *authConfig)
+			}
+		})
+	}
+}
+
+func TestDockerConfigJSON(t *testing.T) {
+	data, err := ocmcli.DockerConfigJSON("./testdata/06/ocm-config-docker-config-file.yaml", []string{"ghcr.io", "registry.internal:5000"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var dockerConfig struct {
+		Auths map[string]map[string]string `json:"auths"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &dockerConfig))
+	assert.Equal(t, map[string]map[string]string{
+		"ghcr.io": {
+			"username": "docker-user",
+			"password": "docker-token",
+			"auth":     base64.StdEncoding.EncodeToString([]byte("docker-user:docker-token")),
+		},
+		"registry.internal:5000": {
+			"username": "internal-user",
+			"password": "internal-password",
+			"auth":     base64.StdEncoding.EncodeToString([]byte("internal-user:internal-password")),
+		},
+	}, dockerConfig.Auths)
+
+	_, err = ocmcli.DockerConfigJSON("./testdata/06/ocm-config-docker-config-file.yaml", []string{"quay.io"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package ocm_cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// CTFArtifactIndexFileName is the name of the index file of a CTF directory.
+	CTFArtifactIndexFileName = "artifact-index.json"
+	// CTFBlobsDirectoryName is the name of the directory containing the blobs of a CTF directory.
+	CTFBlobsDirectoryName = "blobs"
+)
+
+// CTFArtifactIndex is the index of the artifacts stored in a CTF (Common Transport Format) directory.
+type CTFArtifactIndex struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	Artifacts     []CTFArtifact `json:"artifacts"`
+}
+
+// CTFArtifact is an entry of the CTF artifact index.
+type CTFArtifact struct {
+	// Repository is the OCI repository of the artifact, e.g. component-descriptors/<component name>.
+	Repository string `json:"repository"`
+	// Tag is the optional tag of the artifact.
+	Tag string `json:"tag,omitempty"`
+	// Digest is the digest of the artifact manifest.
+	Digest string `json:"digest"`
+}
+
+// ctfStore reads OCI artifacts from a CTF directory.
+// Packed CTF archives (tar, tgz) are not supported and must be extracted first.
+type ctfStore struct {
+	path  string
+	index CTFArtifactIndex
+}
+
+var _ componentStore = (*ctfStore)(nil)
+
+func openCTFStore(path string) (*ctfStore, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error accessing CTF %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("CTF %s is not a directory, packed CTF archives must be extracted first", path)
+	}
+
+	indexData, err := os.ReadFile(filepath.Join(path, CTFArtifactIndexFileName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading CTF artifact index: %w", err)
+	}
+
+	s := &ctfStore{path: path}
+	if err = json.Unmarshal(indexData, &s.index); err != nil {
+		return nil, fmt.Errorf("error unmarshalling CTF artifact index: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *ctfStore) ListTags(_ context.Context, repository string) ([]string, error) {
+	tags := make([]string, 0)
+	for _, artifact := range s.index.Artifacts {
+		if artifact.Repository == repository && artifact.Tag != "" {
+			tags = append(tags, artifact.Tag)
+		}
+	}
+	return tags, nil
+}
+
+func (s *ctfStore) GetManifest(ctx context.Context, repository, tag string) (*v1.Manifest, error) {
+	for _, artifact := range s.index.Artifacts {
+		if artifact.Repository != repository || artifact.Tag != tag {
+			continue
+		}
+
+		blob, err := s.GetBlob(ctx, repository, artifact.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest of %s:%s: %w", repository, tag, err)
+		}
+		data, err := io.ReadAll(blob)
+		_ = blob.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest of %s:%s: %w", repository, tag, err)
+		}
+
+		manifest, err := v1.ParseManifest(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing manifest of %s:%s: %w", repository, tag, err)
+		}
+		return manifest, nil
+	}
+
+	return nil, fmt.Errorf("artifact %s:%s not found in CTF %s", repository, tag, s.path)
+}
+
+func (s *ctfStore) GetBlob(_ context.Context, _ string, digest string) (io.ReadCloser, error) {
+	path, err := CTFBlobPath(s.path, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening blob %s: %w", digest, err)
+	}
+	return blob, nil
+}
+
+// CTFBlobPath returns the path of the blob with the given digest in the CTF directory ctfPath.
+func CTFBlobPath(ctfPath, digest string) (string, error) {
+	hash, err := v1.NewHash(digest)
+	if err != nil {
+		return "", fmt.Errorf("invalid blob digest %s: %w", digest, err)
+	}
+	return filepath.Join(ctfPath, CTFBlobsDirectoryName, hash.Algorithm+"."+hash.Hex), nil
+}
@@ -0,0 +1,148 @@
+package ocm_cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// ResolveDockerConfigPath returns the path of a docker config file referenced by a DockerConfig repository.
+// Like OCM, a leading "~" is expanded to the home directory of the user; relative paths are relative to the working directory.
+func ResolveDockerConfigPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error resolving home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return filepath.Abs(path)
+}
+
+// loadDockerConfig loads the docker config file or the inline docker config of a DockerConfig repository.
+func loadDockerConfig(repository *TypedOCMConfigRepository) (*configfile.ConfigFile, error) {
+	if len(repository.DockerConfig) > 0 && repository.DockerConfigFile != "" {
+		return nil, fmt.Errorf("only one of dockerConfigFile and dockerConfig may be set")
+	}
+
+	if len(repository.DockerConfig) > 0 {
+		data := []byte(repository.DockerConfig)
+		// the inline docker config may also be given as JSON string
+		var inline string
+		if err := json.Unmarshal(data, &inline); err == nil {
+			data = []byte(inline)
+		}
+		dockerConfig := configfile.New("")
+		if err := dockerConfig.LoadFromReader(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("error parsing inline docker config: %w", err)
+		}
+		return dockerConfig, nil
+	}
+
+	if repository.DockerConfigFile == "" {
+		return nil, fmt.Errorf("dockerConfigFile or dockerConfig is required")
+	}
+
+	path, err := ResolveDockerConfigPath(repository.DockerConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("docker config file does not exist: %s", path)
+		}
+		return nil, fmt.Errorf("error reading docker config file %s: %w", path, err)
+	}
+
+	dockerConfig := configfile.New(path)
+	if err = dockerConfig.LoadFromReader(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("error parsing docker config file %s: %w", path, err)
+	}
+	return dockerConfig, nil
+}
+
+// resolveDockerConfigCredentials returns the credentials of the docker config for the registry,
+// including credentials stored by credential helpers, or nil if there are none.
+func resolveDockerConfigCredentials(dockerConfig *configfile.ConfigFile, registry string) (*authn.AuthConfig, error) {
+	key := registry
+	if key == name.DefaultRegistry {
+		key = authn.DefaultAuthKey
+	}
+
+	authConfig, err := dockerConfig.GetAuthConfig(key)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credentials for registry %s from docker config %s: %w", registry, dockerConfig.Filename, err)
+	}
+	if authConfig.Username == "" && authConfig.Password == "" && authConfig.Auth == "" && authConfig.IdentityToken == "" && authConfig.RegistryToken == "" {
+		return nil, nil
+	}
+
+	return &authn.AuthConfig{
+		Username:      authConfig.Username,
+		Password:      authConfig.Password,
+		Auth:          authConfig.Auth,
+		IdentityToken: authConfig.IdentityToken,
+		RegistryToken: authConfig.RegistryToken,
+	}, nil
+}
+
+// DockerConfigJSON returns a docker config JSON with the credentials the OCM configuration file ocmConfig provides for the
+// given registries, e.g. "ghcr.io" or "registry.internal:5000". It can be used as data of an image pull secret of type
+// kubernetes.io/dockerconfigjson. Credentials are taken from the credential consumers and the DockerConfig repositories.
+func DockerConfigJSON(ocmConfig string, registries []string) ([]byte, error) {
+	keychain, err := NewKeychain(ocmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	type dockerConfigAuth struct {
+		Username      string `json:"username,omitempty"`
+		Password      string `json:"password,omitempty"`
+		Auth          string `json:"auth,omitempty"`
+		IdentityToken string `json:"identitytoken,omitempty"`
+		RegistryToken string `json:"registrytoken,omitempty"`
+	}
+	auths := make(map[string]dockerConfigAuth, len(registries))
+
+	for _, registry := range registries {
+		reg, err := name.NewRegistry(registry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry %s: %w", registry, err)
+		}
+
+		authenticator, err := keychain.Resolve(reg)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving credentials for registry %s: %w", registry, err)
+		}
+		authConfig, err := authenticator.Authorization()
+		if err != nil {
+			return nil, fmt.Errorf("error resolving credentials for registry %s: %w", registry, err)
+		}
+		if *authConfig == (authn.AuthConfig{}) {
+			return nil, fmt.Errorf("the OCM configuration contains no credentials for registry %s", registry)
+		}
+
+		auth := authConfig.Auth
+		if auth == "" && authConfig.Username != "" {
+			auth = base64.StdEncoding.EncodeToString([]byte(authConfig.Username + ":" + authConfig.Password))
+		}
+		auths[registry] = dockerConfigAuth{
+			Username:      authConfig.Username,
+			Password:      authConfig.Password,
+			Auth:          auth,
+			IdentityToken: authConfig.IdentityToken,
+			RegistryToken: authConfig.RegistryToken,
+		}
+	}
+
+	return json.Marshal(map[string]any{"auths": auths})
+}
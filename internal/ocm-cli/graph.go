@@ -0,0 +1,185 @@
+package ocm_cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+)
+
+const (
+	// DefaultMaxConcurrentResolutions is the default number of component versions that are resolved concurrently.
+	DefaultMaxConcurrentResolutions = 8
+)
+
+// ComponentGraph is the resolved graph of a root component version and all component versions it references, directly or transitively.
+// Component versions are identified by their component name and version.
+type ComponentGraph struct {
+	root  string
+	nodes map[string]*componentNode
+}
+
+type componentNode struct {
+	componentVersion *ComponentVersion
+	// err is set if the component version could not be resolved.
+	err error
+}
+
+// ResolveComponentGraph resolves all component versions referenced by root, directly or transitively, from the given repository.
+// Up to maxConcurrency component versions are resolved concurrently, a value < 1 uses DefaultMaxConcurrentResolutions.
+// Component versions that cannot be resolved are recorded in the graph and reported when they are looked up.
+// An error is returned if the references contain a cycle.
+func ResolveComponentGraph(ctx context.Context, client Client, repo string, root *ComponentVersion, maxConcurrency int) (*ComponentGraph, error) {
+	logger := log.GetLogger()
+
+	if maxConcurrency < 1 {
+		maxConcurrency = DefaultMaxConcurrentResolutions
+	}
+
+	rootKey := componentNodeKey(root.Component.Name, root.Component.Version)
+	g := &ComponentGraph{
+		root: rootKey,
+		nodes: map[string]*componentNode{
+			rootKey: {componentVersion: root},
+		},
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, maxConcurrency)
+	)
+
+	var resolveReferences func(cv *ComponentVersion)
+	resolveReferences = func(cv *ComponentVersion) {
+		for _, ref := range cv.Component.ComponentReferences {
+			key := componentNodeKey(ref.ComponentName, ref.Version)
+
+			mu.Lock()
+			if _, ok := g.nodes[key]; ok {
+				mu.Unlock()
+				continue
+			}
+			node := &componentNode{}
+			g.nodes[key] = node
+			mu.Unlock()
+
+			wg.Go(func() {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					node.err = ctx.Err()
+					return
+				}
+
+				location := buildLocation(repo, ref.ComponentName, ref.Version)
+				logger.Tracef("Comp_Graph: Resolving component version %s", location)
+				refCV, err := client.GetComponentVersion(ctx, location)
+				<-sem
+
+				if err != nil {
+					logger.Debugf("Comp_Graph: Failed to resolve component version %s: %v", location, err)
+					node.err = fmt.Errorf("error getting component version %s: %w", location, err)
+					return
+				}
+				node.componentVersion = refCV
+				resolveReferences(refCV)
+			})
+		}
+	}
+
+	resolveReferences(root)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("error resolving component references of %s: %w", rootKey, err)
+	}
+
+	if cycle := g.findCycle(); cycle != nil {
+		return nil, fmt.Errorf("component references contain a cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	logger.Debugf("Comp_Graph: Resolved %d component versions referenced by %s", len(g.nodes), rootKey)
+	return g, nil
+}
+
+// Root returns the root component version of the graph.
+func (g *ComponentGraph) Root() *ComponentVersion {
+	return g.nodes[g.root].componentVersion
+}
+
+// ComponentVersions returns all successfully resolved component versions of the graph, sorted by name and version.
+func (g *ComponentGraph) ComponentVersions() []*ComponentVersion {
+	keys := make([]string, 0, len(g.nodes))
+	for key, node := range g.nodes {
+		if node.err == nil {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	cvs := make([]*ComponentVersion, 0, len(keys))
+	for _, key := range keys {
+		cvs = append(cvs, g.nodes[key].componentVersion)
+	}
+	return cvs
+}
+
+// Lookup returns the component version with the given name and version.
+// The boolean result is false if the component version is not part of the graph.
+// A non-nil error is returned if the component version is part of the graph but could not be resolved.
+func (g *ComponentGraph) Lookup(componentName, version string) (*ComponentVersion, bool, error) {
+	node, ok := g.nodes[componentNodeKey(componentName, version)]
+	if !ok {
+		return nil, false, nil
+	}
+	return node.componentVersion, true, node.err
+}
+
+// findCycle returns the component versions forming a cycle, or nil if the graph is acyclic.
+func (g *ComponentGraph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(g.nodes))
+	var path []string
+
+	var visit func(key string) []string
+	visit = func(key string) []string {
+		node := g.nodes[key]
+		if node == nil || node.err != nil {
+			return nil
+		}
+
+		switch state[key] {
+		case visiting:
+			start := slices.Index(path, key)
+			return append(slices.Clone(path[start:]), key)
+		case visited:
+			return nil
+		}
+
+		state[key] = visiting
+		path = append(path, key)
+		for _, ref := range node.componentVersion.Component.ComponentReferences {
+			if cycle := visit(componentNodeKey(ref.ComponentName, ref.Version)); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = visited
+		return nil
+	}
+
+	return visit(g.root)
+}
+
+func componentNodeKey(componentName, version string) string {
+	return componentName + ":" + version
+}
@@ -0,0 +1,124 @@
+package ocm_cli_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+const (
+	graphComponentPrefix = "github.com/openmcp-project/bootstrapper/test-graph-"
+	version001           = "v0.0.1"
+)
+
+func TestResolveComponentGraph(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/04/component-constructor.yaml", t)
+
+	client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc                 string
+		rootComponent        string
+		maxConcurrency       int
+		expectError          bool
+		expectedComponents   []string
+		expectedUnresolvable []string
+	}{
+		{
+			desc:           "should resolve a graph with shared references",
+			rootComponent:  graphComponentPrefix + "a",
+			maxConcurrency: 4,
+			expectedComponents: []string{
+				graphComponentPrefix + "a",
+				graphComponentPrefix + "b",
+				graphComponentPrefix + "c",
+				graphComponentPrefix + "d",
+			},
+			expectedUnresolvable: []string{graphComponentPrefix + "missing"},
+		},
+		{
+			desc:           "should resolve a graph sequentially",
+			rootComponent:  graphComponentPrefix + "b",
+			maxConcurrency: 1,
+			expectedComponents: []string{
+				graphComponentPrefix + "b",
+				graphComponentPrefix + "d",
+			},
+		},
+		{
+			desc:          "should fail for a reference cycle",
+			rootComponent: graphComponentPrefix + "cycle-a",
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			root, err := client.GetComponentVersion(t.Context(), fmt.Sprintf("%s//%s:%s", ctf, tc.rootComponent, version001))
+			assert.NoError(t, err)
+
+			graph, err := ocmcli.ResolveComponentGraph(t.Context(), client, ctf, root, tc.maxConcurrency)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tc.rootComponent, graph.Root().Component.Name)
+
+			names := make([]string, 0)
+			for _, cv := range graph.ComponentVersions() {
+				names = append(names, cv.Component.Name)
+			}
+			assert.Equal(t, tc.expectedComponents, names)
+
+			for _, name := range tc.expectedComponents {
+				cv, found, err := graph.Lookup(name, version001)
+				assert.True(t, found)
+				assert.NoError(t, err)
+				assert.Equal(t, name, cv.Component.Name)
+			}
+
+			for _, name := range tc.expectedUnresolvable {
+				_, found, err := graph.Lookup(name, version001)
+				assert.True(t, found)
+				assert.Error(t, err)
+			}
+
+			_, found, _ := graph.Lookup("unknown-component", version001)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestComponentGetterGraph(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/04/component-constructor.yaml", t)
+
+	g := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%sa:%s", ctf, graphComponentPrefix, version001), "reference-d/test-resource-d", ocmcli.NoOcmConfig)
+	err := g.InitializeComponents(t.Context())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, graphComponentPrefix+"d", g.TemplatesComponentVersion().Component.Name)
+	assert.Len(t, g.Graph().ComponentVersions(), 4)
+
+	cvs, err := g.GetComponentVersionsForResourceRecursive(t.Context(), g.RootComponentVersion(), "test-resource-d")
+	assert.NoError(t, err)
+	if assert.Len(t, cvs, 1) {
+		assert.Equal(t, graphComponentPrefix+"d", cvs[0].Component.Name)
+	}
+
+	_, err = g.GetReferencedComponentVersions(t.Context(), g.RootComponentVersion(), "reference-missing")
+	assert.Error(t, err)
+
+	g = ocmcli.NewComponentGetter(fmt.Sprintf("%s//%scycle-a:%s", ctf, graphComponentPrefix, version001), "test-resource", ocmcli.NoOcmConfig)
+	err = g.InitializeComponents(t.Context())
+	assert.Error(t, err)
+}
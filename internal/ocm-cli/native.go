@@ -0,0 +1,796 @@
+package ocm_cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+const (
+	// componentDescriptorsRepositoryPrefix is the prefix of the OCI repositories that contain the component descriptors.
+	componentDescriptorsRepositoryPrefix = "component-descriptors"
+	// componentConfigMediaType is the media type of the config blob of a component version artifact.
+	componentConfigMediaType = "application/vnd.ocm.software.component.config.v1+json"
+	// componentDescriptorFileName is the name of the component descriptor file in a tar based descriptor layer.
+	componentDescriptorFileName = "component-descriptor.yaml"
+	// componentDescriptorSchemaVersion is the supported schema version of component descriptors.
+	componentDescriptorSchemaVersion = "v2"
+
+	// maxComponentDescriptorSize limits the size of component descriptors read into memory.
+	maxComponentDescriptorSize = 64 << 20
+)
+
+// componentStore provides read access to the OCI artifacts of an OCM repository.
+type componentStore interface {
+	// ListTags returns the tags of the given repository.
+	ListTags(ctx context.Context, repository string) ([]string, error)
+	// GetManifest returns the manifest tagged with tag in the given repository.
+	GetManifest(ctx context.Context, repository, tag string) (*v1.Manifest, error)
+	// GetBlob returns the content of the blob with the given digest in the given repository.
+	GetBlob(ctx context.Context, repository, digest string) (io.ReadCloser, error)
+}
+
+// componentConfig is the config blob of a component version artifact.
+type componentConfig struct {
+	ComponentDescriptorLayer *v1.Descriptor `json:"componentDescriptorLayer"`
+}
+
+// componentDescriptor is the serialized form of a component version.
+type componentDescriptor struct {
+	Meta struct {
+		SchemaVersion string `json:"schemaVersion"`
+	} `json:"meta"`
+	Component  Component   `json:"component"`
+	Signatures []Signature `json:"signatures,omitempty"`
+}
+
+// nativeClient implements Client in-process.
+// It supports OCM repositories stored in CTF directories and OCI registries.
+type nativeClient struct {
+	keychain authn.Keychain
+
+	storesMu sync.Mutex
+	stores   map[string]componentStore
+
+	verifiedMu sync.Mutex
+	// verified contains the SHA-256 hashes of the component descriptors verified by VerifyComponentVersion,
+	// descriptors read later on must still match them
+	verified map[string]string
+}
+
+var _ Client = (*nativeClient)(nil)
+
+func newNativeClient(ocmConfig string) (*nativeClient, error) {
+	keychain, err := NewKeychain(ocmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nativeClient{
+		keychain: keychain,
+		stores:   make(map[string]componentStore),
+		verified: make(map[string]string),
+	}, nil
+}
+
+func (c *nativeClient) GetComponentVersion(ctx context.Context, componentReference string) (*ComponentVersion, error) {
+	repository, componentName, version, err := parseComponentReference(componentReference)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := c.openStore(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == "" {
+		version, err = c.resolveSingleVersion(ctx, store, repository, componentName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	descriptorData, err := readComponentDescriptor(ctx, store, componentName, version)
+	if err != nil {
+		return nil, fmt.Errorf("error getting component version %s: %w", componentReference, err)
+	}
+	if err = c.checkVerified(repository, componentName, version, descriptorData); err != nil {
+		return nil, err
+	}
+
+	cv, err := parseComponentDescriptor(descriptorData, componentName, version)
+	if err != nil {
+		return nil, fmt.Errorf("error getting component version %s: %w", componentReference, err)
+	}
+	cv.Repository = repository
+
+	return cv, nil
+}
+
+func (c *nativeClient) ListComponentVersions(ctx context.Context, repository, componentName string) ([]string, error) {
+	store, err := c.openStore(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := store.ListTags(ctx, componentDescriptorRepository(componentName))
+	if err != nil {
+		return nil, fmt.Errorf("error listing versions of component %s in repository %s: %w", componentName, repository, err)
+	}
+
+	versions := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		versions = append(versions, tagToVersion(tag))
+	}
+
+	return versions, nil
+}
+
+func (c *nativeClient) DownloadDirectoryResource(ctx context.Context, componentLocation, resourceName, downloadDir string) error {
+	logger := log.GetLogger()
+
+	cv, err := c.GetComponentVersion(ctx, componentLocation)
+	if err != nil {
+		return err
+	}
+
+	resource, err := cv.GetResource(resourceName)
+	if err != nil {
+		return err
+	}
+
+	logger.Tracef("OCM_Native: Downloading resource %s of component version %s:%s to %s", resourceName, cv.Component.Name, cv.Component.Version, downloadDir)
+
+	switch normalizeAccessType(resource.Access.Type) {
+	case LocalBlobAccessType:
+		if resource.Access.LocalReference == nil {
+			return fmt.Errorf("resource %s has no local reference", resourceName)
+		}
+
+		store, err := c.openStore(cv.Repository)
+		if err != nil {
+			return err
+		}
+
+		blob, err := store.GetBlob(ctx, componentDescriptorRepository(cv.Component.Name), *resource.Access.LocalReference)
+		if err != nil {
+			return fmt.Errorf("error getting local blob of resource %s: %w", resourceName, err)
+		}
+		defer func() {
+			_ = blob.Close()
+		}()
+
+		content := c.newBlobVerifier(cv, resource, blob)
+		if err = util.ExtractTarArchive(content, downloadDir); err != nil {
+			return fmt.Errorf("error extracting resource %s: %w", resourceName, err)
+		}
+		return content.verify()
+
+	case OCIArtifactAccessType, OCIRegistryAccessType:
+		if resource.Access.ImageReference == nil {
+			return fmt.Errorf("resource %s has no image reference", resourceName)
+		}
+		imageReference, err := PinnedImageReference(resource)
+		if err != nil {
+			return err
+		}
+		return c.downloadOCIArtifact(ctx, imageReference, downloadDir)
+
+	default:
+		return fmt.Errorf("access type %s of resource %s is not supported", resource.Access.Type, resourceName)
+	}
+}
+
+func (c *nativeClient) DownloadResource(ctx context.Context, componentLocation, resourceName, outputFile string) error {
+	cv, err := c.GetComponentVersion(ctx, componentLocation)
+	if err != nil {
+		return err
+	}
+
+	resource, err := cv.GetResource(resourceName)
+	if err != nil {
+		return err
+	}
+
+	if normalizeAccessType(resource.Access.Type) != LocalBlobAccessType || resource.Access.LocalReference == nil {
+		return fmt.Errorf("resource %s with access type %s is not stored as local blob", resourceName, resource.Access.Type)
+	}
+
+	store, err := c.openStore(cv.Repository)
+	if err != nil {
+		return err
+	}
+
+	blob, err := store.GetBlob(ctx, componentDescriptorRepository(cv.Component.Name), *resource.Access.LocalReference)
+	if err != nil {
+		return fmt.Errorf("error getting local blob of resource %s: %w", resourceName, err)
+	}
+	defer func() {
+		_ = blob.Close()
+	}()
+
+	out, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("error creating output file %s: %w", outputFile, err)
+	}
+
+	content := c.newBlobVerifier(cv, resource, blob)
+	_, err = io.Copy(out, content)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing resource %s to %s: %w", resourceName, outputFile, err)
+	}
+
+	return content.verify()
+}
+
+// blobVerifier hashes a local blob while it is read. If the component version has been verified, verify checks the hash
+// against the digest of the resource, so that only verified content is used.
+type blobVerifier struct {
+	io.Reader
+	hash      hash.Hash
+	expected  string
+	component *Component
+	resource  *Resource
+}
+
+func (c *nativeClient) newBlobVerifier(cv *ComponentVersion, resource *Resource, blob io.Reader) *blobVerifier {
+	v := &blobVerifier{Reader: blob, component: &cv.Component, resource: resource}
+	if c.isVerified(cv.Repository, cv.Component.Name, cv.Component.Version) && resource.Digest != nil &&
+		resource.Digest.NormalisationAlgorithm == GenericBlobDigestV1 && resource.Digest.HashAlgorithm == HashAlgorithmSHA256 {
+		v.hash = sha256.New()
+		v.expected = resource.Digest.Value
+		v.Reader = io.TeeReader(blob, v.hash)
+	}
+	return v
+}
+
+func (v *blobVerifier) verify() error {
+	if v.hash == nil {
+		return nil
+	}
+	if _, err := io.Copy(io.Discard, v.Reader); err != nil {
+		return fmt.Errorf("error reading local blob of resource %s: %w", v.resource.Name, err)
+	}
+	if digest := hex.EncodeToString(v.hash.Sum(nil)); digest != v.expected {
+		return fmt.Errorf("digest mismatch of resource %s of component version %s:%s: expected %s, got %s", v.resource.Name, v.component.Name, v.component.Version, v.expected, digest)
+	}
+	return nil
+}
+
+func (c *nativeClient) VerifyComponentVersion(ctx context.Context, componentReference string, options VerifyOptions) error {
+	logger := log.GetLogger()
+
+	if len(options.Signatures) == 0 {
+		return fmt.Errorf("no signature configured to verify component version %s", componentReference)
+	}
+
+	verifiers := make([]*signatureVerifier, 0, len(options.Signatures))
+	for _, signature := range options.Signatures {
+		verifier, err := newSignatureVerifier(signature)
+		if err != nil {
+			return err
+		}
+		verifiers = append(verifiers, verifier)
+	}
+
+	repository, componentName, version, err := parseComponentReference(componentReference)
+	if err != nil {
+		return err
+	}
+
+	store, err := c.openStore(repository)
+	if err != nil {
+		return err
+	}
+
+	if version == "" {
+		version, err = c.resolveSingleVersion(ctx, store, repository, componentName)
+		if err != nil {
+			return err
+		}
+	}
+
+	descriptorData, err := readComponentDescriptor(ctx, store, componentName, version)
+	if err != nil {
+		return fmt.Errorf("error getting component version %s: %w", componentReference, err)
+	}
+
+	var descriptor componentDescriptor
+	if err = yaml.Unmarshal(descriptorData, &descriptor); err != nil {
+		return fmt.Errorf("error unmarshalling component descriptor: %w", err)
+	}
+
+	for _, verifier := range verifiers {
+		if err = verifier.verify(descriptorData, descriptor.Signatures); err != nil {
+			return fmt.Errorf("error verifying component version %s:%s: %w", componentName, version, err)
+		}
+		logger.Debugf("OCM_Native: Verified signature %s of component version %s:%s", verifier.name, componentName, version)
+	}
+
+	verified := map[string][]byte{componentName + ":" + version: descriptorData}
+	if err = c.verifyDigests(ctx, store, &descriptor.Component, verified); err != nil {
+		return err
+	}
+
+	for key, data := range verified {
+		c.markVerified(repository+"//"+key, data)
+	}
+	return nil
+}
+
+// verifyDigests verifies the digests of the local resources of the component and of all component versions it references, directly or transitively.
+// The digests are covered by the signature of the root component version, so every mismatch means the content has been modified after signing.
+// The verified component descriptors are added to verified.
+func (c *nativeClient) verifyDigests(ctx context.Context, store componentStore, component *Component, verified map[string][]byte) error {
+	logger := log.GetLogger()
+
+	key := component.Name + ":" + component.Version
+
+	for i := range component.Resources {
+		if err := c.verifyResourceDigest(ctx, store, component, &component.Resources[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range component.ComponentReferences {
+		if ref.Digest == nil {
+			return fmt.Errorf("reference %s of component version %s has no digest and is not covered by the signature", ref.Name, key)
+		}
+		refKey := ref.ComponentName + ":" + ref.Version
+		descriptorData, seen := verified[refKey]
+		if !seen {
+			var err error
+			descriptorData, err = readComponentDescriptor(ctx, store, ref.ComponentName, ref.Version)
+			if err != nil {
+				return fmt.Errorf("error getting component version %s:%s referenced by %s: %w", ref.ComponentName, ref.Version, key, err)
+			}
+		}
+
+		digest, err := ComponentDescriptorDigest(descriptorData, ref.Digest.NormalisationAlgorithm, ref.Digest.HashAlgorithm)
+		if err != nil {
+			return fmt.Errorf("error computing digest of component version %s:%s: %w", ref.ComponentName, ref.Version, err)
+		}
+		if digest != ref.Digest.Value {
+			return fmt.Errorf("digest mismatch of component version %s:%s referenced by %s: expected %s, got %s", ref.ComponentName, ref.Version, key, ref.Digest.Value, digest)
+		}
+		logger.Tracef("OCM_Native: Verified digest of component version %s:%s", ref.ComponentName, ref.Version)
+		if seen {
+			continue
+		}
+		verified[refKey] = descriptorData
+
+		var descriptor componentDescriptor
+		if err = yaml.Unmarshal(descriptorData, &descriptor); err != nil {
+			return fmt.Errorf("error unmarshalling component descriptor: %w", err)
+		}
+		if err = c.verifyDigests(ctx, store, &descriptor.Component, verified); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyResourceDigest verifies the digest of a resource stored as local blob, or of an OCI artifact.
+// The digest of an OCI artifact referenced by tag is the digest of the manifest the tag currently resolves to,
+// the digest of an OCI artifact stored as local blob in OCM artifact set format is the digest of its main manifest.
+// Digests that cannot be verified are an error.
+func (c *nativeClient) verifyResourceDigest(ctx context.Context, store componentStore, component *Component, resource *Resource) error {
+	logger := log.GetLogger()
+
+	if strings.EqualFold(normalizeAccessType(resource.Access.Type), accessTypeNone) {
+		return nil
+	}
+	if resource.Digest == nil {
+		return fmt.Errorf("resource %s of component version %s:%s has no digest and is not covered by the signature", resource.Name, component.Name, component.Version)
+	}
+
+	switch {
+	case normalizeAccessType(resource.Access.Type) == LocalBlobAccessType && resource.Digest.NormalisationAlgorithm == GenericBlobDigestV1:
+		if resource.Digest.HashAlgorithm != HashAlgorithmSHA256 {
+			return fmt.Errorf("unsupported hash algorithm %q of resource %s", resource.Digest.HashAlgorithm, resource.Name)
+		}
+		if resource.Access.LocalReference == nil {
+			return fmt.Errorf("resource %s has no local reference", resource.Name)
+		}
+
+		blob, err := store.GetBlob(ctx, componentDescriptorRepository(component.Name), *resource.Access.LocalReference)
+		if err != nil {
+			return fmt.Errorf("error getting local blob of resource %s: %w", resource.Name, err)
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, blob)
+		_ = blob.Close()
+		if err != nil {
+			return fmt.Errorf("error reading local blob of resource %s: %w", resource.Name, err)
+		}
+
+		if digest := hex.EncodeToString(hash.Sum(nil)); digest != resource.Digest.Value {
+			return fmt.Errorf("digest mismatch of resource %s of component version %s:%s: expected %s, got %s", resource.Name, component.Name, component.Version, resource.Digest.Value, digest)
+		}
+
+	case isOCIAccessType(resource.Access.Type):
+		if resource.Access.ImageReference == nil {
+			return fmt.Errorf("resource %s has no image reference", resource.Name)
+		}
+		if resource.Digest.HashAlgorithm != HashAlgorithmSHA256 {
+			return fmt.Errorf("unsupported hash algorithm %q of resource %s", resource.Digest.HashAlgorithm, resource.Name)
+		}
+
+		imageDigest, err := c.resolveImageDigest(ctx, *resource.Access.ImageReference)
+		if err != nil {
+			return fmt.Errorf("error resolving digest of resource %s of component version %s:%s: %w", resource.Name, component.Name, component.Version, err)
+		}
+		if strings.TrimPrefix(imageDigest, "sha256:") != resource.Digest.Value {
+			return fmt.Errorf("digest mismatch of resource %s of component version %s:%s: expected %s, image reference has digest %s", resource.Name, component.Name, component.Version, resource.Digest.Value, imageDigest)
+		}
+
+	case normalizeAccessType(resource.Access.Type) == LocalBlobAccessType && resource.Digest.NormalisationAlgorithm == OCIArtifactDigestV1:
+		if resource.Digest.HashAlgorithm != HashAlgorithmSHA256 {
+			return fmt.Errorf("unsupported hash algorithm %q of resource %s", resource.Digest.HashAlgorithm, resource.Name)
+		}
+		if resource.Access.LocalReference == nil {
+			return fmt.Errorf("resource %s has no local reference", resource.Name)
+		}
+
+		blob, err := store.GetBlob(ctx, componentDescriptorRepository(component.Name), *resource.Access.LocalReference)
+		if err != nil {
+			return fmt.Errorf("error getting local blob of resource %s: %w", resource.Name, err)
+		}
+		err = verifyArtifactSet(blob, resource.Digest.Value)
+		_ = blob.Close()
+		if err != nil {
+			return fmt.Errorf("digest mismatch of resource %s of component version %s:%s: %w", resource.Name, component.Name, component.Version, err)
+		}
+
+	default:
+		return fmt.Errorf("digest %s of resource %s of component version %s:%s with access type %s cannot be verified",
+			resource.Digest.NormalisationAlgorithm, resource.Name, component.Name, component.Version, resource.Access.Type)
+	}
+
+	logger.Tracef("OCM_Native: Verified digest of resource %s of component version %s:%s", resource.Name, component.Name, component.Version)
+	return nil
+}
+
+// resolveImageDigest returns the digest of the image reference, or of the manifest its tag resolves to.
+func (c *nativeClient) resolveImageDigest(ctx context.Context, imageReference string) (string, error) {
+	ref, err := name.ParseReference(imageReference)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %s: %w", imageReference, err)
+	}
+	if digest, ok := ref.(name.Digest); ok {
+		return digest.DigestStr(), nil
+	}
+
+	descriptor, err := remote.Head(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(c.keychain))
+	if err != nil {
+		return "", fmt.Errorf("error getting manifest of %s: %w", imageReference, err)
+	}
+	return descriptor.Digest.String(), nil
+}
+
+// PinnedImageReference returns the image reference of an OCI artifact resource pinned to the digest of the resource,
+// so that a moved tag cannot replace the content the digest has been verified for.
+func PinnedImageReference(resource *Resource) (string, error) {
+	imageReference := *resource.Access.ImageReference
+	if resource.Digest == nil || resource.Digest.NormalisationAlgorithm != OCIArtifactDigestV1 || resource.Digest.HashAlgorithm != HashAlgorithmSHA256 {
+		return imageReference, nil
+	}
+
+	ref, err := name.ParseReference(imageReference)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %s: %w", imageReference, err)
+	}
+	if digest, ok := ref.(name.Digest); ok {
+		if strings.TrimPrefix(digest.DigestStr(), "sha256:") != resource.Digest.Value {
+			return "", fmt.Errorf("digest mismatch of resource %s: expected %s, image reference has digest %s", resource.Name, resource.Digest.Value, digest.DigestStr())
+		}
+		return imageReference, nil
+	}
+	return ref.Context().Digest("sha256:" + resource.Digest.Value).String(), nil
+}
+
+// downloadOCIArtifact extracts the layers of the OCI artifact with the given reference into downloadDir.
+func (c *nativeClient) downloadOCIArtifact(ctx context.Context, imageReference, downloadDir string) error {
+	ref, err := name.ParseReference(imageReference)
+	if err != nil {
+		return fmt.Errorf("invalid image reference %s: %w", imageReference, err)
+	}
+
+	image, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(c.keychain))
+	if err != nil {
+		return fmt.Errorf("error getting OCI artifact %s: %w", imageReference, err)
+	}
+
+	layers, err := image.Layers()
+	if err != nil {
+		return fmt.Errorf("error getting layers of OCI artifact %s: %w", imageReference, err)
+	}
+
+	for _, layer := range layers {
+		content, err := layer.Compressed()
+		if err != nil {
+			return fmt.Errorf("error reading layer of OCI artifact %s: %w", imageReference, err)
+		}
+		err = util.ExtractTarArchive(content, downloadDir)
+		_ = content.Close()
+		if err != nil {
+			return fmt.Errorf("error extracting layer of OCI artifact %s: %w", imageReference, err)
+		}
+	}
+
+	return nil
+}
+
+// markVerified records the hash of a verified component descriptor with the given reference <repo>//<component>:<version>.
+func (c *nativeClient) markVerified(componentReference string, descriptorData []byte) {
+	sum := sha256.Sum256(descriptorData)
+
+	c.verifiedMu.Lock()
+	defer c.verifiedMu.Unlock()
+	c.verified[componentReference] = hex.EncodeToString(sum[:])
+}
+
+// checkVerified returns an error if the component descriptor has been verified before and has changed since.
+func (c *nativeClient) checkVerified(repository, componentName, version string, descriptorData []byte) error {
+	c.verifiedMu.Lock()
+	expected, ok := c.verified[repository+"//"+componentName+":"+version]
+	c.verifiedMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	sum := sha256.Sum256(descriptorData)
+	if hex.EncodeToString(sum[:]) != expected {
+		return fmt.Errorf("component descriptor of %s:%s has changed since it was verified", componentName, version)
+	}
+	return nil
+}
+
+// isVerified reports whether the component version has been verified by VerifyComponentVersion.
+func (c *nativeClient) isVerified(repository, componentName, version string) bool {
+	c.verifiedMu.Lock()
+	defer c.verifiedMu.Unlock()
+	_, ok := c.verified[repository+"//"+componentName+":"+version]
+	return ok
+}
+
+// resolveSingleVersion returns the version of a component reference without version.
+// This is only unambiguous if the repository contains exactly one version of the component.
+func (c *nativeClient) resolveSingleVersion(ctx context.Context, store componentStore, repository, componentName string) (string, error) {
+	tags, err := store.ListTags(ctx, componentDescriptorRepository(componentName))
+	if err != nil {
+		return "", fmt.Errorf("error listing versions of component %s in repository %s: %w", componentName, repository, err)
+	}
+	switch len(tags) {
+	case 0:
+		return "", fmt.Errorf("component %s not found in repository %s", componentName, repository)
+	case 1:
+		return tagToVersion(tags[0]), nil
+	default:
+		return "", fmt.Errorf("component %s has multiple versions in repository %s, a version must be specified", componentName, repository)
+	}
+}
+
+// openStore returns the component store for the given repository specification.
+// The repository type can be given explicitly with a "<type>::" prefix, e.g. "ctf::./ctf" or "oci::ghcr.io/org/components".
+// Otherwise, existing local paths are treated as CTF directories and everything else as OCI registry.
+func (c *nativeClient) openStore(repository string) (componentStore, error) {
+	c.storesMu.Lock()
+	defer c.storesMu.Unlock()
+
+	if store, ok := c.stores[repository]; ok {
+		return store, nil
+	}
+
+	var (
+		store componentStore
+		err   error
+	)
+
+	repositoryType, ref := splitRepositoryType(repository)
+	switch strings.ToLower(repositoryType) {
+	case "ctf", "commontransportformat", "directory":
+		store, err = openCTFStore(ref)
+	case "oci", "ociregistry":
+		store, err = newOCIStore(ref, c.keychain)
+	case "":
+		if isLocalPath(ref) {
+			store, err = openCTFStore(ref)
+		} else {
+			store, err = newOCIStore(ref, c.keychain)
+		}
+	default:
+		err = fmt.Errorf("unsupported repository type %s", repositoryType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening OCM repository %s: %w", repository, err)
+	}
+
+	c.stores[repository] = store
+	return store, nil
+}
+
+// parseComponentDescriptor parses the serialized component descriptor of the given component version.
+func parseComponentDescriptor(descriptorData []byte, componentName, version string) (*ComponentVersion, error) {
+	var descriptor componentDescriptor
+	if err := yaml.Unmarshal(descriptorData, &descriptor); err != nil {
+		return nil, fmt.Errorf("error unmarshalling component descriptor: %w", err)
+	}
+	if descriptor.Meta.SchemaVersion != componentDescriptorSchemaVersion {
+		return nil, fmt.Errorf("unsupported component descriptor schema version %q of %s:%s", descriptor.Meta.SchemaVersion, componentName, version)
+	}
+
+	return &ComponentVersion{
+		Component:  descriptor.Component,
+		Signatures: descriptor.Signatures,
+	}, nil
+}
+
+// readComponentDescriptor reads the serialized component descriptor of the given component version from the store.
+func readComponentDescriptor(ctx context.Context, store componentStore, componentName, version string) ([]byte, error) {
+	repository := componentDescriptorRepository(componentName)
+
+	manifest, err := store.GetManifest(ctx, repository, versionToTag(version))
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Config.MediaType != componentConfigMediaType {
+		return nil, fmt.Errorf("artifact %s:%s is not a component version, unexpected config media type %s", repository, version, manifest.Config.MediaType)
+	}
+
+	configData, err := readBlob(ctx, store, repository, manifest.Config.Digest.String())
+	if err != nil {
+		return nil, fmt.Errorf("error reading component config: %w", err)
+	}
+
+	var config componentConfig
+	if err = json.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("error unmarshalling component config: %w", err)
+	}
+	if config.ComponentDescriptorLayer == nil {
+		return nil, fmt.Errorf("component config of %s:%s does not reference a component descriptor layer", componentName, version)
+	}
+
+	descriptorData, err := readBlob(ctx, store, repository, config.ComponentDescriptorLayer.Digest.String())
+	if err != nil {
+		return nil, fmt.Errorf("error reading component descriptor: %w", err)
+	}
+
+	if strings.HasSuffix(string(config.ComponentDescriptorLayer.MediaType), "+tar") || strings.HasSuffix(string(config.ComponentDescriptorLayer.MediaType), "/x-tar") {
+		descriptorData, err = readFileFromTar(descriptorData, componentDescriptorFileName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading component descriptor: %w", err)
+		}
+	}
+
+	return descriptorData, nil
+}
+
+func readBlob(ctx context.Context, store componentStore, repository, digest string) ([]byte, error) {
+	blob, err := store.GetBlob(ctx, repository, digest)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = blob.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(blob, maxComponentDescriptorSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob %s: %w", digest, err)
+	}
+	if len(data) > maxComponentDescriptorSize {
+		return nil, fmt.Errorf("blob %s exceeds the maximum size of %d bytes", digest, maxComponentDescriptorSize)
+	}
+	return data, nil
+}
+
+func readFileFromTar(data []byte, fileName string) ([]byte, error) {
+	tarReader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("file %s not found in tar archive", fileName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tar archive: %w", err)
+		}
+		if filepath.Clean(header.Name) == fileName {
+			return io.ReadAll(tarReader)
+		}
+	}
+}
+
+// parseComponentReference splits a component reference in the format <repo>//<component>[:<version>].
+func parseComponentReference(componentReference string) (repository, componentName, version string, err error) {
+	parts := strings.SplitN(componentReference, "//", 2)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid component reference, expected '<repo>//<component>:<version>': %s", componentReference)
+	}
+
+	repository = parts[0]
+	componentName = parts[1]
+	if idx := strings.LastIndex(componentName, ":"); idx != -1 {
+		version = componentName[idx+1:]
+		componentName = componentName[:idx]
+	}
+
+	return repository, componentName, version, nil
+}
+
+// splitRepositoryType splits an optional "<type>::" prefix from a repository specification.
+func splitRepositoryType(repository string) (repositoryType, ref string) {
+	if idx := strings.Index(repository, "::"); idx != -1 {
+		return repository[:idx], repository[idx+2:]
+	}
+	return "", repository
+}
+
+// isLocalPath returns true if the repository specification refers to the local file system.
+func isLocalPath(ref string) bool {
+	if strings.HasPrefix(ref, ".") || filepath.IsAbs(ref) {
+		return true
+	}
+	_, err := os.Stat(ref)
+	return err == nil
+}
+
+// componentDescriptorRepository returns the OCI repository that contains the versions of the given component.
+func componentDescriptorRepository(componentName string) string {
+	return componentDescriptorsRepositoryPrefix + "/" + componentName
+}
+
+// versionToTag maps a component version to an OCI tag, as OCI tags must not contain "+".
+func versionToTag(version string) string {
+	return strings.ReplaceAll(version, "+", ".build-")
+}
+
+// tagToVersion maps an OCI tag back to a component version.
+func tagToVersion(tag string) string {
+	return strings.ReplaceAll(tag, ".build-", "+")
+}
+
+// normalizeAccessType removes the version suffix from an access type, e.g. "localBlob/v1" becomes "localBlob".
+func normalizeAccessType(accessType string) string {
+	accessType, _, _ = strings.Cut(accessType, "/")
+	switch strings.ToLower(accessType) {
+	case strings.ToLower(LocalBlobAccessType):
+		return LocalBlobAccessType
+	case strings.ToLower(OCIArtifactAccessType):
+		return OCIArtifactAccessType
+	case strings.ToLower(OCIRegistryAccessType):
+		return OCIRegistryAccessType
+	}
+	return accessType
+}
+
+// isOCIAccessType reports whether the access type references an OCI artifact by its image reference.
+func isOCIAccessType(accessType string) bool {
+	accessType = normalizeAccessType(accessType)
+	return accessType == OCIArtifactAccessType || accessType == OCIRegistryAccessType
+}
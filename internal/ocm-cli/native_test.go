@@ -0,0 +1,169 @@
+package ocm_cli_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+const (
+	nativeRootComponent      = "github.com/openmcp-project/bootstrapper/test-native-root"
+	nativeTemplatesComponent = "github.com/openmcp-project/bootstrapper/test-native-templates"
+)
+
+func TestNativeClientGetComponentVersion(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/03/component-constructor.yaml", t)
+
+	client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc              string
+		reference         string
+		expectError       bool
+		expectedName      string
+		expectedVersion   string
+		expectedRefs      int
+		expectedResources int
+	}{
+		{
+			desc:              "should get a component version with references",
+			reference:         fmt.Sprintf("%s//%s:v0.0.1", ctf, nativeRootComponent),
+			expectedName:      nativeRootComponent,
+			expectedVersion:   "v0.0.1",
+			expectedRefs:      1,
+			expectedResources: 1,
+		},
+		{
+			desc:              "should get a component version from a typed repository",
+			reference:         fmt.Sprintf("ctf::%s//%s:v0.0.2", ctf, nativeRootComponent),
+			expectedName:      nativeRootComponent,
+			expectedVersion:   "v0.0.2",
+			expectedResources: 1,
+		},
+		{
+			desc:              "should resolve the only version of a component",
+			reference:         fmt.Sprintf("%s//%s", ctf, nativeTemplatesComponent),
+			expectedName:      nativeTemplatesComponent,
+			expectedVersion:   "v0.1.0",
+			expectedResources: 1,
+		},
+		{
+			desc:        "should fail to resolve the version of a component with multiple versions",
+			reference:   fmt.Sprintf("%s//%s", ctf, nativeRootComponent),
+			expectError: true,
+		},
+		{
+			desc:        "should fail for an unknown component",
+			reference:   fmt.Sprintf("%s//%s:v0.0.1", ctf, "unknown-component"),
+			expectError: true,
+		},
+		{
+			desc:        "should fail for an invalid reference",
+			reference:   nativeRootComponent,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cv, err := client.GetComponentVersion(t.Context(), tc.reference)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.expectedName, cv.Component.Name)
+			assert.Equal(t, tc.expectedVersion, cv.Component.Version)
+			assert.Len(t, cv.Component.ComponentReferences, tc.expectedRefs)
+			assert.Len(t, cv.Component.Resources, tc.expectedResources)
+		})
+	}
+}
+
+func TestNativeClientResources(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/03/component-constructor.yaml", t)
+
+	client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+	assert.NoError(t, err)
+
+	cv, err := client.GetComponentVersion(t.Context(), fmt.Sprintf("%s//%s:v0.0.1", ctf, nativeRootComponent))
+	assert.NoError(t, err)
+
+	images := cv.GetResourcesByType(ocmcli.OCIImageResourceType)
+	if assert.Len(t, images, 1) && assert.NotNil(t, images[0].Access.ImageReference) {
+		assert.Equal(t, "ghcr.io/openmcp-project/images/test-image:v1.2.3", *images[0].Access.ImageReference)
+	}
+
+	refs := cv.GetComponentReferences("gitops-templates")
+	if assert.Len(t, refs, 1) {
+		assert.Equal(t, nativeTemplatesComponent, refs[0].ComponentName)
+		assert.Equal(t, "v0.1.0", refs[0].Version)
+	}
+}
+
+func TestNativeClientListComponentVersions(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/03/component-constructor.yaml", t)
+
+	client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+	assert.NoError(t, err)
+
+	versions, err := client.ListComponentVersions(t.Context(), ctf, nativeRootComponent)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"v0.0.1", "v0.0.2"}, versions)
+
+	versions, err = client.ListComponentVersions(t.Context(), ctf, "unknown-component")
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestNativeClientDownloadDirectoryResource(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/03/component-constructor.yaml", t)
+
+	client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+	assert.NoError(t, err)
+
+	location := fmt.Sprintf("%s//%s:v0.1.0", ctf, nativeTemplatesComponent)
+	downloadDir := t.TempDir()
+
+	err = client.DownloadDirectoryResource(t.Context(), location, "gitops-templates", downloadDir)
+	assert.NoError(t, err)
+
+	for _, file := range []string{"root.yaml", filepath.Join("sub", "sub.yaml")} {
+		expected, err := os.ReadFile(filepath.Join("testdata", "03", "templates", file))
+		assert.NoError(t, err)
+		actual, err := os.ReadFile(filepath.Join(downloadDir, file))
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual))
+	}
+
+	err = client.DownloadDirectoryResource(t.Context(), location, "unknown-resource", t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestNativeComponentGetter(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/02/component-constructor.yaml", t)
+
+	g := ocmcli.NewComponentGetter(
+		fmt.Sprintf("%s//%s:v0.0.1", ctf, "github.com/openmcp-project/bootstrapper/test-component-getter-a"),
+		"reference-b/reference-c/test-resource-c",
+		ocmcli.NoOcmConfig)
+
+	err := g.InitializeComponents(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/openmcp-project/bootstrapper/test-component-getter-c", g.TemplatesComponentVersion().Component.Name)
+
+	cvs, err := g.GetReferencedComponentVersionsRecursive(t.Context(), g.RootComponentVersion(), "reference-c")
+	assert.NoError(t, err)
+	if assert.Len(t, cvs, 1) {
+		assert.Equal(t, "github.com/openmcp-project/bootstrapper/test-component-getter-c", cvs[0].Component.Name)
+	}
+}
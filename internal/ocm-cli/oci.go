@@ -0,0 +1,116 @@
+package ocm_cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// ociStore reads OCI artifacts from an OCI registry.
+type ociStore struct {
+	// baseRepository is the registry host and optional path prefix, e.g. ghcr.io/openmcp-project/components.
+	baseRepository string
+	nameOptions    []name.Option
+	keychain       authn.Keychain
+}
+
+var _ componentStore = (*ociStore)(nil)
+
+// newOCIStore creates a store for the given registry reference.
+// A "http://" scheme marks the registry as insecure, a "https://" scheme is optional.
+func newOCIStore(ref string, keychain authn.Keychain) (*ociStore, error) {
+	s := &ociStore{
+		keychain: keychain,
+	}
+
+	switch {
+	case strings.HasPrefix(ref, "http://"):
+		ref = strings.TrimPrefix(ref, "http://")
+		s.nameOptions = append(s.nameOptions, name.Insecure)
+	case strings.HasPrefix(ref, "https://"):
+		ref = strings.TrimPrefix(ref, "https://")
+	}
+
+	s.baseRepository = strings.TrimSuffix(ref, "/")
+	if s.baseRepository == "" {
+		return nil, fmt.Errorf("OCI registry reference must not be empty")
+	}
+
+	// validate the reference early to fail with a meaningful error
+	if _, err := s.repository(componentDescriptorsRepositoryPrefix); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *ociStore) ListTags(ctx context.Context, repository string) ([]string, error) {
+	repo, err := s.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := remote.List(repo, s.remoteOptions(ctx)...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags of %s: %w", repo.String(), err)
+	}
+	return tags, nil
+}
+
+func (s *ociStore) GetManifest(ctx context.Context, repository, tag string) (*v1.Manifest, error) {
+	repo, err := s.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := remote.Get(repo.Tag(tag), s.remoteOptions(ctx)...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting manifest of %s:%s: %w", repo.String(), tag, err)
+	}
+
+	manifest, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing manifest of %s:%s: %w", repo.String(), tag, err)
+	}
+	return manifest, nil
+}
+
+func (s *ociStore) GetBlob(ctx context.Context, repository, digest string) (io.ReadCloser, error) {
+	repo, err := s.repository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := remote.Layer(repo.Digest(digest), s.remoteOptions(ctx)...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting blob %s of %s: %w", digest, repo.String(), err)
+	}
+
+	blob, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob %s of %s: %w", digest, repo.String(), err)
+	}
+	return blob, nil
+}
+
+func (s *ociStore) repository(repository string) (name.Repository, error) {
+	repo, err := name.NewRepository(s.baseRepository+"/"+repository, s.nameOptions...)
+	if err != nil {
+		return name.Repository{}, fmt.Errorf("invalid OCI repository %s/%s: %w", s.baseRepository, repository, err)
+	}
+	return repo, nil
+}
+
+func (s *ociStore) remoteOptions(ctx context.Context) []remote.Option {
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(s.keychain),
+	}
+}
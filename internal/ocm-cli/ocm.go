@@ -2,6 +2,7 @@ package ocm_cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -87,6 +88,8 @@ type ComponentVersion struct {
 	// Component is the OCM component associated with this version.
 	Component  Component `json:"component"`
 	Repository string    `json:"repository,omitempty"`
+	// Signatures are the signatures of the component version, see VerifyOptions.
+	Signatures []Signature `json:"signatures,omitempty"`
 }
 
 // Component represents an OCM component with its name, version, references to other components, and resources.
@@ -109,6 +112,8 @@ type ComponentReference struct {
 	Version string `json:"version"`
 	// ComponentName is the name of the component that this reference points to.
 	ComponentName string `json:"componentName"`
+	// Digest is the digest of the normalised component descriptor of the referenced component version, if the component version is signed.
+	Digest *DigestSpec `json:"digest,omitempty"`
 }
 
 // Resource represents a resource associated with a component, including its name, version, type, and access information.
@@ -121,6 +126,8 @@ type Resource struct {
 	Type string `json:"type"`
 	// Access contains the information on how to access the resource.
 	Access Access `json:"access"`
+	// Digest is the digest of the resource content, if the component version is signed.
+	Digest *DigestSpec `json:"digest,omitempty"`
 }
 
 // Access represents the access information for a resource, including the type of access.
@@ -133,6 +140,9 @@ type Access struct {
 	LocalReference *string `json:"localReference"`
 	// MediaType is the media type of the resource
 	MediaType *string `json:"mediaType"`
+	// ReferenceName is the original image reference of an OCI artifact that is stored as local blob,
+	// e.g. after the component version has been transferred into a CTF with its resources.
+	ReferenceName *string `json:"referenceName,omitempty"`
 }
 
 type ComponentListEntry struct {
@@ -144,6 +154,20 @@ var (
 	OCIImageResourceType = "ociImage"
 )
 
+const (
+	// LocalBlobAccessType is the access type of resources stored together with the component descriptor.
+	LocalBlobAccessType = "localBlob"
+	// OCIArtifactAccessType is the access type of resources stored as OCI artifact in an OCI registry.
+	OCIArtifactAccessType = "ociArtifact"
+	// OCIRegistryAccessType is the legacy name of OCIArtifactAccessType.
+	OCIRegistryAccessType = "ociRegistry"
+)
+
+// NormalizedType returns the access type without version suffix, e.g. "localBlob" for "localBlob/v1".
+func (a *Access) NormalizedType() string {
+	return normalizeAccessType(a.Type)
+}
+
 // GetResource retrieves a resource by its name from the component version.
 func (cv *ComponentVersion) GetResource(name string) (*Resource, error) {
 	for _, resource := range cv.Component.Resources {
@@ -176,18 +200,43 @@ func (cv *ComponentVersion) GetComponentReferences(name string) []ComponentRefer
 	return references
 }
 
+// ListComponentVersions lists all available versions of the component in the repository of the component version.
 func (cv *ComponentVersion) ListComponentVersions(ctx context.Context, ocmConfig string) ([]string, error) {
+	client, err := NewClient(ocmConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	out, err := ExecuteOutput(ctx, []string{"list", "componentversion", cv.Repository + "//" + cv.Component.Name}, []string{"--output", "yaml"}, ocmConfig)
+	return client.ListComponentVersions(ctx, cv.Repository, cv.Component.Name)
+}
+
+// GetComponentVersion retrieves a component version by its reference using the default backend.
+func GetComponentVersion(ctx context.Context, componentReference string, ocmConfig string) (*ComponentVersion, error) {
+	client, err := NewClient(ocmConfig)
 	if err != nil {
 		return nil, err
 	}
 
+	return client.GetComponentVersion(ctx, componentReference)
+}
+
+// decodeComponentVersion decodes a component descriptor in YAML or JSON format.
+func decodeComponentVersion(data []byte) (*ComponentVersion, error) {
+	var cv ComponentVersion
+	err := yaml.Unmarshal(data, &cv)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling component version: %w", err)
+	}
+	return &cv, nil
+}
+
+// decodeComponentVersionList decodes the versions from a stream of component list entries.
+func decodeComponentVersionList(data []byte) ([]string, error) {
 	cvList := make([]string, 0)
-	decoder := yaml2.NewYAMLOrJSONDecoder(strings.NewReader(string(out)), 1024)
+	decoder := yaml2.NewYAMLOrJSONDecoder(strings.NewReader(string(data)), 1024)
 	for {
 		var entry ComponentListEntry
-		err = decoder.Decode(&entry)
+		err := decoder.Decode(&entry)
 		if err == io.EOF {
 			break
 		}
@@ -198,25 +247,6 @@ func (cv *ComponentVersion) ListComponentVersions(ctx context.Context, ocmConfig
 	}
 
 	return cvList, nil
-
-}
-
-// GetComponentVersion retrieves a component version by its reference using the OCM CLI.
-func GetComponentVersion(ctx context.Context, componentReference string, ocmConfig string) (*ComponentVersion, error) {
-	out, err := ExecuteOutput(ctx, []string{"get", "componentversion", componentReference}, []string{"--output", "yaml"}, ocmConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	var cv ComponentVersion
-	err = yaml.Unmarshal(out, &cv)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshalling component version: %w", err)
-	}
-
-	cv.Repository = strings.SplitN(componentReference, "//", 2)[0]
-
-	return &cv, nil
 }
 
 type OCMConfiguration struct {
@@ -227,6 +257,7 @@ type OCMConfiguration struct {
 type TypedOCMConfigConfiguration struct {
 	Type         string                `json:"type"`
 	Repositories []OCMConfigRepository `json:"repositories"`
+	Consumers    []OCMConfigConsumer   `json:"consumers"`
 }
 
 type OCMConfigRepository struct {
@@ -235,35 +266,61 @@ type OCMConfigRepository struct {
 
 type TypedOCMConfigRepository struct {
 	Type string `json:"type"`
+	// DockerConfigFile is the path of the docker config file of a DockerConfig repository, e.g. "~/.docker/config.json".
+	DockerConfigFile string `json:"dockerConfigFile,omitempty"`
+	// DockerConfig is the inline docker config of a DockerConfig repository.
+	DockerConfig json.RawMessage `json:"dockerConfig,omitempty"`
+}
+
+// OCMConfigConsumer assigns credentials to the consumers matching the identity.
+type OCMConfigConsumer struct {
+	Identity    map[string]string     `json:"identity"`
+	Credentials []OCMConfigCredential `json:"credentials"`
+}
+
+// OCMConfigCredential is a set of credential properties, e.g. username and password.
+type OCMConfigCredential struct {
+	Type       string            `json:"type"`
+	Properties map[string]string `json:"properties"`
 }
 
 const (
 	DockerConfigRepositoryType = "DockerConfig"
+	CredentialsConfigType      = "credentials.config.ocm.software"
 )
 
-// verifyOCMConfig checks if the OCM configuration file exists and does not contain unsupported configuration.
+// verifyOCMConfig checks if the OCM configuration file exists and is valid.
+// The docker config files referenced by DockerConfig repositories must exist and be valid.
 func verifyOCMConfig(ocmConfig string) error {
+	_, err := readOCMConfig(ocmConfig)
+	return err
+}
+
+// readOCMConfig reads and verifies the OCM configuration file.
+func readOCMConfig(ocmConfig string) (*OCMConfiguration, error) {
 	if _, err := os.Stat(ocmConfig); os.IsNotExist(err) {
-		return fmt.Errorf("OCM configuration file does not exist: %s", ocmConfig)
+		return nil, fmt.Errorf("OCM configuration file does not exist: %s", ocmConfig)
 	}
 
 	file, err := os.ReadFile(ocmConfig)
 	if err != nil {
-		return fmt.Errorf("error reading OCM configuration file: %w", err)
+		return nil, fmt.Errorf("error reading OCM configuration file: %w", err)
 	}
 
 	var config OCMConfiguration
 	if err = yaml.Unmarshal(file, &config); err != nil {
-		return fmt.Errorf("error unmarshalling OCM configuration file: %w", err)
+		return nil, fmt.Errorf("error unmarshalling OCM configuration file: %w", err)
 	}
 
 	for _, typedConfig := range config.Configurations {
 		for _, repo := range typedConfig.Repositories {
 			if repo.Repository != nil && strings.Contains(repo.Repository.Type, DockerConfigRepositoryType) {
-				return fmt.Errorf("unsupported repository type: %s", repo.Repository.Type)
+				if _, err = loadDockerConfig(repo.Repository); err != nil {
+					return nil, fmt.Errorf("invalid %s repository: %w", repo.Repository.Type, err)
+				}
 			}
 		}
 	}
 
-	return nil
+	return &config, nil
 }
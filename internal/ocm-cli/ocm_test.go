@@ -56,10 +56,10 @@ func TestExecute(t *testing.T) {
 		},
 
 		{
-			desc:          "get componentversion with unsupported ocm config",
+			desc:          "get componentversion with ocm config referencing a missing docker config",
 			commands:      []string{ocmCmdGet, ocmCmdComponentVersion},
 			arguments:     []string{ocmArgOutput, ocmArgOutputYAML, ctfIn},
-			ocmConfig:     "./testdata/01/unsupported-ocm-config.yaml",
+			ocmConfig:     "./testdata/01/missing-docker-config-ocm-config.yaml",
 			expectedError: expectError,
 		},
 	}
@@ -144,9 +144,9 @@ func TestGetComponentVersion(t *testing.T) {
 			expectedError: nil,
 		},
 		{
-			desc:          "get component version with unsupported ocm config",
+			desc:          "get component version with ocm config referencing a missing docker config",
 			componentRef:  ctfIn,
-			ocmConfig:     "./testdata/01/unsupported-ocm-config.yaml",
+			ocmConfig:     "./testdata/01/missing-docker-config-ocm-config.yaml",
 			expectedError: expectError,
 		},
 		{
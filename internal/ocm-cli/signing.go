@@ -0,0 +1,492 @@
+package ocm_cli
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// HashAlgorithmSHA256 is the hash algorithm of SHA-256 digests.
+	HashAlgorithmSHA256 = "SHA-256"
+
+	// JSONNormalisationV1 is the legacy normalisation of component descriptors, serializing maps as lists of single-entry maps sorted by key.
+	JSONNormalisationV1 = "jsonNormalisation/v1"
+	// JSONNormalisationV2 is the normalisation of component descriptors as canonical JSON with sorted keys.
+	JSONNormalisationV2 = "jsonNormalisation/v2"
+	// GenericBlobDigestV1 is the digest algorithm of resources that are hashed as plain blob.
+	GenericBlobDigestV1 = "genericBlobDigest/v1"
+	// OCIArtifactDigestV1 is the digest algorithm of resources that are OCI artifacts, the digest is the manifest digest.
+	OCIArtifactDigestV1 = "ociArtifactDigest/v1"
+
+	// SignatureAlgorithmRSAPKCS1v15 is the RSA signature algorithm with PKCS #1 v1.5 padding.
+	SignatureAlgorithmRSAPKCS1v15 = "RSASSA-PKCS1-V1_5"
+	// SignatureAlgorithmRSAPSS is the RSA signature algorithm with PSS padding.
+	SignatureAlgorithmRSAPSS = "RSASSA-PSS"
+
+	// SignatureMediaTypeRSA is the media type of hex encoded RSA signatures that are verified with a public key.
+	SignatureMediaTypeRSA = "application/vnd.ocm.signature.rsa"
+	// SignatureMediaTypePEM is the media type of PEM encoded signatures that contain the certificate chain of the signer.
+	SignatureMediaTypePEM = "application/x-pem-file"
+
+	pemTypeSignature   = "SIGNATURE"
+	pemTypeCertificate = "CERTIFICATE"
+	pemHeaderAlgorithm = "Signature Algorithm"
+
+	accessTypeNone = "none"
+)
+
+// DigestSpec describes the digest of a resource or of a normalised component descriptor.
+type DigestSpec struct {
+	HashAlgorithm          string `json:"hashAlgorithm"`
+	NormalisationAlgorithm string `json:"normalisationAlgorithm"`
+	Value                  string `json:"value"`
+}
+
+// Signature is a named signature of a component version.
+type Signature struct {
+	Name      string        `json:"name"`
+	Digest    DigestSpec    `json:"digest"`
+	Signature SignatureSpec `json:"signature"`
+}
+
+// SignatureSpec contains the signature of the digest of a normalised component descriptor.
+type SignatureSpec struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+	MediaType string `json:"mediaType"`
+	Issuer    string `json:"issuer,omitempty"`
+}
+
+// VerifyOptions configures the signatures that are verified by Client.VerifyComponentVersion.
+type VerifyOptions struct {
+	// Signatures are the signatures that must be present and valid. All of them are verified.
+	Signatures []SignatureVerification
+}
+
+// SignatureVerification configures how the signature with the given name is verified.
+// Either a public key or a CA certificate, which must have issued the certificate contained in the signature, is required.
+type SignatureVerification struct {
+	// Name is the name of the signature.
+	Name string
+	// PublicKeyPath is the path to a PEM encoded RSA public key or certificate.
+	PublicKeyPath string
+	// CACertPath is the path to PEM encoded root CA certificates.
+	CACertPath string
+}
+
+// signatureVerifier verifies signatures with the given name.
+type signatureVerifier struct {
+	name      string
+	publicKey *rsa.PublicKey
+	roots     *x509.CertPool
+}
+
+// NormaliseComponentDescriptor returns the normalised form of the serialized component descriptor, which is the input of its digest.
+// Signatures, repository contexts, resource and source access specifications, and labels that are not marked for signing are excluded.
+// Resources with access type "none" are excluded as well.
+func NormaliseComponentDescriptor(descriptor []byte, algorithm string) ([]byte, error) {
+	var cd map[string]any
+	if err := yaml.Unmarshal(descriptor, &cd); err != nil {
+		return nil, fmt.Errorf("error unmarshalling component descriptor: %w", err)
+	}
+
+	switch algorithm {
+	case JSONNormalisationV1:
+		return canonicalJSON(legacyNormalisationEntries(excludeFromSignature(cd, false)))
+	case JSONNormalisationV2:
+		return canonicalJSON(excludeFromSignature(cd, true))
+	default:
+		return nil, fmt.Errorf("unsupported normalisation algorithm %q", algorithm)
+	}
+}
+
+// ComponentDescriptorDigest returns the hex encoded digest of the normalised component descriptor.
+func ComponentDescriptorDigest(descriptor []byte, normalisationAlgorithm, hashAlgorithm string) (string, error) {
+	if hashAlgorithm != HashAlgorithmSHA256 {
+		return "", fmt.Errorf("unsupported hash algorithm %q", hashAlgorithm)
+	}
+
+	normalised, err := NormaliseComponentDescriptor(descriptor, normalisationAlgorithm)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(normalised)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// newSignatureVerifier loads the keys of the given signature verification.
+func newSignatureVerifier(v SignatureVerification) (*signatureVerifier, error) {
+	if v.Name == "" {
+		return nil, fmt.Errorf("signature name is required")
+	}
+	if v.PublicKeyPath == "" && v.CACertPath == "" {
+		return nil, fmt.Errorf("public key or CA certificate is required to verify signature %s", v.Name)
+	}
+
+	verifier := &signatureVerifier{name: v.Name}
+
+	if v.PublicKeyPath != "" {
+		data, err := os.ReadFile(v.PublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading public key of signature %s: %w", v.Name, err)
+		}
+		verifier.publicKey, err = parseRSAPublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key of signature %s: %w", v.Name, err)
+		}
+	}
+
+	if v.CACertPath != "" {
+		data, err := os.ReadFile(v.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate of signature %s: %w", v.Name, err)
+		}
+		verifier.roots = x509.NewCertPool()
+		if !verifier.roots.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no CA certificate found in %s", v.CACertPath)
+		}
+	}
+
+	return verifier, nil
+}
+
+// verify verifies the signature with the name of the verifier of a component descriptor with the given serialized form.
+func (v *signatureVerifier) verify(descriptor []byte, signatures []Signature) error {
+	idx := slices.IndexFunc(signatures, func(s Signature) bool { return s.Name == v.name })
+	if idx == -1 {
+		return fmt.Errorf("signature %s not found", v.name)
+	}
+	signature := signatures[idx]
+
+	digest, err := ComponentDescriptorDigest(descriptor, signature.Digest.NormalisationAlgorithm, signature.Digest.HashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("error computing digest for signature %s: %w", v.name, err)
+	}
+	if digest != signature.Digest.Value {
+		return fmt.Errorf("digest mismatch for signature %s: component descriptor has digest %s, signed digest is %s", v.name, digest, signature.Digest.Value)
+	}
+
+	digestBytes, err := hex.DecodeString(digest)
+	if err != nil {
+		return err
+	}
+
+	publicKey, signatureBytes, err := v.resolveSignature(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature %s: %w", v.name, err)
+	}
+
+	switch signature.Signature.Algorithm {
+	case SignatureAlgorithmRSAPKCS1v15:
+		err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digestBytes, signatureBytes)
+	case SignatureAlgorithmRSAPSS:
+		err = rsa.VerifyPSS(publicKey, crypto.SHA256, digestBytes, signatureBytes, nil)
+	default:
+		return fmt.Errorf("unsupported algorithm %q of signature %s", signature.Signature.Algorithm, v.name)
+	}
+	if err != nil {
+		return fmt.Errorf("signature %s does not match: %w", v.name, err)
+	}
+
+	return nil
+}
+
+// resolveSignature returns the public key to verify the signature with, and the raw signature.
+// PEM encoded signatures carry the certificate chain of the signer, which is verified against the CA certificates.
+func (v *signatureVerifier) resolveSignature(spec SignatureSpec) (*rsa.PublicKey, []byte, error) {
+	switch spec.MediaType {
+	case SignatureMediaTypeRSA, "":
+		if v.publicKey == nil {
+			return nil, nil, fmt.Errorf("a public key is required to verify signatures of media type %s", SignatureMediaTypeRSA)
+		}
+		signatureBytes, err := hex.DecodeString(spec.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("signature value is not hex encoded: %w", err)
+		}
+		return v.publicKey, signatureBytes, nil
+
+	case SignatureMediaTypePEM:
+		var (
+			signatureBytes []byte
+			certs          []*x509.Certificate
+		)
+		rest := []byte(spec.Value)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			switch block.Type {
+			case pemTypeSignature:
+				signatureBytes = block.Bytes
+				if algorithm := block.Headers[pemHeaderAlgorithm]; algorithm != "" && algorithm != spec.Algorithm {
+					return nil, nil, fmt.Errorf("signature algorithm %s does not match %s", algorithm, spec.Algorithm)
+				}
+			case pemTypeCertificate:
+				cert, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return nil, nil, fmt.Errorf("error parsing certificate: %w", err)
+				}
+				certs = append(certs, cert)
+			}
+		}
+		if signatureBytes == nil {
+			return nil, nil, fmt.Errorf("no %s block found", pemTypeSignature)
+		}
+
+		if v.publicKey != nil {
+			return v.publicKey, signatureBytes, nil
+		}
+		if len(certs) == 0 {
+			return nil, nil, fmt.Errorf("no certificate found to verify the signature with the CA certificate")
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         v.roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return nil, nil, fmt.Errorf("certificate of the signer is not trusted: %w", err)
+		}
+		if spec.Issuer != "" && spec.Issuer != certs[0].Subject.CommonName && spec.Issuer != certs[0].Subject.String() {
+			return nil, nil, fmt.Errorf("certificate of the signer %q does not match the issuer %q", certs[0].Subject.String(), spec.Issuer)
+		}
+
+		publicKey, ok := certs[0].PublicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("certificate of the signer does not contain an RSA public key")
+		}
+		return publicKey, signatureBytes, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unsupported signature media type %q", spec.MediaType)
+	}
+}
+
+// parseRSAPublicKey parses a PEM encoded RSA public key in PKIX or PKCS #1 format, or the public key of a certificate.
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case pemTypeCertificate:
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T, only RSA keys are supported", key)
+	}
+	return publicKey, nil
+}
+
+// excludeFromSignature removes all fields of the component descriptor that are not covered by its digest.
+func excludeFromSignature(cd map[string]any, excludeMeta bool) map[string]any {
+	result := map[string]any{}
+	for key, value := range cd {
+		switch key {
+		case "signatures", "nestedDigests":
+			continue
+		case "meta":
+			if excludeMeta {
+				continue
+			}
+		}
+		result[key] = value
+	}
+
+	component, ok := result["component"].(map[string]any)
+	if !ok {
+		return dropNullValues(result).(map[string]any)
+	}
+
+	normalised := map[string]any{}
+	for key, value := range component {
+		switch key {
+		case "repositoryContexts":
+			continue
+		case "labels":
+			value = signingLabels(value)
+		case "provider":
+			if provider, ok := value.(map[string]any); ok {
+				value = withSigningLabels(provider)
+			}
+		case "resources":
+			value = excludeArtifactFields(value, true)
+		case "sources":
+			value = excludeArtifactFields(value, false)
+		case "componentReferences", "references":
+			if refs, ok := value.([]any); ok {
+				normalisedRefs := make([]any, 0, len(refs))
+				for _, ref := range refs {
+					if m, ok := ref.(map[string]any); ok {
+						ref = withSigningLabels(m)
+					}
+					normalisedRefs = append(normalisedRefs, ref)
+				}
+				value = normalisedRefs
+			}
+		}
+		if value != nil {
+			normalised[key] = value
+		}
+	}
+	result["component"] = normalised
+
+	return dropNullValues(result).(map[string]any)
+}
+
+// excludeArtifactFields removes the access specifications and unsigned labels of resources or sources.
+func excludeArtifactFields(value any, isResource bool) any {
+	artifacts, ok := value.([]any)
+	if !ok {
+		return value
+	}
+
+	result := make([]any, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		m, ok := artifact.(map[string]any)
+		if !ok {
+			result = append(result, artifact)
+			continue
+		}
+		if access, ok := m["access"].(map[string]any); ok && isResource && strings.EqualFold(fmt.Sprint(access["type"]), accessTypeNone) {
+			continue
+		}
+
+		normalised := withSigningLabels(m)
+		delete(normalised, "access")
+		delete(normalised, "srcRef")
+		delete(normalised, "srcRefs")
+		result = append(result, normalised)
+	}
+	return result
+}
+
+// withSigningLabels returns a copy of the map, in which the labels only contain labels marked for signing.
+func withSigningLabels(m map[string]any) map[string]any {
+	result := make(map[string]any, len(m))
+	for key, value := range m {
+		if key == "labels" {
+			value = signingLabels(value)
+			if value == nil {
+				continue
+			}
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// signingLabels returns the labels that are marked for signing, or nil if there are none.
+func signingLabels(value any) any {
+	labels, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	result := make([]any, 0, len(labels))
+	for _, label := range labels {
+		if m, ok := label.(map[string]any); ok && m["signing"] == true {
+			result = append(result, label)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// dropNullValues removes map entries with null values recursively.
+func dropNullValues(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, entry := range v {
+			if entry != nil {
+				result[key] = dropNullValues(entry)
+			}
+		}
+		return result
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, entry := range v {
+			result = append(result, dropNullValues(entry))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// legacyNormalisationEntries converts maps into lists of single-entry maps sorted by key, as used by JSONNormalisationV1.
+func legacyNormalisationEntries(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		entries := make([]any, 0, len(keys))
+		for _, key := range keys {
+			entries = append(entries, map[string]any{key: legacyNormalisationEntries(v[key])})
+		}
+		return entries
+	case []any:
+		entries := make([]any, 0, len(v))
+		for _, entry := range v {
+			entries = append(entries, legacyNormalisationEntries(entry))
+		}
+		return entries
+	default:
+		return value
+	}
+}
+
+// canonicalJSON serializes the value as JSON with sorted keys, without insignificant whitespace and HTML escaping.
+func canonicalJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
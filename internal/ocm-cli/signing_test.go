@@ -0,0 +1,614 @@
+package ocm_cli_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+const (
+	signedRootComponent      = "github.com/openmcp-project/bootstrapper/test-signed-root"
+	signedTemplatesComponent = "github.com/openmcp-project/bootstrapper/test-signed-templates"
+	signatureName            = "openmcp"
+)
+
+func TestNormaliseComponentDescriptor(t *testing.T) {
+	descriptor := []byte(`meta:
+  schemaVersion: v2
+component:
+  name: example.com/test
+  version: v0.0.1
+  provider: openmcp-project
+  repositoryContexts:
+    - type: OCIRegistry
+      baseUrl: ghcr.io
+  labels:
+    - name: unsigned
+      value: a
+    - name: signed
+      value: "<b>"
+      signing: true
+  resources:
+    - name: image
+      version: v0.0.1
+      access:
+        type: ociArtifact
+        imageReference: ghcr.io/example/image:v0.0.1
+      digest:
+        hashAlgorithm: SHA-256
+        normalisationAlgorithm: ociArtifactDigest/v1
+        value: abc
+    - name: none
+      version: v0.0.1
+      access:
+        type: none
+  componentReferences: []
+signatures:
+  - name: test
+`)
+
+	testCases := []struct {
+		desc        string
+		algorithm   string
+		expected    string
+		expectError bool
+	}{
+		{
+			desc:      "should normalise as canonical JSON",
+			algorithm: ocmcli.JSONNormalisationV2,
+			expected:  `{"component":{"componentReferences":[],"labels":[{"name":"signed","signing":true,"value":"<b>"}],"name":"example.com/test","provider":"openmcp-project","resources":[{"digest":{"hashAlgorithm":"SHA-256","normalisationAlgorithm":"ociArtifactDigest/v1","value":"abc"},"name":"image","version":"v0.0.1"}],"version":"v0.0.1"}}`,
+		},
+		{
+			desc:      "should normalise with legacy entries",
+			algorithm: ocmcli.JSONNormalisationV1,
+			expected:  `[{"component":[{"componentReferences":[]},{"labels":[[{"name":"signed"},{"signing":true},{"value":"<b>"}]]},{"name":"example.com/test"},{"provider":"openmcp-project"},{"resources":[[{"digest":[{"hashAlgorithm":"SHA-256"},{"normalisationAlgorithm":"ociArtifactDigest/v1"},{"value":"abc"}]},{"name":"image"},{"version":"v0.0.1"}]]},{"version":"v0.0.1"}]},{"meta":[{"schemaVersion":"v2"}]}]`,
+		},
+		{
+			desc:        "should fail for an unsupported algorithm",
+			algorithm:   "unknown",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			normalised, err := ocmcli.NormaliseComponentDescriptor(descriptor, tc.algorithm)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(normalised))
+		})
+	}
+}
+
+func TestNativeClientVerifyComponentVersion(t *testing.T) {
+	keyDir := t.TempDir()
+
+	key := generateKey(t)
+	publicKeyPath := writePEM(t, keyDir, "public-key.pem", "PUBLIC KEY", marshalPublicKey(t, key))
+	otherKeyPath := writePEM(t, keyDir, "other-key.pem", "PUBLIC KEY", marshalPublicKey(t, generateKey(t)))
+
+	caKey := generateKey(t)
+	caCert := createCertificate(t, "openmcp-ca", caKey, nil, nil)
+	caCertPath := writePEM(t, keyDir, "ca.pem", "CERTIFICATE", caCert.Raw)
+	otherCAKey := generateKey(t)
+	otherCACertPath := writePEM(t, keyDir, "other-ca.pem", "CERTIFICATE", createCertificate(t, "other-ca", otherCAKey, nil, nil).Raw)
+	signerCert := createCertificate(t, "openmcp-signer", key, caCert, caKey)
+
+	testCases := []struct {
+		desc        string
+		pemSigned   bool
+		tamper      func(t *testing.T, ctf string)
+		options     ocmcli.VerifyOptions
+		expectError bool
+	}{
+		{
+			desc:    "should verify a signature with a public key",
+			options: ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}},
+		},
+		{
+			desc:      "should verify a signature with a CA certificate",
+			pemSigned: true,
+			options:   ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, CACertPath: caCertPath}}},
+		},
+		{
+			desc:        "should fail with a certificate of another CA",
+			pemSigned:   true,
+			options:     ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, CACertPath: otherCACertPath}}},
+			expectError: true,
+		},
+		{
+			desc:        "should fail with another public key",
+			options:     ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: otherKeyPath}}},
+			expectError: true,
+		},
+		{
+			desc:        "should fail for a missing signature",
+			options:     ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: "unknown", PublicKeyPath: publicKeyPath}}},
+			expectError: true,
+		},
+		{
+			desc:        "should fail without signatures to verify",
+			expectError: true,
+		},
+		{
+			desc: "should fail for a modified component descriptor",
+			tamper: func(t *testing.T, ctf string) {
+				testutil.UpdateComponentDescriptor(t, ctf, signedRootComponent, "v0.0.1", func(descriptor map[string]any) {
+					descriptor["component"].(map[string]any)["provider"] = "someone-else"
+				})
+			},
+			options:     ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}},
+			expectError: true,
+		},
+		{
+			desc: "should fail for a modified referenced component descriptor",
+			tamper: func(t *testing.T, ctf string) {
+				testutil.UpdateComponentDescriptor(t, ctf, signedTemplatesComponent, "v0.1.0", func(descriptor map[string]any) {
+					descriptor["component"].(map[string]any)["provider"] = "someone-else"
+				})
+			},
+			options:     ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}},
+			expectError: true,
+		},
+		{
+			desc: "should fail for a modified local blob",
+			tamper: func(t *testing.T, ctf string) {
+				testutil.UpdateComponentDescriptor(t, ctf, signedTemplatesComponent, "v0.1.0", func(descriptor map[string]any) {
+					resource := descriptor["component"].(map[string]any)["resources"].([]any)[0].(map[string]any)
+					blobPath, err := ocmcli.CTFBlobPath(ctf, resource["access"].(map[string]any)["localReference"].(string))
+					assert.NoError(t, err)
+					assert.NoError(t, os.WriteFile(blobPath, []byte("modified"), 0o644))
+				})
+			},
+			options:     ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctf := testutil.BuildComponentCTF("./testdata/05/component-constructor.yaml", t)
+			testutil.SignComponentVersion(t, ctf, signedRootComponent, "v0.0.1", signatureName, key)
+			if tc.pemSigned {
+				convertToPEMSignature(t, ctf, signerCert)
+			}
+			if tc.tamper != nil {
+				tc.tamper(t, ctf)
+			}
+
+			client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+			assert.NoError(t, err)
+
+			err = client.VerifyComponentVersion(t.Context(), fmt.Sprintf("%s//%s:v0.0.1", ctf, signedRootComponent), tc.options)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestNativeClientVerifyOCMSignedComponentVersion verifies component versions signed by the OCM cli,
+// so that the normalisation and the signature verification are checked against the reference implementation.
+func TestNativeClientVerifyOCMSignedComponentVersion(t *testing.T) {
+	testutil.DownloadOCMAndAddToPath(t)
+
+	keyDir := t.TempDir()
+	key := generateKey(t)
+	privateKeyPath := writePEM(t, keyDir, "private-key.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	publicKeyPath := writePEM(t, keyDir, "public-key.pem", "PUBLIC KEY", marshalPublicKey(t, key))
+	otherKeyPath := writePEM(t, keyDir, "other-key.pem", "PUBLIC KEY", marshalPublicKey(t, generateKey(t)))
+
+	for _, normalisationAlgorithm := range []string{ocmcli.JSONNormalisationV1, ocmcli.JSONNormalisationV2} {
+		t.Run(normalisationAlgorithm, func(t *testing.T) {
+			ctf := testutil.BuildComponent("./testdata/07/component-constructor.yaml", t)
+			location := fmt.Sprintf("%s//%s:v0.0.1", ctf, signedRootComponent)
+			testutil.SignComponentVersionWithOCM(t, location, signatureName, privateKeyPath, normalisationAlgorithm)
+
+			client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+			assert.NoError(t, err)
+			assert.NoError(t, client.VerifyComponentVersion(t.Context(), location, ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}}))
+			assert.Error(t, client.VerifyComponentVersion(t.Context(), location, ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: otherKeyPath}}}))
+
+			testutil.UpdateComponentDescriptor(t, ctf, signedTemplatesComponent, "v0.1.0", func(descriptor map[string]any) {
+				descriptor["component"].(map[string]any)["provider"] = "someone-else"
+			})
+			client, err = ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+			assert.NoError(t, err)
+			assert.Error(t, client.VerifyComponentVersion(t.Context(), location, ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}}))
+		})
+	}
+}
+
+// convertToPEMSignature replaces the hex encoded signature of the root component with a PEM encoded signature containing the certificate of the signer.
+func convertToPEMSignature(t *testing.T, ctf string, signerCert *x509.Certificate) {
+	testutil.UpdateComponentDescriptor(t, ctf, signedRootComponent, "v0.0.1", func(descriptor map[string]any) {
+		spec := descriptor["signatures"].([]any)[0].(map[string]any)["signature"].(map[string]any)
+		signature, err := hex.DecodeString(spec["value"].(string))
+		assert.NoError(t, err)
+
+		value := pem.EncodeToMemory(&pem.Block{Type: "SIGNATURE", Bytes: signature, Headers: map[string]string{"Signature Algorithm": ocmcli.SignatureAlgorithmRSAPKCS1v15}})
+		value = append(value, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signerCert.Raw})...)
+		spec["value"] = string(value)
+		spec["mediaType"] = ocmcli.SignatureMediaTypePEM
+		spec["issuer"] = signerCert.Subject.CommonName
+	})
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func marshalPublicKey(t *testing.T, key *rsa.PrivateKey) []byte {
+	data, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	return data
+}
+
+// createCertificate creates a certificate for the key, which is self-signed if no parent is given.
+func createCertificate(t *testing.T, commonName string, key *rsa.PrivateKey, parent *x509.Certificate, parentKey *rsa.PrivateKey) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	data, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(data)
+	assert.NoError(t, err)
+	return cert
+}
+
+func writePEM(t *testing.T, dir, fileName, blockType string, data []byte) string {
+	path := filepath.Join(dir, fileName)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o644))
+	return path
+}
+
+func TestNativeClientRejectsChangesAfterVerification(t *testing.T) {
+	keyDir := t.TempDir()
+	key := generateKey(t)
+	options := ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: writePEM(t, keyDir, "public-key.pem", "PUBLIC KEY", marshalPublicKey(t, key))}}}
+
+	testCases := []struct {
+		desc   string
+		tamper func(t *testing.T, ctf string)
+		check  func(t *testing.T, client ocmcli.Client, ctf string) error
+	}{
+		{
+			desc: "should reject a component descriptor modified after verification",
+			tamper: func(t *testing.T, ctf string) {
+				// the manifest is replaced in place, as the artifact index has already been read by the client
+				manifestPath := templatesManifestPath(t, ctf)
+				testutil.UpdateComponentDescriptor(t, ctf, signedTemplatesComponent, "v0.1.0", func(descriptor map[string]any) {
+					descriptor["component"].(map[string]any)["provider"] = "someone-else"
+				})
+				manifest, err := os.ReadFile(templatesManifestPath(t, ctf))
+				assert.NoError(t, err)
+				assert.NoError(t, os.WriteFile(manifestPath, manifest, 0o644))
+			},
+			check: func(t *testing.T, client ocmcli.Client, ctf string) error {
+				_, err := client.GetComponentVersion(t.Context(), fmt.Sprintf("%s//%s:v0.1.0", ctf, signedTemplatesComponent))
+				return err
+			},
+		},
+		{
+			desc: "should reject a local blob modified after verification",
+			tamper: func(t *testing.T, ctf string) {
+				client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+				assert.NoError(t, err)
+				cv, err := client.GetComponentVersion(t.Context(), fmt.Sprintf("%s//%s:v0.1.0", ctf, signedTemplatesComponent))
+				assert.NoError(t, err)
+				blobPath, err := ocmcli.CTFBlobPath(ctf, *cv.Component.Resources[0].Access.LocalReference)
+				assert.NoError(t, err)
+				assert.NoError(t, os.WriteFile(blobPath, testutil.TarFiles(t, map[string][]byte{"modified.yaml": []byte("modified")}), 0o644))
+			},
+			check: func(t *testing.T, client ocmcli.Client, ctf string) error {
+				return client.DownloadDirectoryResource(t.Context(), fmt.Sprintf("%s//%s:v0.1.0", ctf, signedTemplatesComponent), "gitops-templates", t.TempDir())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctf := testutil.BuildComponentCTF("./testdata/05/component-constructor.yaml", t)
+			testutil.SignComponentVersion(t, ctf, signedRootComponent, "v0.0.1", signatureName, key)
+
+			client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+			assert.NoError(t, err)
+			assert.NoError(t, client.VerifyComponentVersion(t.Context(), fmt.Sprintf("%s//%s:v0.0.1", ctf, signedRootComponent), options))
+			assert.NoError(t, tc.check(t, client, ctf))
+
+			tc.tamper(t, ctf)
+			assert.Error(t, tc.check(t, client, ctf))
+		})
+	}
+}
+
+func TestNativeClientVerifyOCIArtifactByTag(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	imageReference := strings.TrimPrefix(server.URL, "http://") + "/templates:v1.0.0"
+	ref, err := name.ParseReference(imageReference)
+	assert.NoError(t, err)
+	image, err := random.Image(256, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(ref, image))
+
+	constructorDir := t.TempDir()
+	testutil.WriteToFile(t, filepath.Join(constructorDir, "component-constructor.yaml"), fmt.Sprintf(`components:
+  - name: %s
+    version: v0.0.1
+    provider:
+      name: openmcp-project
+    resources:
+      - name: gitops-templates
+        type: ociImage
+        version: v1.0.0
+        access:
+          type: ociArtifact
+          imageReference: %s
+`, signedRootComponent, imageReference))
+	ctf := testutil.BuildComponentCTF(filepath.Join(constructorDir, "component-constructor.yaml"), t)
+
+	key := generateKey(t)
+	testutil.SignComponentVersion(t, ctf, signedRootComponent, "v0.0.1", signatureName, key)
+	options := ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: writePEM(t, t.TempDir(), "public-key.pem", "PUBLIC KEY", marshalPublicKey(t, key))}}}
+	location := fmt.Sprintf("%s//%s:v0.0.1", ctf, signedRootComponent)
+
+	client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+	assert.NoError(t, err)
+	assert.NoError(t, client.VerifyComponentVersion(t.Context(), location, options))
+
+	// the tag is moved to another image after signing
+	movedImage, err := random.Image(256, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(ref, movedImage))
+	assert.Error(t, client.VerifyComponentVersion(t.Context(), location, options))
+
+	// the signed image is still downloaded by its digest
+	downloadDir := t.TempDir()
+	assert.NoError(t, client.DownloadDirectoryResource(t.Context(), location, "gitops-templates", downloadDir))
+	layers, err := image.Layers()
+	assert.NoError(t, err)
+	content, err := layers[0].Uncompressed()
+	assert.NoError(t, err)
+	defer func() {
+		_ = content.Close()
+	}()
+	header, err := tar.NewReader(content).Next()
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(downloadDir, header.Name))
+}
+
+func TestComponentGetterVerifiesComponentVersionsOutsideTheGraph(t *testing.T) {
+	const overrideComponent = "github.com/openmcp-project/bootstrapper/test-signed-override"
+
+	keyDir := t.TempDir()
+	key := generateKey(t)
+	publicKeyPath := writePEM(t, keyDir, "public-key.pem", "PUBLIC KEY", marshalPublicKey(t, key))
+
+	ctf := testutil.BuildComponentCTF("./testdata/05/component-constructor.yaml", t)
+	testutil.SignComponentVersion(t, ctf, signedRootComponent, "v0.0.1", signatureName, key)
+
+	constructorDir := t.TempDir()
+	testutil.WriteToFile(t, filepath.Join(constructorDir, "component-constructor.yaml"), fmt.Sprintf(`components:
+  - name: %s
+    version: v0.2.0
+    provider:
+      name: openmcp-project
+  - name: %s
+    version: v0.3.0
+    provider:
+      name: openmcp-project
+`, overrideComponent, overrideComponent))
+	testutil.AddComponentsToCTF(filepath.Join(constructorDir, "component-constructor.yaml"), ctf, t)
+	testutil.SignComponentVersion(t, ctf, overrideComponent, "v0.3.0", signatureName, key)
+
+	getter := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.1", ctf, signedRootComponent), "gitops-templates/gitops-templates", ocmcli.NoOcmConfig)
+	getter.SetVerification(ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}})
+	assert.NoError(t, getter.InitializeComponents(t.Context()))
+
+	_, err := getter.GetComponentVersion(t.Context(), overrideComponent, "v0.2.0")
+	assert.ErrorContains(t, err, "error verifying component version")
+
+	cv, err := getter.GetComponentVersion(t.Context(), overrideComponent, "v0.3.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.3.0", cv.Component.Version)
+}
+
+func TestNativeClientVerifyArtifactSet(t *testing.T) {
+	keyDir := t.TempDir()
+	key := generateKey(t)
+	options := ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: writePEM(t, keyDir, "public-key.pem", "PUBLIC KEY", marshalPublicKey(t, key))}}}
+
+	testCases := []struct {
+		desc        string
+		tamper      func(t *testing.T, blobPath string)
+		expectError bool
+	}{
+		{
+			desc: "should verify an unmodified artifact set",
+		},
+		{
+			desc: "should fail for a modified layer",
+			tamper: func(t *testing.T, blobPath string) {
+				rewriteTar(t, blobPath, func(name string, content []byte) []byte {
+					if strings.HasPrefix(name, "blobs/") && len(content) > 1024 {
+						return append(content, 0)
+					}
+					return content
+				})
+			},
+			expectError: true,
+		},
+		{
+			desc: "should fail for another main artifact",
+			tamper: func(t *testing.T, blobPath string) {
+				image, err := random.Image(256, 1)
+				assert.NoError(t, err)
+				dir := t.TempDir()
+				testutil.WriteArtifactSet(t, dir, image)
+				files := map[string][]byte{}
+				assert.NoError(t, filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+					if err != nil || d.IsDir() {
+						return err
+					}
+					rel, err := filepath.Rel(dir, path)
+					if err != nil {
+						return err
+					}
+					files[filepath.ToSlash(rel)], err = os.ReadFile(path)
+					return err
+				}))
+				assert.NoError(t, os.WriteFile(blobPath, testutil.TarFiles(t, files), 0o644))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			image, err := random.Image(4096, 2)
+			assert.NoError(t, err)
+			constructorDir := t.TempDir()
+			testutil.WriteArtifactSet(t, filepath.Join(constructorDir, "image"), image)
+			testutil.WriteToFile(t, filepath.Join(constructorDir, "component-constructor.yaml"), fmt.Sprintf(`components:
+  - name: %s
+    version: v0.0.1
+    provider:
+      name: openmcp-project
+    resources:
+      - name: image
+        type: ociImage
+        version: v1.0.0
+        input:
+          type: dir
+          path: image
+          mediaType: application/vnd.oci.image.manifest.v1+tar
+          referenceName: ghcr.io/example/image
+`, signedRootComponent))
+			ctf := testutil.BuildComponentCTF(filepath.Join(constructorDir, "component-constructor.yaml"), t)
+			testutil.SignComponentVersion(t, ctf, signedRootComponent, "v0.0.1", signatureName, key)
+			location := fmt.Sprintf("%s//%s:v0.0.1", ctf, signedRootComponent)
+
+			client, err := ocmcli.NewClientForBackend(ocmcli.BackendNative, ocmcli.NoOcmConfig)
+			assert.NoError(t, err)
+			cv, err := client.GetComponentVersion(t.Context(), location)
+			assert.NoError(t, err)
+			digest, err := image.Digest()
+			assert.NoError(t, err)
+			assert.Equal(t, &ocmcli.DigestSpec{HashAlgorithm: ocmcli.HashAlgorithmSHA256, NormalisationAlgorithm: ocmcli.OCIArtifactDigestV1, Value: digest.Hex}, cv.Component.Resources[0].Digest)
+
+			if tc.tamper != nil {
+				blobPath, err := ocmcli.CTFBlobPath(ctf, *cv.Component.Resources[0].Access.LocalReference)
+				assert.NoError(t, err)
+				tc.tamper(t, blobPath)
+			}
+
+			err = client.VerifyComponentVersion(t.Context(), location, options)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// rewriteTar replaces the content of every file in the tar archive at path with the result of update.
+func rewriteTar(t *testing.T, path string, update func(name string, content []byte) []byte) {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	files := map[string][]byte{}
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		files[header.Name] = update(header.Name, content)
+	}
+	assert.NoError(t, os.WriteFile(path, testutil.TarFiles(t, files), 0o644))
+}
+
+// templatesManifestPath returns the path of the manifest blob of the signed templates component version in the ctf.
+func templatesManifestPath(t *testing.T, ctf string) string {
+	data, err := os.ReadFile(filepath.Join(ctf, ocmcli.CTFArtifactIndexFileName))
+	assert.NoError(t, err)
+	var index ocmcli.CTFArtifactIndex
+	assert.NoError(t, json.Unmarshal(data, &index))
+	for _, artifact := range index.Artifacts {
+		if artifact.Repository == "component-descriptors/"+signedTemplatesComponent && artifact.Tag == "v0.1.0" {
+			path, err := ocmcli.CTFBlobPath(ctf, artifact.Digest)
+			assert.NoError(t, err)
+			return path
+		}
+	}
+	t.Fatalf("component version %s:v0.1.0 not found in ctf", signedTemplatesComponent)
+	return ""
+}
+
+func TestComponentGetterVerifiesBeforeUse(t *testing.T) {
+	keyDir := t.TempDir()
+	key := generateKey(t)
+	publicKeyPath := writePEM(t, keyDir, "public-key.pem", "PUBLIC KEY", marshalPublicKey(t, key))
+	otherKeyPath := writePEM(t, keyDir, "other-key.pem", "PUBLIC KEY", marshalPublicKey(t, generateKey(t)))
+
+	ctf := testutil.BuildComponentCTF("./testdata/05/component-constructor.yaml", t)
+	testutil.SignComponentVersion(t, ctf, signedRootComponent, "v0.0.1", signatureName, key)
+	location := fmt.Sprintf("%s//%s:v0.0.1", ctf, signedRootComponent)
+
+	getter := ocmcli.NewComponentGetter(location, "gitops-templates/gitops-templates", ocmcli.NoOcmConfig)
+	getter.SetVerification(ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: publicKeyPath}}})
+	assert.NoError(t, getter.InitializeComponents(t.Context()))
+	assert.NoError(t, getter.DownloadTemplatesResource(t.Context(), t.TempDir()))
+
+	getter = ocmcli.NewComponentGetter(location, "gitops-templates/gitops-templates", ocmcli.NoOcmConfig)
+	getter.SetVerification(ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: signatureName, PublicKeyPath: otherKeyPath}}})
+	assert.Error(t, getter.InitializeComponents(t.Context()))
+	assert.Nil(t, getter.RootComponentVersion())
+}
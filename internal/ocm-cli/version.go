@@ -0,0 +1,103 @@
+package ocm_cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// LatestVersion is the version constraint selecting the highest version of a component that is not a pre-release.
+	LatestVersion = "latest"
+)
+
+// IsVersionConstraint returns true if the version of a component location is a semantic version constraint, e.g. "~0.0.18",
+// ">=0.1.0 <0.2.0" or "latest", which must be resolved against the repository, and false if it is an exact version.
+func IsVersionConstraint(version string) bool {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return false
+	}
+	if version == LatestVersion {
+		return true
+	}
+	if _, err := semver.NewVersion(version); err == nil {
+		return false
+	}
+	_, err := semver.NewConstraint(version)
+	return err == nil
+}
+
+// ResolveVersionConstraint returns the highest of the given versions satisfying the constraint.
+// The constraint "latest" selects the highest version that is not a pre-release.
+// Versions that are not semantic versions are ignored.
+func ResolveVersionConstraint(constraint string, versions []string) (string, error) {
+	constraint = strings.TrimSpace(constraint)
+
+	var constraints *semver.Constraints
+	if constraint != LatestVersion {
+		var err error
+		constraints, err = semver.NewConstraint(constraint)
+		if err != nil {
+			return "", fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		}
+	}
+
+	var (
+		resolved       string
+		resolvedSemver *semver.Version
+	)
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			continue
+		}
+		if constraints == nil && v.Prerelease() != "" {
+			continue
+		}
+		if constraints != nil && !constraints.Check(v) {
+			continue
+		}
+		if resolvedSemver == nil || v.GreaterThan(resolvedSemver) {
+			resolved, resolvedSemver = version, v
+		}
+	}
+
+	if resolvedSemver == nil {
+		return "", fmt.Errorf("no version satisfies the version constraint %q, available versions: %s", constraint, strings.Join(versions, ", "))
+	}
+	return resolved, nil
+}
+
+// resolveComponentLocation resolves the version constraint of the component location against the versions in its repository.
+// Locations with an exact version or without a version are returned unchanged.
+func resolveComponentLocation(ctx context.Context, client Client, location string) (resolvedLocation, constraint string, err error) {
+	repo, err := extractRepoFromLocation(location)
+	if err != nil {
+		return "", "", err
+	}
+
+	nameAndVersion := strings.TrimPrefix(location, repo+"//")
+	idx := strings.LastIndex(nameAndVersion, ":")
+	if idx == -1 {
+		return location, "", nil
+	}
+	componentName, version := nameAndVersion[:idx], nameAndVersion[idx+1:]
+	if !IsVersionConstraint(version) {
+		return location, "", nil
+	}
+
+	versions, err := client.ListComponentVersions(ctx, repo, componentName)
+	if err != nil {
+		return "", "", fmt.Errorf("error listing versions of component %s: %w", componentName, err)
+	}
+
+	resolved, err := ResolveVersionConstraint(version, versions)
+	if err != nil {
+		return "", "", fmt.Errorf("error resolving version of component %s: %w", componentName, err)
+	}
+
+	return buildLocation(repo, componentName, resolved), version, nil
+}
@@ -0,0 +1,130 @@
+package ocm_cli_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+func TestIsVersionConstraint(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected bool
+	}{
+		{version: "v0.0.18", expected: false},
+		{version: "0.0.18", expected: false},
+		{version: "v0.1.0-rc.1", expected: false},
+		{version: "main-1234", expected: false},
+		{version: "", expected: false},
+		{version: "latest", expected: true},
+		{version: "~0.0.18", expected: true},
+		{version: "^0.1", expected: true},
+		{version: ">=0.1.0 <0.2.0", expected: true},
+		{version: "0.1.x", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ocmcli.IsVersionConstraint(tc.version))
+		})
+	}
+}
+
+func TestResolveVersionConstraint(t *testing.T) {
+	versions := []string{"v0.0.17", "v0.0.18", "v0.0.19", "v0.1.0", "v0.1.1", "v0.2.0-rc.1", "main-1234"}
+
+	testCases := []struct {
+		desc        string
+		constraint  string
+		expected    string
+		expectError bool
+	}{
+		{
+			desc:       "should resolve latest to the highest release",
+			constraint: "latest",
+			expected:   "v0.1.1",
+		},
+		{
+			desc:       "should resolve a tilde range",
+			constraint: "~0.0.18",
+			expected:   "v0.0.19",
+		},
+		{
+			desc:       "should resolve a range",
+			constraint: ">=0.1.0 <0.2.0",
+			expected:   "v0.1.1",
+		},
+		{
+			desc:       "should resolve a pre-release range",
+			constraint: ">=0.2.0-0",
+			expected:   "v0.2.0-rc.1",
+		},
+		{
+			desc:        "should fail if no version satisfies the constraint",
+			constraint:  ">=1.0.0",
+			expectError: true,
+		},
+		{
+			desc:        "should fail for an invalid constraint",
+			constraint:  "not a constraint",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			version, err := ocmcli.ResolveVersionConstraint(tc.constraint, versions)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, version)
+		})
+	}
+}
+
+func TestComponentGetterResolvesVersionConstraint(t *testing.T) {
+	ctf := testutil.BuildComponentCTF("./testdata/03/component-constructor.yaml", t)
+
+	testCases := []struct {
+		desc               string
+		version            string
+		expectedVersion    string
+		expectedConstraint string
+	}{
+		{
+			desc:               "should resolve latest",
+			version:            "latest",
+			expectedVersion:    "v0.0.2",
+			expectedConstraint: "latest",
+		},
+		{
+			desc:               "should resolve a range",
+			version:            "<0.0.2",
+			expectedVersion:    "v0.0.1",
+			expectedConstraint: "<0.0.2",
+		},
+		{
+			desc:            "should keep an exact version",
+			version:         "v0.0.1",
+			expectedVersion: "v0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			compGetter := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:%s", ctf, nativeRootComponent, tc.version), "", ocmcli.NoOcmConfig)
+			if !assert.NoError(t, compGetter.InitializeComponents(t.Context())) {
+				return
+			}
+			assert.Equal(t, tc.expectedVersion, compGetter.RootComponentVersion().Component.Version)
+			assert.Equal(t, fmt.Sprintf("%s//%s:%s", ctf, nativeRootComponent, tc.expectedVersion), compGetter.RootComponentLocation())
+			assert.Equal(t, tc.expectedConstraint, compGetter.VersionConstraint())
+		})
+	}
+}
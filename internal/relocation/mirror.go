@@ -0,0 +1,415 @@
+package relocation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/openmcp-project/bootstrapper/internal/log"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/util"
+)
+
+// MirroredImage describes an OCI artifact resource that has been pushed into the mirror registry.
+type MirroredImage struct {
+	// Component is the name and version of the component containing the resource.
+	Component string
+	// Resource is the name of the resource.
+	Resource string
+	// Source is the original image reference of the resource.
+	Source string
+	// Target is the image reference in the mirror registry.
+	Target string
+}
+
+// Mirror pushes the OCI artifact resources of component versions into the mirror registry.
+type Mirror struct {
+	client    ocmcli.Client
+	repo      string
+	keychain  authn.Keychain
+	relocator *Relocator
+	insecure  bool
+}
+
+// NewMirror creates a Mirror for the component versions of the given component getter.
+// Registry credentials are taken from the OCM configuration, falling back to the Docker configuration.
+func NewMirror(compGetter *ocmcli.ComponentGetter, relocator *Relocator, insecure bool) (*Mirror, error) {
+	if relocator.MirrorRegistry() == "" {
+		return nil, fmt.Errorf("no mirror registry configured")
+	}
+
+	ocmKeychain, err := ocmcli.NewKeychain(compGetter.OCMConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mirror{
+		client:    compGetter.Client(),
+		repo:      compGetter.Repository(),
+		keychain:  authn.NewMultiKeychain(ocmKeychain, authn.DefaultKeychain),
+		relocator: relocator,
+		insecure:  insecure,
+	}, nil
+}
+
+// MirrorImages pushes all OCI artifact resources of the component versions in the graph into the mirror registry.
+func (m *Mirror) MirrorImages(ctx context.Context, graph *ocmcli.ComponentGraph) ([]MirroredImage, error) {
+	logger := log.GetLogger()
+
+	mirrored := make([]MirroredImage, 0)
+	for _, cv := range graph.ComponentVersions() {
+		for i := range cv.Component.Resources {
+			resource := &cv.Component.Resources[i]
+			if !IsOCIArtifactResource(resource) {
+				continue
+			}
+
+			image, err := m.MirrorResource(ctx, cv, resource)
+			if err != nil {
+				return mirrored, fmt.Errorf("failed to mirror resource %s of component version %s:%s: %w", resource.Name, cv.Component.Name, cv.Component.Version, err)
+			}
+			logger.Infof("Mirrored %s to %s", image.Source, image.Target)
+			mirrored = append(mirrored, *image)
+		}
+	}
+
+	return mirrored, nil
+}
+
+// MirrorResource pushes the OCI artifact of the given resource into the mirror registry.
+// Artifacts stored as local blob are read from the OCM repository, all other artifacts are copied from their registry.
+func (m *Mirror) MirrorResource(ctx context.Context, cv *ocmcli.ComponentVersion, resource *ocmcli.Resource) (*MirroredImage, error) {
+	source, err := OriginalImageReference(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := m.relocator.RelocateImage(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameOptions []name.Option
+	if m.insecure {
+		nameOptions = append(nameOptions, name.Insecure)
+	}
+	targetRef, err := parseImageReference(target, nameOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	log.GetLogger().Debugf("Mirroring resource %s of component version %s:%s to %s", resource.Name, cv.Component.Name, cv.Component.Version, target)
+
+	signedDigest := resourceDigest(resource)
+	if resource.Access.NormalizedType() == ocmcli.LocalBlobAccessType {
+		err = m.pushLocalBlob(ctx, cv, resource, targetRef, signedDigest)
+	} else {
+		var pinned string
+		pinned, err = ocmcli.PinnedImageReference(resource)
+		if err == nil {
+			err = m.copyArtifact(ctx, pinned, targetRef, signedDigest)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &MirroredImage{
+		Component: cv.Component.Name + ":" + cv.Component.Version,
+		Resource:  resource.Name,
+		Source:    source,
+		Target:    target,
+	}, nil
+}
+
+// IsOCIArtifactResource returns true if the resource is an OCI artifact, e.g. an image or a helm chart,
+// that is either stored in an OCI registry or as local blob in OCM artifact set format.
+func IsOCIArtifactResource(resource *ocmcli.Resource) bool {
+	switch resource.Access.NormalizedType() {
+	case ocmcli.OCIArtifactAccessType, ocmcli.OCIRegistryAccessType:
+		return resource.Access.ImageReference != nil
+	case ocmcli.LocalBlobAccessType:
+		return resource.Access.MediaType != nil && isArtifactSetMediaType(*resource.Access.MediaType)
+	default:
+		return false
+	}
+}
+
+// isArtifactSetMediaType returns true for the media types of OCI artifacts in OCM artifact set format,
+// e.g. "application/vnd.oci.image.manifest.v1+tar+gzip".
+func isArtifactSetMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	if !strings.HasSuffix(mediaType, "+tar") && !strings.HasSuffix(mediaType, "+tar+gzip") {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "application/vnd.oci.image.") || strings.HasPrefix(mediaType, "application/vnd.docker.distribution.manifest.")
+}
+
+// copyArtifact copies an OCI artifact from its registry into the mirror registry.
+// An artifact pinned by digest is fetched by its digest, so that the mirror receives the pinned artifact even if the tag has been moved.
+// The artifact is pushed by digest and tagged with the tag of the target reference, if any. It must have the signed digest of the resource, if any.
+func (m *Mirror) copyArtifact(ctx context.Context, source string, targetRef *imageReference, signedDigest *v1.Hash) error {
+	sourceRef, err := parseImageReference(source)
+	if err != nil {
+		return err
+	}
+
+	// the digest of a manifest fetched by digest is verified by go-containerregistry
+	desc, err := remote.Get(sourceRef.reference(), m.remoteOptions(ctx)...)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", source, err)
+	}
+	digestRef, err := targetRef.withDigest(desc.Digest, signedDigest)
+	if err != nil {
+		return err
+	}
+
+	var artifact remote.Taggable
+	if desc.MediaType.IsIndex() {
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return fmt.Errorf("failed to read index %s: %w", source, err)
+		}
+		if err = remote.WriteIndex(digestRef, index, m.remoteOptions(ctx)...); err != nil {
+			return fmt.Errorf("failed to push %s: %w", digestRef.String(), err)
+		}
+		artifact = index
+	} else {
+		image, err := desc.Image()
+		if err != nil {
+			return fmt.Errorf("failed to read artifact %s: %w", source, err)
+		}
+		if err = remote.Write(digestRef, image, m.remoteOptions(ctx)...); err != nil {
+			return fmt.Errorf("failed to push %s: %w", digestRef.String(), err)
+		}
+		artifact = image
+	}
+
+	return m.tagArtifact(ctx, targetRef, artifact)
+}
+
+// tagArtifact tags the artifact pushed by digest with the tag of the target reference, if any.
+func (m *Mirror) tagArtifact(ctx context.Context, targetRef *imageReference, artifact remote.Taggable) error {
+	if targetRef.tag == nil {
+		return nil
+	}
+	if err := remote.Tag(*targetRef.tag, artifact, m.remoteOptions(ctx)...); err != nil {
+		return fmt.Errorf("failed to tag %s: %w", targetRef.tag.String(), err)
+	}
+	return nil
+}
+
+// pushLocalBlob pushes an OCI artifact stored as local blob in OCM artifact set format into the mirror registry.
+func (m *Mirror) pushLocalBlob(ctx context.Context, cv *ocmcli.ComponentVersion, resource *ocmcli.Resource, targetRef *imageReference, signedDigest *v1.Hash) error {
+	tempDir, err := util.CreateTempDir()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = util.DeleteTempDir(tempDir)
+	}()
+
+	blobFile := filepath.Join(tempDir, "blob")
+	location := fmt.Sprintf("%s//%s:%s", m.repo, cv.Component.Name, cv.Component.Version)
+	if err = m.client.DownloadResource(ctx, location, resource.Name, blobFile); err != nil {
+		return fmt.Errorf("failed to download resource %s: %w", resource.Name, err)
+	}
+
+	blob, err := os.Open(blobFile)
+	if err != nil {
+		return fmt.Errorf("failed to open resource %s: %w", resource.Name, err)
+	}
+	artifactSetDir := filepath.Join(tempDir, "artifact-set")
+	err = util.ExtractTarArchive(blob, artifactSetDir)
+	_ = blob.Close()
+	if err != nil {
+		return fmt.Errorf("failed to extract artifact set of resource %s: %w", resource.Name, err)
+	}
+
+	return m.pushArtifactSet(ctx, artifactSetDir, targetRef, signedDigest)
+}
+
+// pushArtifactSet pushes the main artifact of an extracted OCM artifact set by digest to the repository of targetRef
+// and tags it with the tag of targetRef, if any. The main artifact must have the signed digest of the resource, if any.
+func (m *Mirror) pushArtifactSet(ctx context.Context, dir string, targetRef *imageReference, signedDigest *v1.Hash) error {
+	index, err := ocmcli.ReadArtifactSetIndex(dir)
+	if err != nil {
+		return err
+	}
+	main, err := ocmcli.ArtifactSetMainManifest(index)
+	if err != nil {
+		return err
+	}
+
+	digestRef, err := targetRef.withDigest(main.Digest, signedDigest)
+	if err != nil {
+		return err
+	}
+	if err = m.pushManifest(ctx, dir, digestRef, main); err != nil {
+		return err
+	}
+
+	raw, err := os.ReadFile(ocmcli.ArtifactSetBlobPath(dir, main.Digest))
+	if err != nil {
+		return fmt.Errorf("failed to read manifest %s: %w", main.Digest, err)
+	}
+	return m.tagArtifact(ctx, targetRef, &rawManifest{raw: raw, mediaType: main.MediaType})
+}
+
+// pushManifest pushes the manifest with the given descriptor and everything it references from the artifact set to ref.
+func (m *Mirror) pushManifest(ctx context.Context, dir string, ref name.Reference, desc v1.Descriptor) error {
+	raw, err := os.ReadFile(ocmcli.ArtifactSetBlobPath(dir, desc.Digest))
+	if err != nil {
+		return fmt.Errorf("failed to read manifest %s: %w", desc.Digest, err)
+	}
+
+	repo := ref.Context()
+
+	if desc.MediaType.IsIndex() {
+		index, err := v1.ParseIndexManifest(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Errorf("failed to parse index %s: %w", desc.Digest, err)
+		}
+		for _, child := range index.Manifests {
+			if err = m.pushManifest(ctx, dir, repo.Digest(child.Digest.String()), child); err != nil {
+				return err
+			}
+		}
+	} else {
+		manifest, err := v1.ParseManifest(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+		}
+		for _, blobDesc := range append([]v1.Descriptor{manifest.Config}, manifest.Layers...) {
+			layer := &artifactSetBlob{path: ocmcli.ArtifactSetBlobPath(dir, blobDesc.Digest), desc: blobDesc}
+			if err = remote.WriteLayer(repo, layer, m.remoteOptions(ctx)...); err != nil {
+				return fmt.Errorf("failed to push blob %s to %s: %w", blobDesc.Digest, repo.String(), err)
+			}
+		}
+	}
+
+	if err = remote.Put(ref, &rawManifest{raw: raw, mediaType: desc.MediaType}, m.remoteOptions(ctx)...); err != nil {
+		return fmt.Errorf("failed to push manifest %s to %s: %w", desc.Digest, ref.String(), err)
+	}
+	return nil
+}
+
+func (m *Mirror) remoteOptions(ctx context.Context) []remote.Option {
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(m.keychain),
+	}
+}
+
+// imageReference is a parsed image reference, which contains a tag, a digest or both.
+type imageReference struct {
+	tag    *name.Tag
+	digest *name.Digest
+}
+
+// parseImageReference parses an image reference, e.g. "registry.internal:5000/app:v1.0.0@sha256:<hex>".
+// An image reference without tag and digest refers to the tag "latest".
+func parseImageReference(image string, options ...name.Option) (*imageReference, error) {
+	imageName, tag, digest := splitImageReference(image)
+
+	ref := &imageReference{}
+	if digest != "" {
+		digestRef, err := name.NewDigest(imageName+"@"+digest, options...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image reference %s: %w", image, err)
+		}
+		ref.digest = &digestRef
+	}
+	if tag != "" || digest == "" {
+		tagRef, err := name.NewTag(formatImage(imageName, tag, ""), options...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image reference %s: %w", image, err)
+		}
+		ref.tag = &tagRef
+	}
+	return ref, nil
+}
+
+// reference returns the digest reference of the image, if it is pinned by digest, or else its tag reference.
+func (r *imageReference) reference() name.Reference {
+	if r.digest != nil {
+		return *r.digest
+	}
+	return *r.tag
+}
+
+// withDigest returns the reference of the artifact with the digest in the repository of the image.
+// It fails if the image is pinned to a different digest, or if the digest differs from the signed digest of the resource, if any.
+func (r *imageReference) withDigest(digest v1.Hash, signedDigest *v1.Hash) (name.Digest, error) {
+	if r.digest != nil && r.digest.DigestStr() != digest.String() {
+		return name.Digest{}, fmt.Errorf("artifact with digest %s does not match the digest of %s", digest, r.digest.String())
+	}
+	if signedDigest != nil && *signedDigest != digest {
+		return name.Digest{}, fmt.Errorf("artifact with digest %s does not match the signed digest %s of the resource", digest, signedDigest)
+	}
+	return r.reference().Context().Digest(digest.String()), nil
+}
+
+// resourceDigest returns the manifest digest of an OCI artifact resource covered by the signature of its component version, or nil.
+func resourceDigest(resource *ocmcli.Resource) *v1.Hash {
+	if resource.Digest == nil || resource.Digest.NormalisationAlgorithm != ocmcli.OCIArtifactDigestV1 || resource.Digest.HashAlgorithm != ocmcli.HashAlgorithmSHA256 {
+		return nil
+	}
+	return &v1.Hash{Algorithm: "sha256", Hex: resource.Digest.Value}
+}
+
+// artifactSetBlob is a blob of an extracted artifact set, which is uploaded unmodified.
+type artifactSetBlob struct {
+	path string
+	desc v1.Descriptor
+}
+
+var _ v1.Layer = (*artifactSetBlob)(nil)
+
+func (b *artifactSetBlob) Digest() (v1.Hash, error) {
+	return b.desc.Digest, nil
+}
+
+func (b *artifactSetBlob) DiffID() (v1.Hash, error) {
+	return b.desc.Digest, nil
+}
+
+func (b *artifactSetBlob) Compressed() (io.ReadCloser, error) {
+	return os.Open(b.path)
+}
+
+func (b *artifactSetBlob) Uncompressed() (io.ReadCloser, error) {
+	return os.Open(b.path)
+}
+
+func (b *artifactSetBlob) Size() (int64, error) {
+	return b.desc.Size, nil
+}
+
+func (b *artifactSetBlob) MediaType() (types.MediaType, error) {
+	return b.desc.MediaType, nil
+}
+
+// rawManifest is a manifest that is pushed unmodified, preserving its digest.
+type rawManifest struct {
+	raw       []byte
+	mediaType types.MediaType
+}
+
+func (r *rawManifest) RawManifest() ([]byte, error) {
+	return r.raw, nil
+}
+
+func (r *rawManifest) MediaType() (types.MediaType, error) {
+	return r.mediaType, nil
+}
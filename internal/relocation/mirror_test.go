@@ -0,0 +1,222 @@
+package relocation_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
+	testutil "github.com/openmcp-project/bootstrapper/test/utils"
+)
+
+const mirrorTestComponent = "github.com/openmcp-project/bootstrapper/test-mirror"
+
+func TestMirrorImages(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	// an image that is referenced from its registry
+	remoteImage, err := random.Image(256, 2)
+	assert.NoError(t, err)
+	remoteRef, err := name.ParseReference(host + "/origin/remote-app:v1.0.0")
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(remoteRef, remoteImage))
+
+	// an image pinned by digest, whose tag has been moved to another image afterwards
+	pinnedImage, err := random.Image(256, 2)
+	assert.NoError(t, err)
+	pinnedDigest, err := pinnedImage.Digest()
+	assert.NoError(t, err)
+	pinnedRef, err := name.ParseReference(host + "/origin/pinned-app:v1.0.0")
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(pinnedRef, pinnedImage))
+	movedImage, err := random.Image(256, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(pinnedRef, movedImage))
+
+	// an image that is stored as local blob in the CTF
+	localImage, err := random.Image(256, 2)
+	assert.NoError(t, err)
+
+	workDir := t.TempDir()
+	testutil.WriteArtifactSet(t, filepath.Join(workDir, "local-app"), localImage)
+	constructorPath := filepath.Join(workDir, "component-constructor.yaml")
+	constructor := fmt.Sprintf(`components:
+  - name: %s
+    version: v0.0.1
+    provider:
+      name: openmcp-project
+    resources:
+      - name: remote-app
+        type: ociImage
+        version: v1.0.0
+        access:
+          type: ociArtifact
+          imageReference: %s
+      - name: pinned-app
+        type: ociImage
+        version: v1.0.0
+        access:
+          type: ociArtifact
+          imageReference: %s@%s
+      - name: local-app
+        type: ociImage
+        version: v2.0.0
+        input:
+          type: dir
+          path: local-app
+          mediaType: application/vnd.oci.image.manifest.v1+tar
+          referenceName: ghcr.io/example/local-app
+      - name: templates
+        type: blob
+        input:
+          type: file
+          path: component-constructor.yaml
+`, mirrorTestComponent, remoteRef.String(), pinnedRef.String(), pinnedDigest)
+	assert.NoError(t, os.WriteFile(constructorPath, []byte(constructor), 0o644))
+	ctf := testutil.BuildComponentCTF(constructorPath, t)
+
+	compGetter := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.1", ctf, mirrorTestComponent), "templates", ocmcli.NoOcmConfig)
+	if !assert.NoError(t, compGetter.InitializeComponents(t.Context())) {
+		return
+	}
+
+	relocator := relocation.NewRelocator(&config.BootstrapperConfig{AirGap: config.AirGap{MirrorRegistry: host + "/mirror"}})
+	mirror, err := relocation.NewMirror(compGetter, relocator, true)
+	assert.NoError(t, err)
+
+	mirrored, err := mirror.MirrorImages(t.Context(), compGetter.Graph())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []relocation.MirroredImage{
+		{
+			Component: mirrorTestComponent + ":v0.0.1",
+			Resource:  "remote-app",
+			Source:    host + "/origin/remote-app:v1.0.0",
+			Target:    host + "/mirror/origin/remote-app:v1.0.0",
+		},
+		{
+			Component: mirrorTestComponent + ":v0.0.1",
+			Resource:  "pinned-app",
+			Source:    host + "/origin/pinned-app:v1.0.0@" + pinnedDigest.String(),
+			Target:    host + "/mirror/origin/pinned-app:v1.0.0@" + pinnedDigest.String(),
+		},
+		{
+			Component: mirrorTestComponent + ":v0.0.1",
+			Resource:  "local-app",
+			Source:    "ghcr.io/example/local-app:v2.0.0",
+			Target:    host + "/mirror/example/local-app:v2.0.0",
+		},
+	}, mirrored)
+
+	assertImageDigest(t, host+"/mirror/origin/remote-app:v1.0.0", remoteImage)
+	assertImageDigest(t, host+"/mirror/example/local-app:v2.0.0", localImage)
+	// the pinned image is mirrored, not the image the tag points to
+	assertImageDigest(t, host+"/mirror/origin/pinned-app:v1.0.0", pinnedImage)
+}
+
+func TestMirrorImagesWithVerification(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	signedImage, err := random.Image(256, 2)
+	assert.NoError(t, err)
+	ref, err := name.ParseReference(host + "/origin/app:v1.0.0")
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(ref, signedImage))
+	localImage, err := random.Image(256, 2)
+	assert.NoError(t, err)
+
+	workDir := t.TempDir()
+	testutil.WriteArtifactSet(t, filepath.Join(workDir, "local-app"), localImage)
+	constructorPath := filepath.Join(workDir, "component-constructor.yaml")
+	constructor := fmt.Sprintf(`components:
+  - name: %s
+    version: v0.0.1
+    provider:
+      name: openmcp-project
+    resources:
+      - name: app
+        type: ociImage
+        version: v1.0.0
+        access:
+          type: ociArtifact
+          imageReference: %s
+      - name: local-app
+        type: ociImage
+        version: v2.0.0
+        input:
+          type: dir
+          path: local-app
+          mediaType: application/vnd.oci.image.manifest.v1+tar
+          referenceName: ghcr.io/example/local-app
+`, mirrorTestComponent, ref.String())
+	assert.NoError(t, os.WriteFile(constructorPath, []byte(constructor), 0o644))
+	ctf := testutil.BuildComponentCTF(constructorPath, t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	testutil.SignComponentVersion(t, ctf, mirrorTestComponent, "v0.0.1", "openmcp", key)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	publicKeyPath := filepath.Join(workDir, "public-key.pem")
+	assert.NoError(t, os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), 0o644))
+
+	compGetter := ocmcli.NewComponentGetter(fmt.Sprintf("%s//%s:v0.0.1", ctf, mirrorTestComponent), "", ocmcli.NoOcmConfig)
+	compGetter.SetVerification(ocmcli.VerifyOptions{Signatures: []ocmcli.SignatureVerification{{Name: "openmcp", PublicKeyPath: publicKeyPath}}})
+	if !assert.NoError(t, compGetter.InitializeComponents(t.Context())) {
+		return
+	}
+
+	// the tag is moved to another image after the verification
+	movedImage, err := random.Image(256, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, remote.Write(ref, movedImage))
+
+	relocator := relocation.NewRelocator(&config.BootstrapperConfig{AirGap: config.AirGap{MirrorRegistry: host + "/mirror"}})
+	mirror, err := relocation.NewMirror(compGetter, relocator, true)
+	assert.NoError(t, err)
+
+	_, err = mirror.MirrorImages(t.Context(), compGetter.Graph())
+	assert.NoError(t, err)
+
+	// the signed image is mirrored, not the image the tag points to
+	assertImageDigest(t, host+"/mirror/origin/app:v1.0.0", signedImage)
+	assertImageDigest(t, host+"/mirror/example/local-app:v2.0.0", localImage)
+}
+
+func TestNewMirrorWithoutMirrorRegistry(t *testing.T) {
+	_, err := relocation.NewMirror(ocmcli.NewComponentGetter("", "", ocmcli.NoOcmConfig), relocation.NewRelocator(&config.BootstrapperConfig{}), false)
+	assert.Error(t, err)
+}
+
+func assertImageDigest(t *testing.T, image string, expected v1.Image) {
+	t.Helper()
+
+	ref, err := name.ParseReference(image)
+	assert.NoError(t, err)
+	desc, err := remote.Get(ref)
+	if !assert.NoError(t, err) {
+		return
+	}
+	expectedDigest, err := expected.Digest()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedDigest, desc.Digest)
+}
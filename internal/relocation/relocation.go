@@ -0,0 +1,146 @@
+package relocation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// Relocator maps the image references of component resources to the locations from which the cluster pulls them.
+// A nil Relocator or a Relocator without rules and mirror registry returns the original image references.
+type Relocator struct {
+	// rules are the prefix rewrite rules, which take precedence over the mirror registry.
+	rules []config.ImageRelocationRule
+	// mirrorRegistry is the registry host with an optional path prefix, into which images are mirrored in air-gapped mode.
+	mirrorRegistry string
+}
+
+// NewRelocator creates a Relocator for the image relocation rules and the air-gapped configuration of the bootstrapper configuration.
+func NewRelocator(c *config.BootstrapperConfig) *Relocator {
+	rules := make([]config.ImageRelocationRule, 0, len(c.ImageRelocation.Rules))
+	for _, rule := range c.ImageRelocation.Rules {
+		rules = append(rules, config.ImageRelocationRule{
+			From: strings.TrimSuffix(rule.From, "/"),
+			To:   strings.TrimSuffix(rule.To, "/"),
+		})
+	}
+
+	return &Relocator{
+		rules:          rules,
+		mirrorRegistry: strings.TrimSuffix(c.AirGap.MirrorRegistry, "/"),
+	}
+}
+
+// Enabled returns true if image references are relocated, either by rewrite rules or into a mirror registry.
+func (r *Relocator) Enabled() bool {
+	return r != nil && (len(r.rules) > 0 || r.mirrorRegistry != "")
+}
+
+// MirrorRegistry returns the registry into which images are mirrored.
+func (r *Relocator) MirrorRegistry() string {
+	if r == nil {
+		return ""
+	}
+	return r.mirrorRegistry
+}
+
+// RelocateImage returns the relocated reference of the given image reference.
+// If a rewrite rule matches, its prefix is replaced, e.g. the rule "ghcr.io/openmcp-project -> registry.internal/openmcp"
+// turns "ghcr.io/openmcp-project/openmcp-operator:v0.1.0" into "registry.internal/openmcp/openmcp-operator:v0.1.0".
+// Otherwise, the registry of the image is replaced by the mirror registry, the repository path, tag and digest are kept, e.g.
+// "ghcr.io/fluxcd/source-controller:v1.6.0" becomes "<mirror registry>/fluxcd/source-controller:v1.6.0".
+// Images that already point to the mirror registry are returned unchanged.
+func (r *Relocator) RelocateImage(image string) (string, error) {
+	if !r.Enabled() {
+		return image, nil
+	}
+
+	for _, rule := range r.rules {
+		if hasPrefix(image, rule.From) {
+			relocated := rule.To + image[len(rule.From):]
+			if _, err := name.ParseReference(relocated); err != nil {
+				return "", fmt.Errorf("invalid image reference %s after applying relocation rule %s -> %s: %w", relocated, rule.From, rule.To, err)
+			}
+			return relocated, nil
+		}
+	}
+
+	if r.mirrorRegistry == "" || hasPrefix(image, r.mirrorRegistry) {
+		return image, nil
+	}
+
+	imageName, tag, digest := splitImageReference(image)
+	repo, err := name.NewRepository(imageName)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %s: %w", image, err)
+	}
+
+	return formatImage(r.mirrorRegistry+"/"+repo.RepositoryStr(), tag, digest), nil
+}
+
+// ResourceImage returns the relocated image reference of an OCI artifact resource.
+func (r *Relocator) ResourceImage(resource *ocmcli.Resource) (string, error) {
+	image, err := OriginalImageReference(resource)
+	if err != nil {
+		return "", err
+	}
+
+	relocated, err := r.RelocateImage(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to relocate image of resource %s: %w", resource.Name, err)
+	}
+	return relocated, nil
+}
+
+// OriginalImageReference returns the image reference of an OCI artifact resource as specified by its component version.
+// For OCI artifacts stored as local blob, the reference name hint is used. If it has no tag, the resource version is used as tag.
+func OriginalImageReference(resource *ocmcli.Resource) (string, error) {
+	if resource.Access.ImageReference != nil && *resource.Access.ImageReference != "" {
+		return *resource.Access.ImageReference, nil
+	}
+
+	if resource.Access.ReferenceName != nil && *resource.Access.ReferenceName != "" {
+		imageName, tag, digest := splitImageReference(*resource.Access.ReferenceName)
+		if tag == "" && resource.Version != "" {
+			tag = resource.Version
+		}
+		return formatImage(imageName, tag, digest), nil
+	}
+
+	return "", fmt.Errorf("resource %s with access type %s has no image reference", resource.Name, resource.Access.Type)
+}
+
+// hasPrefix returns true if the image reference starts with prefix, followed by a path, tag or digest separator or the end of the reference.
+func hasPrefix(image, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(image, prefix) {
+		return false
+	}
+	rest := image[len(prefix):]
+	return rest == "" || strings.ContainsAny(rest[:1], "/:@")
+}
+
+// splitImageReference splits an image reference into the image name, the tag and the digest, which may be empty.
+// A colon is only a tag separator in the last path segment, so that the port of a registry host is kept,
+// e.g. "localhost:5000/nginx" has no tag, while "localhost:5000/nginx:1.29" has the tag "1.29".
+func splitImageReference(image string) (imageName, tag, digest string) {
+	imageName, digest, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(imageName, ":"); i > strings.LastIndex(imageName, "/") {
+		imageName, tag = imageName[:i], imageName[i+1:]
+	}
+	return imageName, tag, digest
+}
+
+func formatImage(imageName, tag, digest string) string {
+	image := imageName
+	if tag != "" {
+		image += ":" + tag
+	}
+	if digest != "" {
+		image += "@" + digest
+	}
+	return image
+}
@@ -0,0 +1,195 @@
+package relocation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
+)
+
+func TestRelocateImage(t *testing.T) {
+	testRules := []config.ImageRelocationRule{
+		{From: "ghcr.io/openmcp-project/images/", To: "registry.internal/openmcp-images/"},
+		{From: "ghcr.io/openmcp-project", To: "registry.internal/openmcp"},
+		{From: "docker.io/library/nginx", To: "registry.internal/nginx"},
+	}
+
+	testCases := []struct {
+		desc           string
+		rules          []config.ImageRelocationRule
+		mirrorRegistry string
+		image          string
+		expected       string
+		expectError    bool
+	}{
+		{
+			desc:     "should keep the image without mirror registry",
+			image:    "ghcr.io/fluxcd/source-controller:v1.6.0",
+			expected: "ghcr.io/fluxcd/source-controller:v1.6.0",
+		},
+		{
+			desc:           "should replace the registry",
+			mirrorRegistry: "registry.internal:5000",
+			image:          "ghcr.io/fluxcd/source-controller:v1.6.0",
+			expected:       "registry.internal:5000/fluxcd/source-controller:v1.6.0",
+		},
+		{
+			desc:           "should prepend the path of the mirror registry",
+			mirrorRegistry: "registry.internal:5000/openmcp/",
+			image:          "ghcr.io/fluxcd/source-controller:v1.6.0@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expected:       "registry.internal:5000/openmcp/fluxcd/source-controller:v1.6.0@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+		{
+			desc:           "should expand docker hub images",
+			mirrorRegistry: "registry.internal",
+			image:          "nginx:1.29",
+			expected:       "registry.internal/library/nginx:1.29",
+		},
+		{
+			desc:           "should keep the port of a registry without tag",
+			mirrorRegistry: "registry.internal",
+			image:          "registry.example:5000/fluxcd/source-controller@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expected:       "registry.internal/fluxcd/source-controller@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+		{
+			desc:           "should keep images in the mirror registry",
+			mirrorRegistry: "registry.internal:5000/openmcp",
+			image:          "registry.internal:5000/openmcp/fluxcd/source-controller:v1.6.0",
+			expected:       "registry.internal:5000/openmcp/fluxcd/source-controller:v1.6.0",
+		},
+		{
+			desc:           "should fail for an invalid image",
+			mirrorRegistry: "registry.internal",
+			image:          "Invalid Image",
+			expectError:    true,
+		},
+		{
+			desc:     "should apply the first matching rule",
+			rules:    testRules,
+			image:    "ghcr.io/openmcp-project/images/openmcp-operator:v0.1.0",
+			expected: "registry.internal/openmcp-images/openmcp-operator:v0.1.0",
+		},
+		{
+			desc:     "should apply a rule matching the image name",
+			rules:    testRules,
+			image:    "docker.io/library/nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expected: "registry.internal/nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+		{
+			desc:     "should not match a prefix within a path segment",
+			rules:    testRules,
+			image:    "ghcr.io/openmcp-project-fork/openmcp-operator:v0.1.0",
+			expected: "ghcr.io/openmcp-project-fork/openmcp-operator:v0.1.0",
+		},
+		{
+			desc:           "should prefer rules over the mirror registry",
+			rules:          testRules,
+			mirrorRegistry: "mirror.internal",
+			image:          "ghcr.io/openmcp-project/openmcp-operator:v0.1.0",
+			expected:       "registry.internal/openmcp/openmcp-operator:v0.1.0",
+		},
+		{
+			desc:           "should use the mirror registry if no rule matches",
+			rules:          testRules,
+			mirrorRegistry: "mirror.internal",
+			image:          "ghcr.io/fluxcd/source-controller:v1.6.0",
+			expected:       "mirror.internal/fluxcd/source-controller:v1.6.0",
+		},
+		{
+			desc:        "should fail for an invalid image after applying a rule",
+			rules:       []config.ImageRelocationRule{{From: "ghcr.io/openmcp-project", To: "registry.internal/openmcp:v1"}},
+			image:       "ghcr.io/openmcp-project/openmcp-operator:v0.1.0@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r := relocation.NewRelocator(&config.BootstrapperConfig{
+				AirGap:          config.AirGap{MirrorRegistry: tc.mirrorRegistry},
+				ImageRelocation: config.ImageRelocation{Rules: tc.rules},
+			})
+			assert.Equal(t, tc.mirrorRegistry != "" || len(tc.rules) > 0, r.Enabled())
+
+			image, err := r.RelocateImage(tc.image)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, image)
+		})
+	}
+}
+
+func TestNilRelocator(t *testing.T) {
+	var r *relocation.Relocator
+	assert.False(t, r.Enabled())
+	assert.Empty(t, r.MirrorRegistry())
+
+	image, err := r.RelocateImage("ghcr.io/fluxcd/source-controller:v1.6.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghcr.io/fluxcd/source-controller:v1.6.0", image)
+}
+
+func TestOriginalImageReference(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	testCases := []struct {
+		desc        string
+		resource    ocmcli.Resource
+		expected    string
+		expectError bool
+	}{
+		{
+			desc: "should use the image reference",
+			resource: ocmcli.Resource{
+				Name:    "image",
+				Version: "v1.0.0",
+				Access:  ocmcli.Access{Type: ocmcli.OCIArtifactAccessType, ImageReference: ptr("ghcr.io/example/image:v0.9.0")},
+			},
+			expected: "ghcr.io/example/image:v0.9.0",
+		},
+		{
+			desc: "should use the reference name with the resource version",
+			resource: ocmcli.Resource{
+				Name:    "image",
+				Version: "v1.0.0",
+				Access:  ocmcli.Access{Type: ocmcli.LocalBlobAccessType, ReferenceName: ptr("ghcr.io/example/image")},
+			},
+			expected: "ghcr.io/example/image:v1.0.0",
+		},
+		{
+			desc: "should keep the tag of the reference name",
+			resource: ocmcli.Resource{
+				Name:    "image",
+				Version: "v1.0.0",
+				Access:  ocmcli.Access{Type: ocmcli.LocalBlobAccessType, ReferenceName: ptr("localhost:5000/example/image:v0.9.0")},
+			},
+			expected: "localhost:5000/example/image:v0.9.0",
+		},
+		{
+			desc: "should fail without image reference",
+			resource: ocmcli.Resource{
+				Name:   "image",
+				Access: ocmcli.Access{Type: ocmcli.LocalBlobAccessType},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			image, err := relocation.OriginalImageReference(&tc.resource)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, image)
+		})
+	}
+}
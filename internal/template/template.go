@@ -12,6 +12,7 @@ import (
 	"github.com/openmcp-project/bootstrapper/internal/log"
 
 	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/util"
 )
 
@@ -184,6 +185,16 @@ func parseImageReference(imageRef string) map[string]interface{} {
 	}
 }
 
+func parseRelocatedImageReference(relocator *relocation.Relocator, imageRef string) map[string]interface{} {
+	relocated, err := relocator.RelocateImage(imageRef)
+	if err != nil {
+		log.GetLogger().Errorf("Template_Func: parseImage error relocating image %s: %v", imageRef, err)
+		return nil
+	}
+
+	return parseImageReference(relocated)
+}
+
 // TemplateExecution is a struct that provides methods to execute templates with input data.
 type TemplateExecution struct {
 	funcMaps               []gotmpl.FuncMap
@@ -239,6 +250,19 @@ func (t *TemplateExecution) WithMissingKeyOption(option string) *TemplateExecuti
 	return t
 }
 
+// WithImageRelocator relocates the images parsed by the parseImage template function with the given relocator.
+func (t *TemplateExecution) WithImageRelocator(relocator *relocation.Relocator) *TemplateExecution {
+	if relocator.Enabled() {
+		t.funcMaps = append(t.funcMaps, gotmpl.FuncMap{
+			// parseImage relocates the container image string and returns a map with the keys "name", "tag", and "digest".
+			"parseImage": func(imageRef string) map[string]interface{} {
+				return parseRelocatedImageReference(relocator, imageRef)
+			},
+		})
+	}
+	return t
+}
+
 func (t *TemplateExecution) WithOCMComponentGetter(ctx context.Context, compGetter *ocmcli.ComponentGetter) *TemplateExecution {
 	if compGetter != nil {
 		t.funcMaps = append(t.funcMaps, gotmpl.FuncMap{
@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/openmcp-project/bootstrapper/internal/config"
+	"github.com/openmcp-project/bootstrapper/internal/relocation"
 	"github.com/openmcp-project/bootstrapper/internal/template"
 )
 
@@ -78,3 +80,42 @@ func TestTemplateExecution(t *testing.T) {
 		})
 	}
 }
+
+func TestParseImageWithImageRelocator(t *testing.T) {
+	relocator := relocation.NewRelocator(&config.BootstrapperConfig{
+		ImageRelocation: config.ImageRelocation{
+			Rules: []config.ImageRelocationRule{
+				{From: "ghcr.io/openmcp-project", To: "registry.internal/openmcp"},
+			},
+		},
+	})
+
+	testCases := []struct {
+		desc      string
+		relocator *relocation.Relocator
+		expected  string
+	}{
+		{
+			desc:     "should parse the original image without relocator",
+			expected: "ghcr.io/openmcp-project/openmcp-operator v0.1.0",
+		},
+		{
+			desc:      "should parse the relocated image",
+			relocator: relocator,
+			expected:  "registry.internal/openmcp/openmcp-operator v0.1.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tmplExec := template.NewTemplateExecution().WithImageRelocator(tc.relocator)
+			result, err := tmplExec.Execute(testInputKey, `{{ $image := parseImage .values.test }}{{ $image.image }} {{ $image.tag }}`, map[string]interface{}{
+				"values": map[string]interface{}{
+					testInputKey: "ghcr.io/openmcp-project/openmcp-operator:v0.1.0",
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+}
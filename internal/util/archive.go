@@ -0,0 +1,117 @@
+package util
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ExtractTarArchive extracts a tar archive read from r into the directory dst.
+// Gzip compressed archives are detected automatically.
+// Entries that would be written outside of dst are rejected.
+func ExtractTarArchive(r io.Reader, dst string) error {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read archive header: %w", err)
+	}
+
+	var archive io.Reader = buffered
+	if bytes.Equal(magic, gzipMagic) {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer func() {
+			_ = gzipReader.Close()
+		}()
+		archive = gzipReader
+	}
+
+	if err = os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory %s: %w", dst, err)
+	}
+
+	tarReader := tar.NewReader(archive)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		target, err := archiveEntryPath(dst, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err = writeArchiveFile(tarReader, target, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) {
+				return fmt.Errorf("archive entry %s links to absolute path %s", header.Name, header.Linkname)
+			}
+			if _, err = archiveEntryPath(dst, filepath.Join(filepath.Dir(header.Name), header.Linkname)); err != nil {
+				return err
+			}
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", target, err)
+			}
+			if err = os.Symlink(header.Linkname, target); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", target, err)
+			}
+		default:
+			// other entry types (hard links, devices, ...) are not part of directory trees
+			continue
+		}
+	}
+}
+
+// archiveEntryPath returns the path of the archive entry name inside dst.
+// It returns an error if the entry would be located outside of dst.
+func archiveEntryPath(dst, name string) (string, error) {
+	target := filepath.Join(dst, name)
+	rel, err := filepath.Rel(dst, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %s points outside of the target directory", name)
+	}
+	return target, nil
+}
+
+func writeArchiveFile(r io.Reader, target string, mode os.FileMode) (err error) {
+	if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", target, err)
+	}
+
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode|0200)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", target, err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		err = errors.Join(err, closeErr)
+	}()
+
+	if _, err = io.Copy(file, r); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testArchiveEntry struct {
+	name     string
+	content  string
+	typeflag byte
+}
+
+func buildTestArchive(t *testing.T, entries []testArchiveEntry, compress bool) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Typeflag: e.typeflag, Size: int64(len(e.content))}
+		if e.typeflag == tar.TypeDir {
+			hdr.Mode = 0o755
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatalf("failed to write tar content: %v", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+
+	if !compress {
+		return buf.Bytes()
+	}
+
+	var gzBuf bytes.Buffer
+	gw := gzip.NewWriter(&gzBuf)
+	if _, err := gw.Write(buf.Bytes()); err != nil {
+		t.Fatalf("failed to write gzip content: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	return gzBuf.Bytes()
+}
+
+func TestExtractTarArchive(t *testing.T) {
+	tests := []struct {
+		name          string
+		entries       []testArchiveEntry
+		compress      bool
+		expectedFiles map[string]string
+		expectError   bool
+	}{
+		{
+			name: "plain tar archive",
+			entries: []testArchiveEntry{
+				{name: "dir", typeflag: tar.TypeDir},
+				{name: "dir/file.yaml", content: "key: value", typeflag: tar.TypeReg},
+				{name: "root.yaml", content: "root", typeflag: tar.TypeReg},
+			},
+			expectedFiles: map[string]string{
+				"dir/file.yaml": "key: value",
+				"root.yaml":     "root",
+			},
+		},
+		{
+			name: "gzip compressed tar archive",
+			entries: []testArchiveEntry{
+				{name: "nested/dir/file.yaml", content: "nested", typeflag: tar.TypeReg},
+			},
+			compress: true,
+			expectedFiles: map[string]string{
+				"nested/dir/file.yaml": "nested",
+			},
+		},
+		{
+			name: "entry outside of the destination",
+			entries: []testArchiveEntry{
+				{name: "../escape.yaml", content: "escape", typeflag: tar.TypeReg},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := t.TempDir()
+			archive := buildTestArchive(t, tt.entries, tt.compress)
+
+			err := ExtractTarArchive(bytes.NewReader(archive), dst)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for name, expected := range tt.expectedFiles {
+				actual, err := os.ReadFile(filepath.Join(dst, name))
+				if err != nil {
+					t.Fatalf("failed to read extracted file %s: %v", name, err)
+				}
+				if string(actual) != expected {
+					t.Errorf("expected content %q for file %s, got %q", expected, name, string(actual))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewHTTPClient returns an HTTP client with the given timeout, which trusts the PEM encoded caBundle in addition to the system CAs.
+func NewHTTPClient(caBundle []byte, timeout time.Duration) (*http.Client, error) {
+	client := &http.Client{Timeout: timeout}
+	if len(caBundle) == 0 {
+		return client, nil
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("failed to parse TLS CA certificate")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+	client.Transport = transport
+	return client, nil
+}
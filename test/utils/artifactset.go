@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// WriteArtifactSet writes the image in OCM artifact set format to dir, with the image as main artifact.
+func WriteArtifactSet(t *testing.T, dir string, image v1.Image) {
+	t.Helper()
+
+	blobsDir := filepath.Join(dir, "blobs")
+	if err := os.MkdirAll(blobsDir, 0o755); err != nil {
+		t.Fatalf("failed to create blobs directory: %v", err)
+	}
+
+	writeBlob := func(digest v1.Hash, data []byte) {
+		if err := os.WriteFile(filepath.Join(blobsDir, digest.Algorithm+"."+digest.Hex), data, 0o644); err != nil {
+			t.Fatalf("failed to write blob %s: %v", digest, err)
+		}
+	}
+
+	configData, err := image.RawConfigFile()
+	if err != nil {
+		t.Fatalf("failed to get image config: %v", err)
+	}
+	configDigest, err := image.ConfigName()
+	if err != nil {
+		t.Fatalf("failed to get image config digest: %v", err)
+	}
+	writeBlob(configDigest, configData)
+
+	layers, err := image.Layers()
+	if err != nil {
+		t.Fatalf("failed to get image layers: %v", err)
+	}
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			t.Fatalf("failed to get layer digest: %v", err)
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			t.Fatalf("failed to read layer %s: %v", digest, err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read layer %s: %v", digest, err)
+		}
+		writeBlob(digest, data)
+	}
+
+	manifestData, err := image.RawManifest()
+	if err != nil {
+		t.Fatalf("failed to get image manifest: %v", err)
+	}
+	manifestDigest, err := image.Digest()
+	if err != nil {
+		t.Fatalf("failed to get image digest: %v", err)
+	}
+	mediaType, err := image.MediaType()
+	if err != nil {
+		t.Fatalf("failed to get image media type: %v", err)
+	}
+	writeBlob(manifestDigest, manifestData)
+
+	index, err := json.Marshal(v1.IndexManifest{
+		SchemaVersion: 2,
+		Manifests: []v1.Descriptor{
+			{
+				MediaType: mediaType,
+				Digest:    manifestDigest,
+				Size:      int64(len(manifestData)),
+			},
+		},
+		Annotations: map[string]string{ocmcli.ArtifactSetMainAnnotation: manifestDigest.String()},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal artifact set index: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "index.json"), index, 0o644); err != nil {
+		t.Fatalf("failed to write artifact set index: %v", err)
+	}
+}
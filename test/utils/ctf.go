@@ -0,0 +1,267 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// componentConstructor is the subset of the OCM component constructor format supported by BuildComponentCTF.
+type componentConstructor struct {
+	Components []struct {
+		Name     string `json:"name"`
+		Version  string `json:"version"`
+		Provider struct {
+			Name string `json:"name"`
+		} `json:"provider"`
+		ComponentReferences []map[string]any `json:"componentReferences"`
+		Resources           []struct {
+			Name    string         `json:"name"`
+			Type    string         `json:"type"`
+			Version string         `json:"version"`
+			Access  map[string]any `json:"access"`
+			Input   *struct {
+				Type          string `json:"type"`
+				Path          string `json:"path"`
+				MediaType     string `json:"mediaType"`
+				ReferenceName string `json:"referenceName"`
+			} `json:"input"`
+		} `json:"resources"`
+	} `json:"components"`
+}
+
+// BuildComponentCTF builds the components for the specified componentConstructorLocation without the OCM cli
+// and returns the ctf out directory.
+// Resource inputs of type "file" and "dir" are stored as local blobs, resources with an access are stored as external resources.
+// The referenceName of an input is set on the local blob access, e.g. for OCI artifacts in OCM artifact set format.
+func BuildComponentCTF(componentConstructorLocation string, t *testing.T) string {
+	t.Helper()
+
+	ctfDir := filepath.Join(t.TempDir(), "ctf")
+	AddComponentsToCTF(componentConstructorLocation, ctfDir, t)
+	return ctfDir
+}
+
+// AddComponentsToCTF adds the components for the specified componentConstructorLocation to the ctf directory ctfDir.
+// The ctf directory is created if it does not exist.
+func AddComponentsToCTF(componentConstructorLocation, ctfDir string, t *testing.T) {
+	t.Helper()
+
+	data, err := os.ReadFile(componentConstructorLocation)
+	if err != nil {
+		t.Fatalf("failed to read component constructor: %v", err)
+	}
+
+	var constructor componentConstructor
+	if err = yaml.Unmarshal(data, &constructor); err != nil {
+		t.Fatalf("failed to unmarshal component constructor: %v", err)
+	}
+
+	if err = os.MkdirAll(filepath.Join(ctfDir, ocmcli.CTFBlobsDirectoryName), 0o755); err != nil {
+		t.Fatalf("failed to create ctf directory: %v", err)
+	}
+
+	index := ocmcli.CTFArtifactIndex{SchemaVersion: 1}
+	if indexData, err := os.ReadFile(filepath.Join(ctfDir, ocmcli.CTFArtifactIndexFileName)); err == nil {
+		if err = json.Unmarshal(indexData, &index); err != nil {
+			t.Fatalf("failed to unmarshal ctf artifact index: %v", err)
+		}
+	}
+
+	baseDir := filepath.Dir(componentConstructorLocation)
+
+	for _, component := range constructor.Components {
+		var layers []map[string]any
+
+		resources := make([]map[string]any, 0, len(component.Resources))
+		for _, res := range component.Resources {
+			version := res.Version
+			if version == "" {
+				version = component.Version
+			}
+
+			resource := map[string]any{
+				"name":     res.Name,
+				"type":     res.Type,
+				"version":  version,
+				"relation": "external",
+				"access":   res.Access,
+			}
+
+			if res.Input != nil {
+				var (
+					blob      []byte
+					mediaType = res.Input.MediaType
+				)
+				inputPath := filepath.Join(baseDir, res.Input.Path)
+				switch res.Input.Type {
+				case "file":
+					blob, err = os.ReadFile(inputPath)
+					if err != nil {
+						t.Fatalf("failed to read input file %s: %v", inputPath, err)
+					}
+					if mediaType == "" {
+						mediaType = "application/octet-stream"
+					}
+				case "dir":
+					blob = tarDirectory(t, inputPath)
+					if mediaType == "" {
+						mediaType = "application/x-tar"
+					}
+				default:
+					t.Fatalf("unsupported input type %s of resource %s", res.Input.Type, res.Name)
+				}
+
+				digest := writeCTFBlob(t, ctfDir, blob)
+				layers = append(layers, ctfDescriptor(mediaType, digest, len(blob)))
+				resource["relation"] = "local"
+				access := map[string]any{
+					"type":           "localBlob",
+					"localReference": digest,
+					"mediaType":      mediaType,
+				}
+				if res.Input.ReferenceName != "" {
+					access["referenceName"] = res.Input.ReferenceName
+				}
+				resource["access"] = access
+			}
+
+			resources = append(resources, resource)
+		}
+
+		references := component.ComponentReferences
+		if references == nil {
+			references = []map[string]any{}
+		}
+
+		descriptor := map[string]any{
+			"meta": map[string]any{
+				"schemaVersion": "v2",
+			},
+			"component": map[string]any{
+				"name":                component.Name,
+				"version":             component.Version,
+				"provider":            component.Provider.Name,
+				"repositoryContexts":  []any{},
+				"componentReferences": references,
+				"resources":           resources,
+				"sources":             []any{},
+			},
+		}
+
+		descriptorYAML, err := yaml.Marshal(descriptor)
+		if err != nil {
+			t.Fatalf("failed to marshal component descriptor: %v", err)
+		}
+		descriptorLayer := TarFiles(t, map[string][]byte{"component-descriptor.yaml": descriptorYAML})
+		descriptorLayerDigest := writeCTFBlob(t, ctfDir, descriptorLayer)
+		descriptorLayerDesc := ctfDescriptor("application/vnd.ocm.software.component-descriptor.v2+yaml+tar", descriptorLayerDigest, len(descriptorLayer))
+
+		config, err := json.Marshal(map[string]any{
+			"componentDescriptorLayer": descriptorLayerDesc,
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal component config: %v", err)
+		}
+		configDigest := writeCTFBlob(t, ctfDir, config)
+
+		manifest, err := json.Marshal(map[string]any{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+			"config":        ctfDescriptor("application/vnd.ocm.software.component.config.v1+json", configDigest, len(config)),
+			"layers":        append([]map[string]any{descriptorLayerDesc}, layers...),
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal manifest: %v", err)
+		}
+		manifestDigest := writeCTFBlob(t, ctfDir, manifest)
+
+		index.Artifacts = append(index.Artifacts, ocmcli.CTFArtifact{
+			Repository: "component-descriptors/" + component.Name,
+			Tag:        component.Version,
+			Digest:     manifestDigest,
+		})
+	}
+
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		t.Fatalf("failed to marshal ctf artifact index: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(ctfDir, ocmcli.CTFArtifactIndexFileName), indexData, 0o644); err != nil {
+		t.Fatalf("failed to write ctf artifact index: %v", err)
+	}
+}
+
+func ctfDescriptor(mediaType, digest string, size int) map[string]any {
+	return map[string]any{
+		"mediaType": mediaType,
+		"digest":    digest,
+		"size":      size,
+	}
+}
+
+func writeCTFBlob(t *testing.T, ctfDir string, blob []byte) string {
+	t.Helper()
+
+	sum := sha256.Sum256(blob)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	path, err := ocmcli.CTFBlobPath(ctfDir, digest)
+	if err != nil {
+		t.Fatalf("failed to get blob path: %v", err)
+	}
+	if err = os.WriteFile(path, blob, 0o644); err != nil {
+		t.Fatalf("failed to write blob: %v", err)
+	}
+	return digest
+}
+
+func tarDirectory(t *testing.T, dir string) []byte {
+	t.Helper()
+
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)], err = os.ReadFile(path)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to read directory %s: %v", dir, err)
+	}
+	return TarFiles(t, files)
+}
+
+// TarFiles returns a tar archive containing the given files.
+func TarFiles(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatalf("failed to write tar content: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar writer: %v", err)
+	}
+	return buf.Bytes()
+}
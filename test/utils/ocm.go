@@ -132,6 +132,29 @@ func BuildComponent(componentConstructorLocation string, t *testing.T) string {
 	return ctfDir
 }
 
+// SignComponentVersionWithOCM signs the component version with the given reference <repo>//<component>:<version> with "ocm sign componentversions",
+// using the RSA private key at privateKeyPath and the given normalisation algorithm. The OCM cli must be in the PATH.
+func SignComponentVersionWithOCM(t *testing.T, componentReference, signatureName, privateKeyPath, normalisationAlgorithm string) {
+	t.Helper()
+
+	cmd := exec.Command("ocm", []string{
+		"sign",
+		"componentversions",
+		"--signature",
+		signatureName,
+		"--private-key",
+		privateKeyPath,
+		"--normalization",
+		normalisationAlgorithm,
+		componentReference}...)
+
+	out, err := cmd.CombinedOutput()
+	t.Log("OCM Output:", string(out))
+	if err != nil {
+		t.Fatalf("failed to sign component version: %v", err)
+	}
+}
+
 func getOCMVersion(t *testing.T) string {
 	var err error
 
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"sigs.k8s.io/yaml"
+
+	ocmcli "github.com/openmcp-project/bootstrapper/internal/ocm-cli"
+)
+
+// SignComponentVersion signs the component version in the ctf directory ctfDir with the given RSA key, like "ocm sign componentversions".
+// The digests of the resources and of the referenced component versions are added to the component descriptors, transitively.
+// Resources stored as local blobs are hashed, OCI artifacts in OCM artifact set format are digested by their main manifest.
+// The digest of OCI artifacts referenced by tag is resolved in their registry.
+func SignComponentVersion(t *testing.T, ctfDir, componentName, version, signatureName string, key *rsa.PrivateKey) {
+	t.Helper()
+
+	addDigests(t, ctfDir, componentName, version)
+
+	UpdateComponentDescriptor(t, ctfDir, componentName, version, func(descriptor map[string]any) {
+		data, err := json.Marshal(descriptor)
+		if err != nil {
+			t.Fatalf("failed to marshal component descriptor: %v", err)
+		}
+		digest, err := ocmcli.ComponentDescriptorDigest(data, ocmcli.JSONNormalisationV2, ocmcli.HashAlgorithmSHA256)
+		if err != nil {
+			t.Fatalf("failed to compute component descriptor digest: %v", err)
+		}
+		digestBytes, err := hex.DecodeString(digest)
+		if err != nil {
+			t.Fatalf("failed to decode digest: %v", err)
+		}
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digestBytes)
+		if err != nil {
+			t.Fatalf("failed to sign component descriptor: %v", err)
+		}
+
+		descriptor["signatures"] = []any{
+			map[string]any{
+				"name": signatureName,
+				"digest": map[string]any{
+					"hashAlgorithm":          ocmcli.HashAlgorithmSHA256,
+					"normalisationAlgorithm": ocmcli.JSONNormalisationV2,
+					"value":                  digest,
+				},
+				"signature": map[string]any{
+					"algorithm": ocmcli.SignatureAlgorithmRSAPKCS1v15,
+					"mediaType": ocmcli.SignatureMediaTypeRSA,
+					"value":     hex.EncodeToString(signature),
+				},
+			},
+		}
+	})
+}
+
+// UpdateComponentDescriptor applies update to the component descriptor of the component version in the ctf directory ctfDir
+// and replaces the component version in the ctf.
+func UpdateComponentDescriptor(t *testing.T, ctfDir, componentName, version string, update func(descriptor map[string]any)) {
+	t.Helper()
+
+	index, idx := findComponentVersion(t, ctfDir, componentName, version)
+	var manifest map[string]any
+	unmarshalCTFBlob(t, ctfDir, index.Artifacts[idx].Digest, &manifest)
+	configDesc := manifest["config"].(map[string]any)
+	var config map[string]any
+	unmarshalCTFBlob(t, ctfDir, configDesc["digest"].(string), &config)
+	descriptorLayerDesc := config["componentDescriptorLayer"].(map[string]any)
+
+	descriptorData := readFileFromTar(t, readCTFBlob(t, ctfDir, descriptorLayerDesc["digest"].(string)), "component-descriptor.yaml")
+	var descriptor map[string]any
+	if err := yaml.Unmarshal(descriptorData, &descriptor); err != nil {
+		t.Fatalf("failed to unmarshal component descriptor: %v", err)
+	}
+
+	update(descriptor)
+
+	descriptorYAML, err := yaml.Marshal(descriptor)
+	if err != nil {
+		t.Fatalf("failed to marshal component descriptor: %v", err)
+	}
+	descriptorLayer := TarFiles(t, map[string][]byte{"component-descriptor.yaml": descriptorYAML})
+	descriptorLayerDesc = ctfDescriptor(descriptorLayerDesc["mediaType"].(string), writeCTFBlob(t, ctfDir, descriptorLayer), len(descriptorLayer))
+
+	config["componentDescriptorLayer"] = descriptorLayerDesc
+	configData, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal component config: %v", err)
+	}
+	manifest["config"] = ctfDescriptor(configDesc["mediaType"].(string), writeCTFBlob(t, ctfDir, configData), len(configData))
+	layers := manifest["layers"].([]any)
+	layers[0] = descriptorLayerDesc
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	manifestDigest := writeCTFBlob(t, ctfDir, manifestData)
+
+	// the update may have modified other component versions, so the index is read again
+	index, idx = findComponentVersion(t, ctfDir, componentName, version)
+	index.Artifacts[idx].Digest = manifestDigest
+
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		t.Fatalf("failed to marshal ctf artifact index: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(ctfDir, ocmcli.CTFArtifactIndexFileName), indexData, 0o644); err != nil {
+		t.Fatalf("failed to write ctf artifact index: %v", err)
+	}
+}
+
+// addDigests adds the digests of the resources and references to the component descriptor and returns its digest.
+func addDigests(t *testing.T, ctfDir, componentName, version string) string {
+	t.Helper()
+
+	var result string
+	UpdateComponentDescriptor(t, ctfDir, componentName, version, func(descriptor map[string]any) {
+		component := descriptor["component"].(map[string]any)
+
+		resources, _ := component["resources"].([]any)
+		for _, r := range resources {
+			resource := r.(map[string]any)
+			access := resource["access"].(map[string]any)
+			switch access["type"] {
+			case ocmcli.LocalBlobAccessType:
+				blob := readCTFBlob(t, ctfDir, access["localReference"].(string))
+				if mediaType, _ := access["mediaType"].(string); strings.HasPrefix(mediaType, "application/vnd.oci.image.") {
+					resource["digest"] = digestSpec(ocmcli.OCIArtifactDigestV1, artifactSetDigest(t, blob))
+					continue
+				}
+				sum := sha256.Sum256(blob)
+				resource["digest"] = digestSpec(ocmcli.GenericBlobDigestV1, hex.EncodeToString(sum[:]))
+			case ocmcli.OCIArtifactAccessType:
+				resource["digest"] = digestSpec(ocmcli.OCIArtifactDigestV1, imageDigest(t, access["imageReference"].(string)))
+			default:
+				t.Fatalf("unsupported access type %s of resource %s", access["type"], resource["name"])
+			}
+		}
+
+		references, _ := component["componentReferences"].([]any)
+		for _, r := range references {
+			reference := r.(map[string]any)
+			digest := addDigests(t, ctfDir, reference["componentName"].(string), reference["version"].(string))
+			reference["digest"] = digestSpec(ocmcli.JSONNormalisationV2, digest)
+		}
+
+		data, err := json.Marshal(descriptor)
+		if err != nil {
+			t.Fatalf("failed to marshal component descriptor: %v", err)
+		}
+		result, err = ocmcli.ComponentDescriptorDigest(data, ocmcli.JSONNormalisationV2, ocmcli.HashAlgorithmSHA256)
+		if err != nil {
+			t.Fatalf("failed to compute component descriptor digest: %v", err)
+		}
+	})
+	return result
+}
+
+// artifactSetDigest returns the hex encoded digest of the main artifact of an OCI artifact stored in OCM artifact set format.
+func artifactSetDigest(t *testing.T, blob []byte) string {
+	t.Helper()
+
+	index, err := v1.ParseIndexManifest(bytes.NewReader(readFileFromTar(t, blob, "index.json")))
+	if err != nil {
+		t.Fatalf("failed to parse artifact set index: %v", err)
+	}
+	main, err := ocmcli.ArtifactSetMainManifest(index)
+	if err != nil {
+		t.Fatalf("failed to get main artifact of artifact set: %v", err)
+	}
+	return main.Digest.Hex
+}
+
+// imageDigest returns the hex encoded digest of the image reference, or of the manifest its tag resolves to.
+func imageDigest(t *testing.T, imageReference string) string {
+	t.Helper()
+
+	if _, digest, found := strings.Cut(imageReference, "@sha256:"); found {
+		return digest
+	}
+	ref, err := name.ParseReference(imageReference)
+	if err != nil {
+		t.Fatalf("invalid image reference %s: %v", imageReference, err)
+	}
+	descriptor, err := remote.Head(ref)
+	if err != nil {
+		t.Fatalf("failed to resolve digest of %s: %v", imageReference, err)
+	}
+	return descriptor.Digest.Hex
+}
+
+func digestSpec(normalisationAlgorithm, value string) map[string]any {
+	return map[string]any{
+		"hashAlgorithm":          ocmcli.HashAlgorithmSHA256,
+		"normalisationAlgorithm": normalisationAlgorithm,
+		"value":                  value,
+	}
+}
+
+// findComponentVersion returns the ctf artifact index and the index of the artifact of the component version.
+func findComponentVersion(t *testing.T, ctfDir, componentName, version string) (ocmcli.CTFArtifactIndex, int) {
+	t.Helper()
+
+	index := readCTFIndex(t, ctfDir)
+	for i, artifact := range index.Artifacts {
+		if artifact.Repository == "component-descriptors/"+componentName && artifact.Tag == version {
+			return index, i
+		}
+	}
+	t.Fatalf("component version %s:%s not found in ctf %s", componentName, version, ctfDir)
+	return index, -1
+}
+
+func readCTFIndex(t *testing.T, ctfDir string) ocmcli.CTFArtifactIndex {
+	t.Helper()
+
+	var index ocmcli.CTFArtifactIndex
+	data, err := os.ReadFile(filepath.Join(ctfDir, ocmcli.CTFArtifactIndexFileName))
+	if err != nil {
+		t.Fatalf("failed to read ctf artifact index: %v", err)
+	}
+	if err = json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("failed to unmarshal ctf artifact index: %v", err)
+	}
+	return index
+}
+
+func readCTFBlob(t *testing.T, ctfDir, digest string) []byte {
+	t.Helper()
+
+	path, err := ocmcli.CTFBlobPath(ctfDir, digest)
+	if err != nil {
+		t.Fatalf("failed to get blob path: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read blob: %v", err)
+	}
+	return data
+}
+
+func unmarshalCTFBlob(t *testing.T, ctfDir, digest string, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal(readCTFBlob(t, ctfDir, digest), v); err != nil {
+		t.Fatalf("failed to unmarshal blob %s: %v", digest, err)
+	}
+}
+
+func readFileFromTar(t *testing.T, data []byte, fileName string) []byte {
+	t.Helper()
+
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			t.Fatalf("failed to find %s in tar: %v", fileName, err)
+		}
+		if header.Name == fileName {
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatalf("failed to read %s from tar: %v", fileName, err)
+			}
+			return content
+		}
+	}
+}